@@ -140,6 +140,8 @@ Compatibility and versioning information for the Fastly CLI is being updated in
 		Env:        env,
 		ErrLog:     fsterrors.Log,
 		HTTPClient: httpClient,
+		RTSClient:  app.FastlyRTSClient,
+		Stderr:     os.Stderr,
 		Stdin:      in,
 		Stdout:     out,
 		Versioners: app.Versioners{
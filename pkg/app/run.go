@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/fastly/cli/pkg/api"
@@ -50,6 +51,7 @@ import (
 	"github.com/fastly/cli/pkg/logging/syslog"
 	"github.com/fastly/cli/pkg/logs"
 	"github.com/fastly/cli/pkg/pop"
+	"github.com/fastly/cli/pkg/profile"
 	"github.com/fastly/cli/pkg/purge"
 	"github.com/fastly/cli/pkg/revision"
 	"github.com/fastly/cli/pkg/service"
@@ -85,6 +87,8 @@ type RunOpts struct {
 	Env        config.Environment
 	ErrLog     errors.LogInterface
 	HTTPClient api.HTTPClient
+	RTSClient  RTSClientFactory
+	Stderr     io.Writer
 	Stdin      io.Reader
 	Stdout     io.Writer
 	Versioners Versioners
@@ -139,6 +143,10 @@ func Run(opts RunOpts) error {
 	app.Flag("token", tokenHelp).Short('t').StringVar(&globals.Flag.Token)
 	app.Flag("verbose", "Verbose logging").Short('v').BoolVar(&globals.Flag.Verbose)
 	app.Flag("endpoint", "Fastly API endpoint").Hidden().StringVar(&globals.Flag.Endpoint)
+	profileHelp := fmt.Sprintf("Config profile to use (or via %s)", env.Profile)
+	app.Flag("profile", profileHelp).StringVar(&globals.Flag.Profile)
+	outputHelp := fmt.Sprintf("Output format for list and describe commands (%s)", strings.Join(text.Formats, ", "))
+	app.Flag("output", outputHelp).HintOptions(text.Formats...).EnumVar(&globals.Flag.Format, text.Formats...)
 
 	configureRoot := configure.NewRootCommand(app, opts.ConfigPath, configure.APIClientFactory(opts.APIClient), &globals)
 	whoamiRoot := whoami.NewRootCommand(app, opts.HTTPClient, &globals)
@@ -146,7 +154,14 @@ func Run(opts RunOpts) error {
 	updateRoot := update.NewRootCommand(app, opts.ConfigPath, opts.Versioners.CLI, opts.HTTPClient, &globals)
 	ipRoot := ip.NewRootCommand(app, &globals)
 	popRoot := pop.NewRootCommand(app, &globals)
-	purgeRoot := purge.NewRootCommand(app, &globals)
+	purgeRoot := purge.NewRootCommand(app, opts.HTTPClient, &globals)
+
+	profileRoot := profile.NewRootCommand(app, &globals)
+	profileCreate := profile.NewCreateCommand(profileRoot.CmdClause, opts.ConfigPath, profile.APIClientFactory(opts.APIClient), &globals)
+	profileList := profile.NewListCommand(profileRoot.CmdClause, &globals)
+	profileSwitch := profile.NewSwitchCommand(profileRoot.CmdClause, opts.ConfigPath, &globals)
+	profileDelete := profile.NewDeleteCommand(profileRoot.CmdClause, opts.ConfigPath, &globals)
+	profileDefault := profile.NewDefaultCommand(profileRoot.CmdClause, &globals)
 
 	serviceRoot := service.NewRootCommand(app, &globals)
 	serviceCreate := service.NewCreateCommand(serviceRoot.CmdClause, &globals)
@@ -155,6 +170,8 @@ func Run(opts RunOpts) error {
 	serviceUpdate := service.NewUpdateCommand(serviceRoot.CmdClause, &globals)
 	serviceDelete := service.NewDeleteCommand(serviceRoot.CmdClause, &globals)
 	serviceSearch := service.NewSearchCommand(serviceRoot.CmdClause, &globals)
+	serviceExport := service.NewExportCommand(serviceRoot.CmdClause, &globals)
+	serviceApply := service.NewApplyCommand(serviceRoot.CmdClause, &globals)
 
 	serviceVersionRoot := serviceversion.NewRootCommand(app, &globals)
 	serviceVersionClone := serviceversion.NewCloneCommand(serviceVersionRoot.CmdClause, &globals)
@@ -163,6 +180,7 @@ func Run(opts RunOpts) error {
 	serviceVersionActivate := serviceversion.NewActivateCommand(serviceVersionRoot.CmdClause, &globals)
 	serviceVersionDeactivate := serviceversion.NewDeactivateCommand(serviceVersionRoot.CmdClause, &globals)
 	serviceVersionLock := serviceversion.NewLockCommand(serviceVersionRoot.CmdClause, &globals)
+	serviceVersionDiff := serviceversion.NewDiffCommand(serviceVersionRoot.CmdClause, &globals)
 
 	computeRoot := compute.NewRootCommand(app, &globals)
 	computeInit := compute.NewInitCommand(computeRoot.CmdClause, opts.HTTPClient, &globals)
@@ -187,6 +205,9 @@ func Run(opts RunOpts) error {
 	backendDescribe := backend.NewDescribeCommand(backendRoot.CmdClause, &globals)
 	backendUpdate := backend.NewUpdateCommand(backendRoot.CmdClause, &globals)
 	backendDelete := backend.NewDeleteCommand(backendRoot.CmdClause, &globals)
+	backendVerify := backend.NewVerifyCommand(backendRoot.CmdClause, &globals)
+	backendImport := backend.NewImportCommand(backendRoot.CmdClause, &globals)
+	backendExport := backend.NewExportCommand(backendRoot.CmdClause, &globals)
 
 	healthcheckRoot := healthcheck.NewRootCommand(app, &globals)
 	healthcheckCreate := healthcheck.NewCreateCommand(healthcheckRoot.CmdClause, &globals)
@@ -194,6 +215,7 @@ func Run(opts RunOpts) error {
 	healthcheckDescribe := healthcheck.NewDescribeCommand(healthcheckRoot.CmdClause, &globals)
 	healthcheckUpdate := healthcheck.NewUpdateCommand(healthcheckRoot.CmdClause, &globals)
 	healthcheckDelete := healthcheck.NewDeleteCommand(healthcheckRoot.CmdClause, &globals)
+	healthcheckTest := healthcheck.NewTestCommand(healthcheckRoot.CmdClause, &globals)
 
 	dictionaryRoot := edgedictionary.NewRootCommand(app, &globals)
 	dictionaryCreate := edgedictionary.NewCreateCommand(dictionaryRoot.CmdClause, &globals)
@@ -209,6 +231,8 @@ func Run(opts RunOpts) error {
 	dictionaryItemUpdate := edgedictionaryitem.NewUpdateCommand(dictionaryItemRoot.CmdClause, &globals)
 	dictionaryItemDelete := edgedictionaryitem.NewDeleteCommand(dictionaryItemRoot.CmdClause, &globals)
 	dictionaryItemBatchModify := edgedictionaryitem.NewBatchCommand(dictionaryItemRoot.CmdClause, &globals)
+	dictionaryItemSync := edgedictionaryitem.NewSyncCommand(dictionaryItemRoot.CmdClause, &globals)
+	dictionaryItemExport := edgedictionaryitem.NewExportCommand(dictionaryItemRoot.CmdClause, &globals)
 
 	loggingRoot := logging.NewRootCommand(app, &globals)
 
@@ -388,12 +412,13 @@ func Run(opts RunOpts) error {
 	openstackDelete := openstack.NewDeleteCommand(openstackRoot.CmdClause, &globals)
 
 	logsRoot := logs.NewRootCommand(app, &globals)
-	logsTail := logs.NewTailCommand(logsRoot.CmdClause, &globals)
+	logsTail := logs.NewTailCommand(logsRoot.CmdClause, opts.HTTPClient, opts.Stderr, &globals)
 
 	statsRoot := stats.NewRootCommand(app, &globals)
 	statsRegions := stats.NewRegionsCommand(statsRoot.CmdClause, &globals)
 	statsHistorical := stats.NewHistoricalCommand(statsRoot.CmdClause, &globals)
 	statsRealtime := stats.NewRealtimeCommand(statsRoot.CmdClause, &globals)
+	statsWatch := stats.NewWatchCommand(statsRoot.CmdClause, &globals)
 
 	vclRoot := vcl.NewRootCommand(app, &globals)
 
@@ -420,6 +445,13 @@ func Run(opts RunOpts) error {
 		popRoot,
 		purgeRoot,
 
+		profileRoot,
+		profileCreate,
+		profileList,
+		profileSwitch,
+		profileDelete,
+		profileDefault,
+
 		serviceRoot,
 		serviceCreate,
 		serviceList,
@@ -427,6 +459,8 @@ func Run(opts RunOpts) error {
 		serviceUpdate,
 		serviceDelete,
 		serviceSearch,
+		serviceExport,
+		serviceApply,
 
 		serviceVersionRoot,
 		serviceVersionClone,
@@ -435,6 +469,7 @@ func Run(opts RunOpts) error {
 		serviceVersionActivate,
 		serviceVersionDeactivate,
 		serviceVersionLock,
+		serviceVersionDiff,
 
 		computeRoot,
 		computeInit,
@@ -459,6 +494,9 @@ func Run(opts RunOpts) error {
 		backendDescribe,
 		backendUpdate,
 		backendDelete,
+		backendVerify,
+		backendImport,
+		backendExport,
 
 		healthcheckRoot,
 		healthcheckCreate,
@@ -466,6 +504,7 @@ func Run(opts RunOpts) error {
 		healthcheckDescribe,
 		healthcheckUpdate,
 		healthcheckDelete,
+		healthcheckTest,
 
 		dictionaryRoot,
 		dictionaryCreate,
@@ -481,6 +520,8 @@ func Run(opts RunOpts) error {
 		dictionaryItemUpdate,
 		dictionaryItemDelete,
 		dictionaryItemBatchModify,
+		dictionaryItemSync,
+		dictionaryItemExport,
 
 		loggingRoot,
 
@@ -666,6 +707,7 @@ func Run(opts RunOpts) error {
 		statsRegions,
 		statsHistorical,
 		statsRealtime,
+		statsWatch,
 
 		vclRoot,
 
@@ -743,14 +785,36 @@ func Run(opts RunOpts) error {
 		return errors.RemediationError{Prefix: buf.String()}
 	}
 
+	// An explicitly requested profile must exist, unless the command is one
+	// that creates it.
+	profileName, source := globals.Profile()
+	if source == config.SourceFlag || source == config.SourceEnvironment {
+		if _, ok := globals.File.Profiles[profileName]; !ok && name != "configure" && name != "profile create" {
+			err := fmt.Errorf("profile '%s' not found", profileName)
+			globals.ErrLog.Add(err)
+			return errors.RemediationError{
+				Inner:       err,
+				Remediation: errors.ProfileRemediation,
+			}
+		}
+	}
+
+	// Machine readable output must contain only the rendered data, and so we
+	// don't print any of the following informational messages.
+	machineOutput := text.IsMachineFormat(globals.Flag.Format)
+
 	token, source := globals.Token()
-	if globals.Verbose() {
+	if globals.Verbose() && !machineOutput {
 		switch source {
 		case config.SourceFlag:
 			fmt.Fprintf(opts.Stdout, "Fastly API token provided via --token\n")
 		case config.SourceEnvironment:
 			fmt.Fprintf(opts.Stdout, "Fastly API token provided via %s\n", env.Token)
 		case config.SourceFile:
+			if profileName != "" {
+				fmt.Fprintf(opts.Stdout, "Fastly API token provided via config file (profile: %s)\n", profileName)
+				break
+			}
 			fmt.Fprintf(opts.Stdout, "Fastly API token provided via config file\n")
 		default:
 			fmt.Fprintf(opts.Stdout, "Fastly API token not provided\n")
@@ -760,7 +824,7 @@ func Run(opts RunOpts) error {
 	// If we are using the token from config file, check the files permissions
 	// to assert if they are not too open or have been altered outside of the
 	// application and warn if so.
-	if source == config.SourceFile && name != "configure" {
+	if source == config.SourceFile && name != "configure" && !machineOutput {
 		if fi, err := os.Stat(config.FilePath); err == nil {
 			if mode := fi.Mode().Perm(); mode > config.FilePermissions {
 				text.Warning(opts.Stdout, "Unprotected configuration file.")
@@ -772,7 +836,7 @@ func Run(opts RunOpts) error {
 	}
 
 	endpoint, source := globals.Endpoint()
-	if globals.Verbose() {
+	if globals.Verbose() && !machineOutput {
 		switch source {
 		case config.SourceEnvironment:
 			fmt.Fprintf(opts.Stdout, "Fastly API endpoint (via %s): %s\n", env.Endpoint, endpoint)
@@ -789,7 +853,7 @@ func Run(opts RunOpts) error {
 		return fmt.Errorf("error constructing Fastly API client: %w", err)
 	}
 
-	globals.RTSClient, err = fastly.NewRealtimeStatsClientForEndpoint(token, fastly.DefaultRealtimeStatsEndpoint)
+	globals.RTSClient, err = opts.RTSClient(token, fastly.DefaultRealtimeStatsEndpoint)
 	if err != nil {
 		globals.ErrLog.Add(err)
 		return fmt.Errorf("error constructing Fastly realtime stats client: %w", err)
@@ -801,7 +865,7 @@ func Run(opts RunOpts) error {
 		return errors.RemediationError{Prefix: usage, Inner: fmt.Errorf("command not found")}
 	}
 
-	if opts.Versioners.CLI != nil && name != "update" && !version.IsPreRelease(revision.AppVersion) {
+	if opts.Versioners.CLI != nil && name != "update" && !version.IsPreRelease(revision.AppVersion) && !machineOutput {
 		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 		defer cancel() // push cancel on the defer stack first...
 		f := update.CheckAsync(ctx, opts.ConfigFile, opts.ConfigPath, revision.AppVersion, opts.Versioners.CLI, opts.Stdin, opts.Stdout)
@@ -825,6 +889,18 @@ func FastlyAPIClient(token, endpoint string) (api.Interface, error) {
 	return client, err
 }
 
+// RTSClientFactory creates a Fastly realtime stats client (modeled as an
+// api.RealtimeStatsInterface) from a user-provided API token, in the same way
+// that APIClientFactory does for the Fastly API client.
+type RTSClientFactory func(token, endpoint string) (api.RealtimeStatsInterface, error)
+
+// FastlyRTSClient is a RTSClientFactory that returns a real Fastly realtime
+// stats client using the provided token and endpoint.
+func FastlyRTSClient(token, endpoint string) (api.RealtimeStatsInterface, error) {
+	client, err := fastly.NewRealtimeStatsClientForEndpoint(token, endpoint)
+	return client, err
+}
+
 // contextHasHelpFlag asserts whether a given kingpin.ParseContext contains a
 // `help` flag.
 func contextHasHelpFlag(ctx *kingpin.ParseContext) bool {
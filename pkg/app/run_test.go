@@ -75,9 +75,12 @@ USAGE
 A tool to interact with the Fastly API
 
 GLOBAL FLAGS
-      --help         Show context-sensitive help.
-  -t, --token=TOKEN  Fastly API token (or via FASTLY_API_TOKEN)
-  -v, --verbose      Verbose logging
+      --help             Show context-sensitive help.
+  -t, --token=TOKEN      Fastly API token (or via FASTLY_API_TOKEN)
+  -v, --verbose          Verbose logging
+      --profile=PROFILE  Config profile to use (or via FASTLY_PROFILE)
+      --output=OUTPUT    Output format for list and describe commands (table,
+                         json, yaml, csv)
 
 COMMANDS
   help             Show help.
@@ -88,6 +91,7 @@ COMMANDS
   ip-list          List Fastly's public IPs
   pops             List Fastly datacenters
   purge            Invalidate objects in the Fastly cache
+  profile          Manage user profiles
   service          Manipulate Fastly services
   service-version  Manipulate Fastly service versions
   compute          Manage Compute@Edge packages
@@ -107,9 +111,12 @@ USAGE
   fastly [<flags>] service
 
 GLOBAL FLAGS
-      --help         Show context-sensitive help.
-  -t, --token=TOKEN  Fastly API token (or via FASTLY_API_TOKEN)
-  -v, --verbose      Verbose logging
+      --help             Show context-sensitive help.
+  -t, --token=TOKEN      Fastly API token (or via FASTLY_API_TOKEN)
+  -v, --verbose          Verbose logging
+      --profile=PROFILE  Config profile to use (or via FASTLY_PROFILE)
+      --output=OUTPUT    Output format for list and describe commands (table,
+                         json, yaml, csv)
 
 SUBCOMMANDS
 
@@ -151,6 +158,36 @@ SUBCOMMANDS
 
     -n, --name=NAME  Service name
 
+  service export --version=VERSION [<flags>]
+    Export the resources of a Fastly service version to a service spec file
+
+    -s, --service-id=SERVICE-ID  Service ID (falls back to FASTLY_SERVICE_ID,
+                                 then fastly.toml)
+        --version=VERSION        'latest', 'active', or the number of a specific
+                                 version
+    -f, --file=FILE              Path of the spec file to write (defaults to
+                                 stdout)
+        --format=FORMAT          Spec file format, inferred from the --file
+                                 extension if not set (toml, json)
+        --include-secrets        Include credentials (e.g. logging endpoint
+                                 tokens) rather than redacting them
+
+  service apply --file=FILE [<flags>]
+    Apply a service spec file to a clone of a Fastly service version
+
+    -s, --service-id=SERVICE-ID  Service ID (falls back to FASTLY_SERVICE_ID,
+                                 then fastly.toml)
+        --version=VERSION        'latest', 'active', or the number of a specific
+                                 version
+    -f, --file=FILE              Path of the spec file to apply
+        --activate               Activate the service version once the spec has
+                                 been applied
+        --dry-run                Print the changes required to apply the spec
+                                 without making them
+        --items                  Change the items of existing dictionaries,
+                                 which aren't versioned and so take effect
+                                 immediately
+
 `) + "\n\n"
 
 var fullFatHelpDefault = strings.TrimSpace(`
@@ -160,9 +197,12 @@ USAGE
 A tool to interact with the Fastly API
 
 GLOBAL FLAGS
-      --help         Show context-sensitive help.
-  -t, --token=TOKEN  Fastly API token (or via FASTLY_API_TOKEN)
-  -v, --verbose      Verbose logging
+      --help             Show context-sensitive help.
+  -t, --token=TOKEN      Fastly API token (or via FASTLY_API_TOKEN)
+  -v, --verbose          Verbose logging
+      --profile=PROFILE  Config profile to use (or via FASTLY_PROFILE)
+      --output=OUTPUT    Output format for list and describe commands (table,
+                         json, yaml, csv)
 
 COMMANDS
   help [<command> ...]
@@ -199,15 +239,54 @@ COMMANDS
     Invalidate objects in the Fastly cache
 
         --all                    Purge everything from a service
+        --concurrency=10         Number of URLs to purge at once when using
+                                 --url-file or --sitemap
         --file=FILE              Purge a service of a newline delimited list of
-                                 Surrogate Keys
+                                 Surrogate Keys (--file=- reads from stdin)
+        --format=FORMAT          Output format for bulk purges (json)
         --key=KEY                Purge a service of objects tagged with a
                                  Surrogate Key
+        --match=MATCH ...        Only purge URLs from --url-file or --sitemap
+                                 matching a glob, e.g. '/blog/2021/*'
+                                 (repeatable)
+        --rate=0                 Maximum number of purge requests per second
+                                 when using --url-file or --sitemap (0 is
+                                 unlimited)
     -s, --service-id=SERVICE-ID  Service ID (falls back to FASTLY_SERVICE_ID,
                                  then fastly.toml)
+        --sitemap=SITEMAP        Purge the URLs listed in a sitemap or sitemap
+                                 index
         --soft                   A 'soft' purge marks affected objects as stale
                                  rather than making them inaccessible
         --url=URL                Purge an individual URL
+        --url-file=URL-FILE      Purge a newline delimited list of URLs
+                                 (--url-file=- reads from stdin)
+
+  profile create --name=NAME [<flags>]
+    Create a user profile
+
+    -n, --name=NAME  Profile name
+        --default    Make the profile the default
+
+  profile list
+    List user profiles
+
+
+  profile switch --name=NAME
+    Switch the default user profile
+
+    -n, --name=NAME  Profile name
+
+  profile delete --name=NAME [<flags>]
+    Delete a user profile
+
+    -n, --name=NAME  Profile name
+        --force      Delete the default profile even if it leaves no profile as
+                     the default
+
+  profile default
+    Show the default user profile
+
 
   service create --name=NAME [<flags>]
     Create a Fastly service
@@ -247,6 +326,36 @@ COMMANDS
 
     -n, --name=NAME  Service name
 
+  service export --version=VERSION [<flags>]
+    Export the resources of a Fastly service version to a service spec file
+
+    -s, --service-id=SERVICE-ID  Service ID (falls back to FASTLY_SERVICE_ID,
+                                 then fastly.toml)
+        --version=VERSION        'latest', 'active', or the number of a specific
+                                 version
+    -f, --file=FILE              Path of the spec file to write (defaults to
+                                 stdout)
+        --format=FORMAT          Spec file format, inferred from the --file
+                                 extension if not set (toml, json)
+        --include-secrets        Include credentials (e.g. logging endpoint
+                                 tokens) rather than redacting them
+
+  service apply --file=FILE [<flags>]
+    Apply a service spec file to a clone of a Fastly service version
+
+    -s, --service-id=SERVICE-ID  Service ID (falls back to FASTLY_SERVICE_ID,
+                                 then fastly.toml)
+        --version=VERSION        'latest', 'active', or the number of a specific
+                                 version
+    -f, --file=FILE              Path of the spec file to apply
+        --activate               Activate the service version once the spec has
+                                 been applied
+        --dry-run                Print the changes required to apply the spec
+                                 without making them
+        --items                  Change the items of existing dictionaries,
+                                 which aren't versioned and so take effect
+                                 immediately
+
   service-version clone --version=VERSION [<flags>]
     Clone a Fastly service version
 
@@ -275,12 +384,28 @@ COMMANDS
   service-version activate --version=VERSION [<flags>]
     Activate a Fastly service version
 
-    -s, --service-id=SERVICE-ID  Service ID (falls back to FASTLY_SERVICE_ID,
-                                 then fastly.toml)
-        --version=VERSION        'latest', 'active', or the number of a specific
-                                 version
-        --autoclone              If the selected service version is not
-                                 editable, clone it and use the clone.
+    -s, --service-id=SERVICE-ID    Service ID (falls back to FASTLY_SERVICE_ID,
+                                   then fastly.toml)
+        --version=VERSION          'latest', 'active', or the number of a
+                                   specific version
+        --autoclone                If the selected service version is not
+                                   editable, clone it and use the clone.
+        --canary                   Watch the realtime stats after activating
+                                   and re-activate the previous version if they
+                                   regress
+        --canary-baseline=1m       How long to measure the previous version's
+                                   realtime stats for before activating
+        --canary-window=5m         How long to watch the realtime stats for
+                                   after activating
+        --canary-error-threshold=0.01
+                                   Maximum increase in the ratio of 5xx
+                                   responses over the baseline (e.g. 0.01 is one
+                                   percentage point)
+        --canary-latency-threshold=1.5
+                                   Maximum multiple of the baseline's average
+                                   miss latency
+        --canary-min-requests=100  Minimum number of requests the new version
+                                   must serve before its stats are judged
 
   service-version deactivate --version=VERSION [<flags>]
     Deactivate a Fastly service version
@@ -298,6 +423,16 @@ COMMANDS
         --version=VERSION        'latest', 'active', or the number of a specific
                                  version
 
+  service-version diff --from=FROM --to=TO [<flags>]
+    Show the resource changes between two Fastly service versions
+
+    -s, --service-id=SERVICE-ID  Service ID (falls back to FASTLY_SERVICE_ID,
+                                 then fastly.toml)
+        --from=FROM              'latest', 'active', or the number of the
+                                 version to compare from
+        --to=TO                  'latest', 'active', or the number of the
+                                 version to compare to
+
   compute init [<flags>]
     Initialize a new Compute@Edge package locally
 
@@ -320,6 +455,8 @@ COMMANDS
     --include-source     Include source code in built package
     --force              Skip verification steps and force build
     --timeout=TIMEOUT    Timeout, in seconds, for the build compilation step
+    --list-files         List the files the package would contain, and why
+                         others are excluded, without building it
 
   compute serve [<flags>]
     Build and run a Compute@Edge package locally
@@ -332,11 +469,15 @@ COMMANDS
     --language=LANGUAGE      Language type
     --name=NAME              Package name
     --skip-build             Skip the build step
+    --watch                  Rebuild the package and restart the local server
+                             when its files change
 
-  compute pack --path=PATH
+  compute pack --path=PATH [<flags>]
     Package a pre-compiled Wasm binary for a Fastly Compute@Edge service
 
-    -p, --path=PATH  Path to a pre-compiled Wasm binary
+    -p, --path=PATH             Path to a pre-compiled Wasm binary
+        --include-build-output  Also package the other files in bin, unless
+                                they're ignored by .fastlyignore
 
   compute deploy [<flags>]
     Deploy a package to a Fastly Compute@Edge service
@@ -353,6 +494,8 @@ COMMANDS
         --backend-port=BACKEND-PORT
                                  A port number for the package backend
         --comment=COMMENT        Human-readable comment
+        --rollback-incomplete    Undo the changes made by a previous deploy
+                                 which was interrupted
 
   compute publish [<flags>]
     Build and deploy a Compute@Edge package to a Fastly service
@@ -363,6 +506,9 @@ COMMANDS
         --force                  Skip verification steps and force build
         --timeout=TIMEOUT        Timeout, in seconds, for the build compilation
                                  step
+        --list-files             List the files the package would contain,
+                                 and why others are excluded, without building
+                                 or deploying it
     -s, --service-id=SERVICE-ID  Service ID (falls back to FASTLY_SERVICE_ID,
                                  then fastly.toml)
         --version=VERSION        'latest', 'active', or the number of a specific
@@ -375,6 +521,8 @@ COMMANDS
         --backend-port=BACKEND-PORT
                                  A port number for the package backend
         --comment=COMMENT        Human-readable comment
+        --rollback-incomplete    Undo the changes made by a previous deploy
+                                 which was interrupted
 
   compute update --version=VERSION --path=PATH [<flags>]
     Update a package on a Fastly Compute@Edge service version
@@ -602,6 +750,46 @@ COMMANDS
                                  editable, clone it and use the clone.
     -n, --name=NAME              Backend name
 
+  backend verify --version=VERSION --name=NAME [<flags>]
+    Verify the TLS configuration of a backend by connecting to it from this
+    machine
+
+    -s, --service-id=SERVICE-ID  Service ID (falls back to FASTLY_SERVICE_ID,
+                                 then fastly.toml)
+        --version=VERSION        'latest', 'active', or the number of a specific
+                                 version
+    -n, --name=NAME              Name of backend
+        --expiry-warning=720h    Warn when the certificate expires within this
+                                 long, e.g. 720h
+
+  backend import --version=VERSION --file=FILE [<flags>]
+    Create or update the backends of a Fastly service version to match a JSON or
+    YAML file
+
+    -s, --service-id=SERVICE-ID  Service ID (falls back to FASTLY_SERVICE_ID,
+                                 then fastly.toml)
+        --version=VERSION        'latest', 'active', or the number of a specific
+                                 version
+        --autoclone              If the selected service version is not
+                                 editable, clone it and use the clone.
+    -f, --file=FILE              Path of the JSON or YAML file of backends,
+                                 as written by 'fastly backend export'
+        --concurrency=5          Number of backends to change at once
+        --dry-run                Print the changes required to import the
+                                 backends without making them
+        --prune                  Delete backends which aren't in the file
+
+  backend export --version=VERSION [<flags>]
+    Export the backends of a Fastly service version to a file, sorted by name
+
+    -s, --service-id=SERVICE-ID  Service ID (falls back to FASTLY_SERVICE_ID,
+                                 then fastly.toml)
+        --version=VERSION        'latest', 'active', or the number of a specific
+                                 version
+    -f, --file=FILE              Path of the file to write (defaults to stdout)
+        --format=FORMAT          File format, inferred from the --file extension
+                                 if not set (json, yaml)
+
   healthcheck create --version=VERSION --name=NAME [<flags>]
     Create a healthcheck on a Fastly service version
 
@@ -612,6 +800,7 @@ COMMANDS
         --autoclone              If the selected service version is not
                                  editable, clone it and use the clone.
     -n, --name=NAME              Healthcheck name
+
         --comment=COMMENT        A descriptive note
         --method=METHOD          Which HTTP method to use
         --host=HOST              Which host to check
@@ -689,6 +878,21 @@ COMMANDS
                                  editable, clone it and use the clone.
     -n, --name=NAME              Healthcheck name
 
+  healthcheck test --version=VERSION [<flags>]
+    Probe the backends of a Fastly service version from this machine as a
+    healthcheck would
+
+    -s, --service-id=SERVICE-ID  Service ID (falls back to FASTLY_SERVICE_ID,
+                                 then fastly.toml)
+        --version=VERSION        'latest', 'active', or the number of a specific
+                                 version
+    -n, --name=NAME              Name of healthcheck, to probe every backend
+                                 using it
+        --backend=BACKEND        Name of backend, to probe with its healthcheck
+        --windows=1              Number of healthcheck windows to probe for
+        --interval=INTERVAL      Time between probes, e.g. 1s (defaults to the
+                                 healthcheck's check interval)
+
   dictionary create --version=VERSION --name=NAME [<flags>]
     Create a Fastly edge dictionary on a Fastly service version
 
@@ -799,6 +1003,33 @@ COMMANDS
                                  Dictionary ID
         --file=FILE              Batch update json file
 
+  dictionaryitem sync --dictionary-id=DICTIONARY-ID --file=FILE [<flags>]
+    Update a Fastly edge dictionary to match the items in a CSV, JSON, YAML or
+    env file
+
+    -s, --service-id=SERVICE-ID  Service ID (falls back to FASTLY_SERVICE_ID,
+                                 then fastly.toml)
+        --dictionary-id=DICTIONARY-ID
+                                 Dictionary ID
+    -f, --file=FILE              Path of the CSV, JSON, YAML or env file of
+                                 dictionary items
+        --dry-run                Print the changes required to sync the
+                                 dictionary without making them
+        --prune                  Delete dictionary items which aren't in the
+                                 file
+
+  dictionaryitem export --dictionary-id=DICTIONARY-ID [<flags>]
+    Export the items of a Fastly edge dictionary to a file, sorted by key
+
+    -s, --service-id=SERVICE-ID  Service ID (falls back to FASTLY_SERVICE_ID,
+                                 then fastly.toml)
+        --dictionary-id=DICTIONARY-ID
+                                 Dictionary ID
+    -f, --file=FILE              Path of the file to write (defaults to stdout)
+        --format=FORMAT          File format, inferred from the --file extension
+                                 if not set (csv, json, yaml, env, or batch for
+                                 the batchmodify command)
+
   logging bigquery create --name=NAME --version=VERSION --project-id=PROJECT-ID --dataset=DATASET --table=TABLE --user=USER --secret-key=SECRET-KEY [<flags>]
     Create a BigQuery logging endpoint on a Fastly service version
 
@@ -3726,18 +3957,31 @@ COMMANDS
   logs tail [<flags>]
     Tail Compute@Edge logs
 
-    -s, --service-id=SERVICE-ID  Service ID (falls back to FASTLY_SERVICE_ID,
-                                 then fastly.toml)
-        --from=FROM              From time, in unix seconds
-        --to=TO                  To time, in unix seconds
-        --sort-buffer=1s         Sort buffer is how long to buffer logs,
-                                 attempting to sort them before printing,
-                                 defaults to 1s (second)
-        --search-padding=2s      Search padding is how much of a window on
-                                 either side of From and To to use for
-                                 searching, defaults to 2s (seconds)
-        --stream=STREAM          Stream specifies which of 'stdout' or 'stderr'
-                                 to output, defaults to undefined (all streams)
+    -s, --service-id=SERVICE-ID ...
+                                   Service ID (falls back to FASTLY_SERVICE_ID,
+                                   then fastly.toml), repeat to tail several
+                                   services
+        --all-compute-services     Tail every Compute@Edge service
+        --from=FROM                From time, in unix seconds
+        --to=TO                    To time, in unix seconds
+        --sort-buffer=1s           Sort buffer is how long to buffer logs,
+                                   attempting to sort them before printing,
+                                   defaults to 1s (second)
+        --search-padding=2s        Search padding is how much of a window
+                                   on either side of From and To to use for
+                                   searching, defaults to 2s (seconds)
+        --stream=STREAM            Stream specifies which of 'stdout' or
+                                   'stderr' to output, defaults to undefined
+                                   (all streams)
+        --format=text              Output format (text, json, ndjson, raw)
+        --search=SEARCH            Only output logs whose message matches the
+                                   regular expression
+        --request-id=REQUEST-ID    Only output logs for the request ID (or
+                                   request ID prefix)
+        --output-file=OUTPUT-FILE  Also write logs to the file
+        --output-file-size=100     Size, in MB, at which the output file is
+                                   rotated (0 disables rotation)
+        --output-file-backups=3    Number of rotated output files to keep
 
   stats regions
     List stats regions
@@ -3746,14 +3990,20 @@ COMMANDS
   stats historical [<flags>]
     View historical stats for a Fastly service
 
-    -s, --service-id=SERVICE-ID  Service ID (falls back to FASTLY_SERVICE_ID,
-                                 then fastly.toml)
-        --from=FROM              From time, accepted formats at
-                                 https://fastly.dev/reference/api/metrics-stats/historical-stats
-        --to=TO                  To time
-        --by=BY                  Aggregation period (minute/hour/day)
-        --region=REGION          Filter by region ('stats regions' to list)
-        --format=FORMAT          Output format (json)
+    -s, --service-id=SERVICE-ID ...
+                         Service ID (falls back to FASTLY_SERVICE_ID, then
+                         fastly.toml), repeat to aggregate the stats of several
+                         services
+        --all            Aggregate the stats of every service
+        --from=FROM      From time, accepted formats at
+                         https://fastly.dev/reference/api/metrics-stats/historical-stats
+        --to=TO          To time
+        --by=BY          Aggregation period (minute/hour/day)
+        --region=REGION  Filter by region ('stats regions' to list)
+        --format=FORMAT  Output format (json, csv, prometheus)
+        --fields=FIELDS  Comma separated stats fields to output in the csv
+                         and prometheus formats, defaults to all fields (e.g.
+                         hit_ratio,requests,errors)
 
   stats realtime [<flags>]
     View realtime stats for a Fastly service
@@ -3761,6 +4011,22 @@ COMMANDS
     -s, --service-id=SERVICE-ID  Service ID (falls back to FASTLY_SERVICE_ID,
                                  then fastly.toml)
         --format=FORMAT          Output format (json)
+        --dashboard              Show the stats in a full screen dashboard
+        --dashboard-window=5m    How much history the dashboard charts
+
+  stats watch --alert=ALERT [<flags>]
+    Watch realtime stats for a Fastly service, failing when an alert condition
+    trips
+
+    -s, --service-id=SERVICE-ID  Service ID (falls back to FASTLY_SERVICE_ID,
+                                 then fastly.toml)
+        --alert=ALERT ...        Alert condition over realtime stats fields,
+                                 e.g. 'errors/requests > 0.02 for 30s'
+                                 (repeatable)
+        --exec=EXEC              Command to run when an alert trips, instead of
+                                 exiting with an error
+        --duration=DURATION      How long to watch for, e.g. 5m (defaults to
+                                 until an alert trips)
 
   vcl custom create --content=CONTENT --name=NAME --version=VERSION [<flags>]
     Upload a VCL for a particular service and version
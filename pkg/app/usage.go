@@ -107,6 +107,8 @@ func Usage(args []string, app *kingpin.Application, out, err io.Writer) string {
 // pkg/app/app.go.
 var globalFlags = map[string]bool{
 	"help":    true,
+	"output":  true,
+	"profile": true,
 	"token":   true,
 	"verbose": true,
 }
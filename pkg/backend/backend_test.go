@@ -2,8 +2,18 @@ package backend_test
 
 import (
 	"bytes"
+	"crypto/tls"
+	"encoding/pem"
 	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/fastly/cli/pkg/app"
@@ -141,6 +151,14 @@ func TestBackendList(t *testing.T) {
 			},
 			WantOutput: listBackendsVerboseOutput,
 		},
+		{
+			Args: args("backend list --service-id 123 --version 1 --output csv --verbose"),
+			API: mock.API{
+				ListVersionsFn: testutil.ListVersions,
+				ListBackendsFn: listBackendsOK,
+			},
+			WantOutput: listBackendsCSVOutput,
+		},
 		{
 			Args: args("backend list --service-id 123 --version 1"),
 			API: mock.API{
@@ -325,6 +343,12 @@ func listBackendsError(i *fastly.ListBackendsInput) ([]*fastly.Backend, error) {
 	return nil, errTest
 }
 
+var listBackendsCSVOutput = strings.TrimSpace(`
+service_id,version,name,comment,address,port,override_host,connect_timeout,max_conn,error_threshold,first_byte_timeout,between_bytes_timeout,auto_loadbalance,weight,request_condition,healthcheck,hostname,shield,use_ssl,ssl_check_cert,ssl_ca_cert,ssl_client_cert,ssl_client_key,ssl_hostname,ssl_cert_hostname,ssl_sni_hostname,min_tls_version,max_tls_version,ssl_ciphers,created_at,updated_at,deleted_at
+123,1,test.com,test,www.test.com,80,,0,0,0,0,0,false,0,,,,,false,false,,,,,,,,,[],,,
+123,1,example.com,example,www.example.com,443,,0,0,0,0,0,false,0,,,,,false,false,,,,,,,,,[],,,
+`) + "\n"
+
 var listBackendsShortOutput = strings.TrimSpace(`
 SERVICE  VERSION  NAME         ADDRESS          PORT  COMMENT
 123      1        test.com     www.test.com     80    test
@@ -449,3 +473,341 @@ func deleteBackendOK(i *fastly.DeleteBackendInput) error {
 func deleteBackendError(i *fastly.DeleteBackendInput) error {
 	return errTest
 }
+
+func TestBackendVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+	tls12 := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	tls12.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	tls12.StartTLS()
+	defer tls12.Close()
+
+	caCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	tlsBackend := func(server *httptest.Server, fn func(b *fastly.Backend)) func(*fastly.GetBackendInput) (*fastly.Backend, error) {
+		return func(i *fastly.GetBackendInput) (*fastly.Backend, error) {
+			addr := server.Listener.Addr().(*net.TCPAddr)
+			b := &fastly.Backend{
+				Name:            i.Name,
+				Address:         addr.IP.String(),
+				Port:            uint(addr.Port),
+				UseSSL:          true,
+				SSLCheckCert:    true,
+				SSLCACert:       caCert,
+				SSLCertHostname: "example.com",
+				SSLSNIHostname:  "www.example.com",
+			}
+			fn(b)
+			return b, nil
+		}
+	}
+
+	args := testutil.Args
+	scenarios := []testutil.TestScenario{
+		{
+			Name: "valid",
+			Args: args("backend verify --service-id 123 --version 1 --name origin"),
+			API: mock.API{
+				ListVersionsFn: testutil.ListVersions,
+				GetBackendFn:   tlsBackend(server, func(*fastly.Backend) {}),
+			},
+			WantOutputs: []string{
+				"SNI hostname: www.example.com",
+				"Certificate names: example.com",
+				"Handshake             ok      negotiated TLS 1.3 with TLS_",
+				"Certificate chain     ok      trusted by the backend's CA certificate",
+				"Certificate hostname  ok      valid for example.com",
+				"Certificate expiry    ok      expires ",
+				"Verified TLS of backend origin",
+			},
+		},
+		{
+			Name: "SNI hostname differs from certificate hostname",
+			Args: args("backend verify --service-id 123 --version 1 --name origin"),
+			API: mock.API{
+				ListVersionsFn: testutil.ListVersions,
+				GetBackendFn: tlsBackend(server, func(b *fastly.Backend) {
+					b.SSLSNIHostname = ""
+					b.SSLHostname = "sni.example.com"
+				}),
+			},
+			WantOutputs: []string{
+				"SNI hostname: sni.example.com",
+				"Certificate hostname  ok      valid for example.com",
+				"Verified TLS of backend origin",
+			},
+		},
+		{
+			Name: "hostname mismatch",
+			Args: args("backend verify --service-id 123 --version 1 --name origin"),
+			API: mock.API{
+				ListVersionsFn: testutil.ListVersions,
+				GetBackendFn: tlsBackend(server, func(b *fastly.Backend) {
+					b.SSLCertHostname = "wrong.test"
+				}),
+			},
+			WantError:  "backend origin failed 1 of 4 TLS checks",
+			WantOutput: "Certificate hostname  error   x509: certificate is valid for example.com",
+		},
+		{
+			Name: "untrusted chain",
+			Args: args("backend verify --service-id 123 --version 1 --name origin"),
+			API: mock.API{
+				ListVersionsFn: testutil.ListVersions,
+				GetBackendFn: tlsBackend(server, func(b *fastly.Backend) {
+					b.SSLCACert = ""
+				}),
+			},
+			WantError:  "backend origin failed 1 of 4 TLS checks",
+			WantOutput: "Certificate chain     error   x509: certificate signed by unknown authority",
+		},
+		{
+			Name: "certificate checking disabled",
+			Args: args("backend verify --service-id 123 --version 1 --name origin"),
+			API: mock.API{
+				ListVersionsFn: testutil.ListVersions,
+				GetBackendFn: tlsBackend(server, func(b *fastly.Backend) {
+					b.SSLCheckCert = false
+					b.SSLCertHostname = "wrong.test"
+				}),
+			},
+			WantOutputs: []string{
+				"Certificate checking  warning  disabled, so Fastly accepts any certificate from the backend",
+				"Certificate hostname  warning  x509: certificate is valid for example.com",
+				"Verified TLS of backend origin",
+			},
+		},
+		{
+			Name: "expiry warning",
+			Args: args("backend verify --service-id 123 --version 1 --name origin --expiry-warning 1000000h"),
+			API: mock.API{
+				ListVersionsFn: testutil.ListVersions,
+				GetBackendFn:   tlsBackend(server, func(*fastly.Backend) {}),
+			},
+			WantOutput: "Certificate expiry    warning  expires ",
+		},
+		{
+			Name: "protocol mismatch",
+			Args: args("backend verify --service-id 123 --version 1 --name origin"),
+			API: mock.API{
+				ListVersionsFn: testutil.ListVersions,
+				GetBackendFn: tlsBackend(tls12, func(b *fastly.Backend) {
+					b.MinTLSVersion = "1.3"
+					b.SSLCiphers = []string{"ECDHE-RSA-AES128-GCM-SHA256:NOT-A-CIPHER"}
+				}),
+			},
+			WantError: "backend origin failed 1 of 5 TLS checks",
+			WantOutputs: []string{
+				"without the backend's TLS version and cipher settings it negotiates TLS 1.2 with TLS_",
+				"Ciphers               warning  can't check ciphers NOT-A-CIPHER",
+			},
+		},
+		{
+			Name: "plain HTTP",
+			Args: args("backend verify --service-id 123 --version 1 --name origin"),
+			API: mock.API{
+				ListVersionsFn: testutil.ListVersions,
+				GetBackendFn: tlsBackend(server, func(b *fastly.Backend) {
+					b.UseSSL = false
+				}),
+			},
+			WantError: "backend origin doesn't use TLS",
+		},
+	}
+
+	for _, testcase := range scenarios {
+		t.Run(testcase.Name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			opts := testutil.NewRunOpts(testcase.Args, &stdout)
+			opts.APIClient = mock.APIClient(testcase.API)
+			err := app.Run(opts)
+			testutil.AssertErrorContains(t, err, testcase.WantError)
+			testutil.AssertStringContains(t, stdout.String(), testcase.WantOutput)
+			for _, want := range testcase.WantOutputs {
+				testutil.AssertStringContains(t, stdout.String(), want)
+			}
+		})
+	}
+}
+
+func TestBackendImport(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	listBackends := func(i *fastly.ListBackendsInput) ([]*fastly.Backend, error) {
+		return []*fastly.Backend{
+			{ServiceID: i.ServiceID, ServiceVersion: i.ServiceVersion, Name: "origin-1", Address: "192.0.2.1", Port: 80},
+			{ServiceID: i.ServiceID, ServiceVersion: i.ServiceVersion, Name: "origin-2", Address: "192.0.2.2", Port: 80, ConnectTimeout: 1000},
+			{ServiceID: i.ServiceID, ServiceVersion: i.ServiceVersion, Name: "stale", Address: "192.0.2.9", Port: 80},
+		}, nil
+	}
+
+	valid := writeFile("backends.yaml", `backends:
+  - name: origin-1
+    address: 192.0.2.1
+    port: 80
+  - name: origin-2
+    port: 443
+    use_ssl: true
+  - name: origin-3
+    address: 192.0.2.3
+    use_ssl: true
+    ssl_cert_hostname: origin.example.com
+`)
+	invalid := writeFile("invalid.json", `{"backends": [
+  {"name": "origin-1", "min_tls_version": "1.4"},
+  {"address": "192.0.2.5"},
+  {"name": "origin-4"},
+  {"name": "origin-4", "address": "192.0.2.4"}
+]}`)
+	failing := writeFile("failing.yml", `backends:
+  - {name: a, address: 192.0.2.10}
+  - {name: b, address: 192.0.2.11}
+  - {name: c, address: 192.0.2.12}
+`)
+
+	for _, testcase := range []struct {
+		name        string
+		args        []string
+		wantError   string
+		wantOutputs []string
+		wantCalls   []string
+	}{
+		{
+			name:      "import",
+			args:      testutil.Args("backend import --service-id 123 --version 1 --autoclone -f " + valid),
+			wantCalls: []string{"create origin-3 192.0.2.3:443 true", "update origin-2 port=443 use_ssl=true"},
+			wantOutputs: []string{
+				"Imported backends to service 123 version 4: 1 created, 1 updated, 0 deleted",
+			},
+		},
+		{
+			name: "dry run",
+			args: testutil.Args("backend import --service-id 123 --version 1 --dry-run --prune -f " + valid),
+			wantOutputs: []string{
+				"BACKEND   CHANGE  FIELDS\norigin-2  update  port, use_ssl\norigin-3  create  \nstale     delete  \n",
+				"Dry run: 1 created, 1 updated, 1 deleted",
+			},
+		},
+		{
+			name: "invalid",
+			args: testutil.Args("backend import --service-id 123 --version 3 -f " + invalid),
+			wantError: strings.Join([]string{
+				"invalid backends in " + invalid + ":",
+				`backend 1 (origin-1): TLS version "1.4" isn't one of 1.0, 1.1, 1.2 or 1.3`,
+				"backend 2: name is required",
+				"backend 3 (origin-4): address is required to create a backend",
+				"backend 4 (origin-4): name is a duplicate",
+			}, "\n\t"),
+		},
+		{
+			name:      "rollback",
+			args:      testutil.Args("backend import --service-id 123 --version 3 --concurrency 1 -f " + failing),
+			wantError: "failed to import 1 of 3 backend changes: error creating backend b: " + testutil.Err.Error(),
+			wantCalls: []string{"create a 192.0.2.10:0 false", "create b 192.0.2.11:0 false", "delete a"},
+			wantOutputs: []string{
+				"Rolling back the 1 backend changes which were made.",
+			},
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			var (
+				mu    sync.Mutex
+				calls []string
+			)
+			record := func(format string, args ...interface{}) {
+				mu.Lock()
+				defer mu.Unlock()
+				calls = append(calls, fmt.Sprintf(format, args...))
+			}
+			api := mock.API{
+				ListVersionsFn: testutil.ListVersions,
+				CloneVersionFn: testutil.CloneVersionResult(4),
+				ListBackendsFn: listBackends,
+				CreateBackendFn: func(i *fastly.CreateBackendInput) (*fastly.Backend, error) {
+					record("create %s %s:%d %t", i.Name, i.Address, i.Port, i.UseSSL)
+					if i.Name == "b" {
+						return nil, testutil.Err
+					}
+					return &fastly.Backend{Name: i.Name}, nil
+				},
+				UpdateBackendFn: func(i *fastly.UpdateBackendInput) (*fastly.Backend, error) {
+					record("update %s port=%d use_ssl=%t", i.Name, *i.Port, *i.UseSSL)
+					return &fastly.Backend{Name: i.Name}, nil
+				},
+				DeleteBackendFn: func(i *fastly.DeleteBackendInput) error {
+					record("delete %s", i.Name)
+					return nil
+				},
+			}
+
+			var stdout bytes.Buffer
+			opts := testutil.NewRunOpts(testcase.args, &stdout)
+			opts.APIClient = mock.APIClient(api)
+			err := app.Run(opts)
+			testutil.AssertErrorContains(t, err, testcase.wantError)
+			for _, want := range testcase.wantOutputs {
+				testutil.AssertStringContains(t, stdout.String(), want)
+			}
+			sort.Strings(calls)
+			sort.Strings(testcase.wantCalls)
+			testutil.AssertEqual(t, testcase.wantCalls, calls)
+		})
+	}
+}
+
+func TestBackendExport(t *testing.T) {
+	api := mock.API{
+		ListVersionsFn: testutil.ListVersions,
+		ListBackendsFn: func(i *fastly.ListBackendsInput) ([]*fastly.Backend, error) {
+			return []*fastly.Backend{
+				{Name: "origin-2", Address: "192.0.2.2", Port: 443, UseSSL: true, SSLCheckCert: true, SSLCertHostname: "origin.example.com"},
+				{Name: "origin-1", Address: "192.0.2.1", Port: 80, ConnectTimeout: 1000, SSLCiphers: []string{"ECDHE-RSA-AES128-GCM-SHA256"}},
+			}, nil
+		},
+	}
+
+	var stdout bytes.Buffer
+	opts := testutil.NewRunOpts(testutil.Args("backend export --service-id 123 --version 1"), &stdout)
+	opts.APIClient = mock.APIClient(api)
+	err := app.Run(opts)
+	testutil.AssertNoError(t, err)
+	testutil.AssertString(t, `backends:
+- name: origin-1
+  address: 192.0.2.1
+  port: 80
+  connect_timeout: 1000
+  auto_loadbalance: false
+  use_ssl: false
+  ssl_check_cert: false
+  ssl_ciphers:
+  - ECDHE-RSA-AES128-GCM-SHA256
+- name: origin-2
+  address: 192.0.2.2
+  port: 443
+  auto_loadbalance: false
+  use_ssl: true
+  ssl_check_cert: true
+  ssl_cert_hostname: origin.example.com
+`, stdout.String())
+
+	path := filepath.Join(t.TempDir(), "backends.json")
+	stdout.Reset()
+	opts = testutil.NewRunOpts(testutil.Args("backend export --service-id 123 --version 1 -f "+path), &stdout)
+	opts.APIClient = mock.APIClient(api)
+	err = app.Run(opts)
+	testutil.AssertNoError(t, err)
+	testutil.AssertStringContains(t, stdout.String(), "Exported 2 backends of service 123 version 1 to "+path)
+
+	bs, err := os.ReadFile(path)
+	testutil.AssertNoError(t, err)
+	testutil.AssertStringContains(t, string(bs), `{
+  "backends": [
+    {
+      "name": "origin-1",
+      "address": "192.0.2.1",`)
+}
@@ -0,0 +1,117 @@
+package backend
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fastly/cli/pkg/cmd"
+	"github.com/fastly/cli/pkg/compute/manifest"
+	"github.com/fastly/cli/pkg/config"
+	"github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/text"
+	"github.com/fastly/go-fastly/v3/fastly"
+)
+
+// ExportCommand calls the Fastly API to write the backends of a service
+// version to a file which `backend import` can read.
+type ExportCommand struct {
+	cmd.Base
+	manifest       manifest.Data
+	Input          fastly.ListBackendsInput
+	serviceVersion cmd.OptionalServiceVersion
+
+	file   string
+	format string
+}
+
+// NewExportCommand returns a usable command registered under the parent.
+func NewExportCommand(parent cmd.Registerer, globals *config.Data) *ExportCommand {
+	var c ExportCommand
+	c.Globals = globals
+	c.manifest.File.SetOutput(c.Globals.Output)
+	c.manifest.File.Read(manifest.Filename)
+	c.CmdClause = parent.Command("export", "Export the backends of a Fastly service version to a file, sorted by name")
+	c.RegisterServiceIDFlag(&c.manifest.Flag.ServiceID)
+	c.RegisterServiceVersionFlag(cmd.ServiceVersionFlagOpts{
+		Dst: &c.serviceVersion.Value,
+	})
+	c.CmdClause.Flag("file", "Path of the file to write (defaults to stdout)").Short('f').StringVar(&c.file)
+	c.CmdClause.Flag("format", "File format, inferred from the --file extension if not set (json, yaml)").HintOptions(formats...).EnumVar(&c.format, formats...)
+	return &c
+}
+
+// Exec invokes the application logic for the command.
+func (c *ExportCommand) Exec(in io.Reader, out io.Writer) error {
+	format := c.format
+	if format == "" {
+		format = formatYAML
+		if c.file != "" {
+			f, err := formatFromPath(c.file)
+			if err != nil {
+				return errors.RemediationError{
+					Inner:       err,
+					Remediation: "Set the file format with the --format flag.",
+				}
+			}
+			format = f
+		}
+	}
+
+	serviceID, serviceVersion, err := cmd.ServiceDetails(cmd.ServiceDetailsOpts{
+		AllowActiveLocked:  true,
+		Client:             c.Globals.Client,
+		Manifest:           c.manifest,
+		Out:                out,
+		ServiceVersionFlag: c.serviceVersion,
+		VerboseMode:        c.Globals.Flag.Verbose,
+	})
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID":      serviceID,
+			"Service Version": errors.ServiceVersion(serviceVersion),
+		})
+		return err
+	}
+
+	c.Input.ServiceID = serviceID
+	c.Input.ServiceVersion = serviceVersion.Number
+
+	backends, err := c.Globals.Client.ListBackends(&c.Input)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID":      serviceID,
+			"Service Version": serviceVersion.Number,
+		})
+		return err
+	}
+
+	specs := make([]backendSpec, 0, len(backends))
+	for _, b := range backends {
+		specs = append(specs, specFromBackend(b))
+	}
+	sortSpecs(specs)
+
+	bs, err := encodeBackends(specs, format)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return fmt.Errorf("error encoding backends: %w", err)
+	}
+
+	// Only the backends are written to stdout so they can be redirected to a
+	// file.
+	if c.file == "" {
+		_, err = out.Write(bs)
+		return err
+	}
+
+	if err := os.WriteFile(c.file, bs, filePermissions); err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"File": c.file,
+		})
+		return fmt.Errorf("error writing backends: %w", err)
+	}
+
+	text.Success(out, "Exported %d backends of service %s version %d to %s", len(specs), serviceID, serviceVersion.Number, c.file)
+	return nil
+}
@@ -0,0 +1,331 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fastly/go-fastly/v3/fastly"
+	"gopkg.in/yaml.v2"
+)
+
+// Backend file formats.
+const (
+	formatJSON = "json"
+	formatYAML = "yaml"
+)
+
+// formats is every supported backend file format.
+var formats = []string{formatJSON, formatYAML}
+
+// filePermissions is the file mode of exported backend files, which may
+// contain client keys.
+const filePermissions = 0600
+
+// maxPort is the largest valid TCP port.
+const maxPort = 65535
+
+// backendFile is the file format of backend import and export.
+type backendFile struct {
+	Backends []backendSpec `json:"backends" yaml:"backends"`
+}
+
+// backendSpec is a backend in a backend file. Fields which aren't set are
+// left as they are when a backend is updated.
+type backendSpec struct {
+	Name                string   `json:"name" yaml:"name"`
+	Comment             *string  `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Address             *string  `json:"address,omitempty" yaml:"address,omitempty"`
+	Port                *uint    `json:"port,omitempty" yaml:"port,omitempty"`
+	OverrideHost        *string  `json:"override_host,omitempty" yaml:"override_host,omitempty"`
+	ConnectTimeout      *uint    `json:"connect_timeout,omitempty" yaml:"connect_timeout,omitempty"`
+	MaxConn             *uint    `json:"max_conn,omitempty" yaml:"max_conn,omitempty"`
+	FirstByteTimeout    *uint    `json:"first_byte_timeout,omitempty" yaml:"first_byte_timeout,omitempty"`
+	BetweenBytesTimeout *uint    `json:"between_bytes_timeout,omitempty" yaml:"between_bytes_timeout,omitempty"`
+	AutoLoadbalance     *bool    `json:"auto_loadbalance,omitempty" yaml:"auto_loadbalance,omitempty"`
+	Weight              *uint    `json:"weight,omitempty" yaml:"weight,omitempty"`
+	RequestCondition    *string  `json:"request_condition,omitempty" yaml:"request_condition,omitempty"`
+	HealthCheck         *string  `json:"healthcheck,omitempty" yaml:"healthcheck,omitempty"`
+	Shield              *string  `json:"shield,omitempty" yaml:"shield,omitempty"`
+	UseSSL              *bool    `json:"use_ssl,omitempty" yaml:"use_ssl,omitempty"`
+	SSLCheckCert        *bool    `json:"ssl_check_cert,omitempty" yaml:"ssl_check_cert,omitempty"`
+	SSLCACert           *string  `json:"ssl_ca_cert,omitempty" yaml:"ssl_ca_cert,omitempty"`
+	SSLClientCert       *string  `json:"ssl_client_cert,omitempty" yaml:"ssl_client_cert,omitempty"`
+	SSLClientKey        *string  `json:"ssl_client_key,omitempty" yaml:"ssl_client_key,omitempty"`
+	SSLCertHostname     *string  `json:"ssl_cert_hostname,omitempty" yaml:"ssl_cert_hostname,omitempty"`
+	SSLSNIHostname      *string  `json:"ssl_sni_hostname,omitempty" yaml:"ssl_sni_hostname,omitempty"`
+	MinTLSVersion       *string  `json:"min_tls_version,omitempty" yaml:"min_tls_version,omitempty"`
+	MaxTLSVersion       *string  `json:"max_tls_version,omitempty" yaml:"max_tls_version,omitempty"`
+	SSLCiphers          []string `json:"ssl_ciphers,omitempty" yaml:"ssl_ciphers,omitempty"`
+}
+
+// formatFromPath deduces the format of a backend file from its extension.
+func formatFromPath(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return formatJSON, nil
+	case ".yaml", ".yml":
+		return formatYAML, nil
+	}
+	return "", fmt.Errorf("unrecognised backend file extension %q (expected .json, .yaml or .yml)", filepath.Ext(path))
+}
+
+// readBackends reads the backends in the JSON or YAML file at path.
+func readBackends(path string) ([]backendSpec, error) {
+	format, err := formatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	bs, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var f backendFile
+	switch format {
+	case formatJSON:
+		dec := json.NewDecoder(bytes.NewReader(bs))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&f)
+	case formatYAML:
+		err = yaml.UnmarshalStrict(bs, &f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return f.Backends, nil
+}
+
+// encodeBackends encodes backends in the given format. The output can be read
+// by readBackends.
+func encodeBackends(specs []backendSpec, format string) ([]byte, error) {
+	f := backendFile{Backends: specs}
+	switch format {
+	case formatJSON:
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(f); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case formatYAML:
+		return yaml.Marshal(f)
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// validateBackends checks every backend in a file, returning all of the
+// problems found. Backends which don't exist yet need an address.
+func validateBackends(specs []backendSpec, existing map[string]*fastly.Backend) []string {
+	var (
+		problems []string
+		seen     = make(map[string]bool)
+	)
+	for i, s := range specs {
+		label := fmt.Sprintf("backend %d", i+1)
+		if s.Name != "" {
+			label = fmt.Sprintf("backend %d (%s)", i+1, s.Name)
+		}
+		problem := func(format string, args ...interface{}) {
+			problems = append(problems, label+": "+fmt.Sprintf(format, args...))
+		}
+
+		switch {
+		case s.Name == "":
+			problem("name is required")
+		case seen[s.Name]:
+			problem("name is a duplicate")
+		}
+		seen[s.Name] = true
+
+		if _, ok := existing[s.Name]; !ok && (s.Address == nil || *s.Address == "") {
+			problem("address is required to create a backend")
+		}
+		if s.Port != nil && *s.Port > maxPort {
+			problem("port %d is out of range", *s.Port)
+		}
+		for _, v := range []*string{s.MinTLSVersion, s.MaxTLSVersion} {
+			if v == nil || *v == "" {
+				continue
+			}
+			if _, ok := tlsVersions[*v]; !ok {
+				problem("TLS version %q isn't one of 1.0, 1.1, 1.2 or 1.3", *v)
+			}
+		}
+		if (s.SSLClientCert == nil) != (s.SSLClientKey == nil) {
+			problem("ssl_client_cert and ssl_client_key must be set together")
+		}
+	}
+	return problems
+}
+
+// specFromBackend returns the backend file entry of a backend. Booleans are
+// always set so an exported file fully describes the backend.
+func specFromBackend(b *fastly.Backend) backendSpec {
+	return backendSpec{
+		Name:                b.Name,
+		Comment:             optionalString(b.Comment),
+		Address:             optionalString(b.Address),
+		Port:                optionalUint(b.Port),
+		OverrideHost:        optionalString(b.OverrideHost),
+		ConnectTimeout:      optionalUint(b.ConnectTimeout),
+		MaxConn:             optionalUint(b.MaxConn),
+		FirstByteTimeout:    optionalUint(b.FirstByteTimeout),
+		BetweenBytesTimeout: optionalUint(b.BetweenBytesTimeout),
+		AutoLoadbalance:     fastly.Bool(b.AutoLoadbalance),
+		Weight:              optionalUint(b.Weight),
+		RequestCondition:    optionalString(b.RequestCondition),
+		HealthCheck:         optionalString(b.HealthCheck),
+		Shield:              optionalString(b.Shield),
+		UseSSL:              fastly.Bool(b.UseSSL),
+		SSLCheckCert:        fastly.Bool(b.SSLCheckCert),
+		SSLCACert:           optionalString(b.SSLCACert),
+		SSLClientCert:       optionalString(b.SSLClientCert),
+		SSLClientKey:        optionalString(b.SSLClientKey),
+		SSLCertHostname:     optionalString(b.SSLCertHostname),
+		SSLSNIHostname:      optionalString(b.SSLSNIHostname),
+		MinTLSVersion:       optionalString(b.MinTLSVersion),
+		MaxTLSVersion:       optionalString(b.MaxTLSVersion),
+		SSLCiphers:          b.SSLCiphers,
+	}
+}
+
+// createInput returns the input to create the backend in a file.
+func createInput(serviceID string, serviceVersion int, s backendSpec) *fastly.CreateBackendInput {
+	i := &fastly.CreateBackendInput{
+		ServiceID:      serviceID,
+		ServiceVersion: serviceVersion,
+		Name:           s.Name,
+		SSLCiphers:     s.SSLCiphers,
+	}
+	assignString(&i.Comment, s.Comment)
+	assignString(&i.Address, s.Address)
+	assignUint(&i.Port, s.Port)
+	assignString(&i.OverrideHost, s.OverrideHost)
+	assignUint(&i.ConnectTimeout, s.ConnectTimeout)
+	assignUint(&i.MaxConn, s.MaxConn)
+	assignUint(&i.FirstByteTimeout, s.FirstByteTimeout)
+	assignUint(&i.BetweenBytesTimeout, s.BetweenBytesTimeout)
+	assignBool(&i.AutoLoadbalance, s.AutoLoadbalance)
+	assignUint(&i.Weight, s.Weight)
+	assignString(&i.RequestCondition, s.RequestCondition)
+	assignString(&i.HealthCheck, s.HealthCheck)
+	assignString(&i.Shield, s.Shield)
+	assignBool(&i.UseSSL, s.UseSSL)
+	assignBool(&i.SSLCheckCert, s.SSLCheckCert)
+	assignString(&i.SSLCACert, s.SSLCACert)
+	assignString(&i.SSLClientCert, s.SSLClientCert)
+	assignString(&i.SSLClientKey, s.SSLClientKey)
+	assignString(&i.SSLCertHostname, s.SSLCertHostname)
+	assignString(&i.SSLSNIHostname, s.SSLSNIHostname)
+	assignString(&i.MinTLSVersion, s.MinTLSVersion)
+	assignString(&i.MaxTLSVersion, s.MaxTLSVersion)
+
+	// As with `backend create`, TLS backends default to port 443.
+	if i.UseSSL && i.Port == 0 {
+		i.Port = 443
+	}
+	return i
+}
+
+// updateInputs returns the input to update a backend to match its entry in a
+// file, the input which reverts that update, and the names of the fields
+// which change. There are no changes when the backend already matches.
+func updateInputs(b *fastly.Backend, s backendSpec) (update, revert *fastly.UpdateBackendInput, changes []string) {
+	update = &fastly.UpdateBackendInput{ServiceID: b.ServiceID, ServiceVersion: b.ServiceVersion, Name: b.Name}
+	revert = &fastly.UpdateBackendInput{ServiceID: b.ServiceID, ServiceVersion: b.ServiceVersion, Name: b.Name}
+
+	str := func(field string, want *string, have string, dst, old **string) {
+		if want != nil && *want != have {
+			*dst, *old = fastly.String(*want), fastly.String(have)
+			changes = append(changes, field)
+		}
+	}
+	num := func(field string, want *uint, have uint, dst, old **uint) {
+		if want != nil && *want != have {
+			*dst, *old = fastly.Uint(*want), fastly.Uint(have)
+			changes = append(changes, field)
+		}
+	}
+	boolean := func(field string, want *bool, have bool, dst, old **fastly.Compatibool) {
+		if want != nil && *want != have {
+			*dst, *old = fastly.CBool(*want), fastly.CBool(have)
+			changes = append(changes, field)
+		}
+	}
+
+	str("comment", s.Comment, b.Comment, &update.Comment, &revert.Comment)
+	str("address", s.Address, b.Address, &update.Address, &revert.Address)
+	num("port", s.Port, b.Port, &update.Port, &revert.Port)
+	str("override_host", s.OverrideHost, b.OverrideHost, &update.OverrideHost, &revert.OverrideHost)
+	num("connect_timeout", s.ConnectTimeout, b.ConnectTimeout, &update.ConnectTimeout, &revert.ConnectTimeout)
+	num("max_conn", s.MaxConn, b.MaxConn, &update.MaxConn, &revert.MaxConn)
+	num("first_byte_timeout", s.FirstByteTimeout, b.FirstByteTimeout, &update.FirstByteTimeout, &revert.FirstByteTimeout)
+	num("between_bytes_timeout", s.BetweenBytesTimeout, b.BetweenBytesTimeout, &update.BetweenBytesTimeout, &revert.BetweenBytesTimeout)
+	boolean("auto_loadbalance", s.AutoLoadbalance, b.AutoLoadbalance, &update.AutoLoadbalance, &revert.AutoLoadbalance)
+	num("weight", s.Weight, b.Weight, &update.Weight, &revert.Weight)
+	str("request_condition", s.RequestCondition, b.RequestCondition, &update.RequestCondition, &revert.RequestCondition)
+	str("healthcheck", s.HealthCheck, b.HealthCheck, &update.HealthCheck, &revert.HealthCheck)
+	str("shield", s.Shield, b.Shield, &update.Shield, &revert.Shield)
+	boolean("use_ssl", s.UseSSL, b.UseSSL, &update.UseSSL, &revert.UseSSL)
+	boolean("ssl_check_cert", s.SSLCheckCert, b.SSLCheckCert, &update.SSLCheckCert, &revert.SSLCheckCert)
+	str("ssl_ca_cert", s.SSLCACert, b.SSLCACert, &update.SSLCACert, &revert.SSLCACert)
+	str("ssl_client_cert", s.SSLClientCert, b.SSLClientCert, &update.SSLClientCert, &revert.SSLClientCert)
+	str("ssl_client_key", s.SSLClientKey, b.SSLClientKey, &update.SSLClientKey, &revert.SSLClientKey)
+	str("ssl_cert_hostname", s.SSLCertHostname, b.SSLCertHostname, &update.SSLCertHostname, &revert.SSLCertHostname)
+	str("ssl_sni_hostname", s.SSLSNIHostname, b.SSLSNIHostname, &update.SSLSNIHostname, &revert.SSLSNIHostname)
+	str("min_tls_version", s.MinTLSVersion, b.MinTLSVersion, &update.MinTLSVersion, &revert.MinTLSVersion)
+	str("max_tls_version", s.MaxTLSVersion, b.MaxTLSVersion, &update.MaxTLSVersion, &revert.MaxTLSVersion)
+	if s.SSLCiphers != nil && strings.Join(s.SSLCiphers, ",") != strings.Join(b.SSLCiphers, ",") {
+		update.SSLCiphers, revert.SSLCiphers = s.SSLCiphers, b.SSLCiphers
+		changes = append(changes, "ssl_ciphers")
+	}
+
+	return update, revert, changes
+}
+
+// sortSpecs orders backend file entries by name.
+func sortSpecs(specs []backendSpec) {
+	sort.Slice(specs, func(i, j int) bool {
+		return specs[i].Name < specs[j].Name
+	})
+}
+
+func optionalString(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return fastly.String(v)
+}
+
+func optionalUint(v uint) *uint {
+	if v == 0 {
+		return nil
+	}
+	return fastly.Uint(v)
+}
+
+func assignString(dst *string, v *string) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+func assignUint(dst *uint, v *uint) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+func assignBool(dst *fastly.Compatibool, v *bool) {
+	if v != nil {
+		*dst = fastly.Compatibool(*v)
+	}
+}
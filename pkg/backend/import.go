@@ -0,0 +1,312 @@
+package backend
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fastly/cli/pkg/cmd"
+	"github.com/fastly/cli/pkg/compute/manifest"
+	"github.com/fastly/cli/pkg/config"
+	"github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/text"
+	"github.com/fastly/cli/pkg/undo"
+	"github.com/fastly/go-fastly/v3/fastly"
+)
+
+// Import operations.
+const (
+	opCreate = "create"
+	opUpdate = "update"
+	opDelete = "delete"
+)
+
+// opVerbs are the present participle and past tense of each operation.
+var opVerbs = map[string][2]string{
+	opCreate: {"creating", "Created"},
+	opUpdate: {"updating", "Updated"},
+	opDelete: {"deleting", "Deleted"},
+}
+
+// ImportCommand calls the Fastly API to make the backends of a service version
+// match a file.
+type ImportCommand struct {
+	cmd.Base
+	manifest       manifest.Data
+	serviceVersion cmd.OptionalServiceVersion
+	autoClone      cmd.OptionalAutoClone
+
+	file        string
+	concurrency int
+	dryRun      bool
+	prune       bool
+}
+
+// NewImportCommand returns a usable command registered under the parent.
+func NewImportCommand(parent cmd.Registerer, globals *config.Data) *ImportCommand {
+	var c ImportCommand
+	c.Globals = globals
+	c.manifest.File.SetOutput(c.Globals.Output)
+	c.manifest.File.Read(manifest.Filename)
+	c.CmdClause = parent.Command("import", "Create or update the backends of a Fastly service version to match a JSON or YAML file")
+	c.RegisterServiceIDFlag(&c.manifest.Flag.ServiceID)
+	c.RegisterServiceVersionFlag(cmd.ServiceVersionFlagOpts{
+		Dst: &c.serviceVersion.Value,
+	})
+	c.RegisterAutoCloneFlag(cmd.AutoCloneFlagOpts{
+		Action: c.autoClone.Set,
+		Dst:    &c.autoClone.Value,
+	})
+	c.CmdClause.Flag("file", "Path of the JSON or YAML file of backends, as written by 'fastly backend export'").Short('f').Required().StringVar(&c.file)
+	c.CmdClause.Flag("concurrency", "Number of backends to change at once").Default("5").IntVar(&c.concurrency)
+	c.CmdClause.Flag("dry-run", "Print the changes required to import the backends without making them").BoolVar(&c.dryRun)
+	c.CmdClause.Flag("prune", "Delete backends which aren't in the file").BoolVar(&c.prune)
+	return &c
+}
+
+// operation is a change to a backend made by an import.
+type operation struct {
+	kind    string
+	name    string
+	changes []string
+
+	apply func() error
+	undo  func() error
+}
+
+// Exec invokes the application logic for the command.
+func (c *ImportCommand) Exec(in io.Reader, out io.Writer) (err error) {
+	specs, err := readBackends(c.file)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"File": c.file,
+		})
+		return err
+	}
+
+	opts := cmd.ServiceDetailsOpts{
+		AutoCloneFlag:      c.autoClone,
+		Client:             c.Globals.Client,
+		Manifest:           c.manifest,
+		Out:                out,
+		ServiceVersionFlag: c.serviceVersion,
+		VerboseMode:        c.Globals.Flag.Verbose,
+	}
+	if c.dryRun {
+		// Nothing is changed, so there's no need for an editable version.
+		opts.AutoCloneFlag = cmd.OptionalAutoClone{}
+		opts.AllowActiveLocked = true
+	}
+	serviceID, serviceVersion, err := cmd.ServiceDetails(opts)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID":      serviceID,
+			"Service Version": errors.ServiceVersion(serviceVersion),
+		})
+		return err
+	}
+
+	current, err := c.Globals.Client.ListBackends(&fastly.ListBackendsInput{
+		ServiceID:      serviceID,
+		ServiceVersion: serviceVersion.Number,
+	})
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID":      serviceID,
+			"Service Version": serviceVersion.Number,
+		})
+		return err
+	}
+	existing := make(map[string]*fastly.Backend, len(current))
+	for _, b := range current {
+		existing[b.Name] = b
+	}
+
+	// Every entry is validated before anything is changed.
+	if problems := validateBackends(specs, existing); len(problems) > 0 {
+		return errors.RemediationError{
+			Inner:       fmt.Errorf("invalid backends in %s:\n\t%s", c.file, strings.Join(problems, "\n\t")),
+			Remediation: "Fix the backends in the file, then retry the import. Nothing has been changed.",
+		}
+	}
+
+	ops := c.operations(serviceID, serviceVersion.Number, specs, existing)
+	if len(ops) == 0 {
+		text.Info(out, "The backends of service %s version %d already match %s", serviceID, serviceVersion.Number, c.file)
+		return nil
+	}
+
+	counts := make(map[string]int)
+	for _, op := range ops {
+		counts[op.kind]++
+	}
+	summary := fmt.Sprintf("%d created, %d updated, %d deleted", counts[opCreate], counts[opUpdate], counts[opDelete])
+
+	if c.dryRun {
+		t := text.NewTable(out)
+		t.AddHeader("BACKEND", "CHANGE", "FIELDS")
+		for _, op := range ops {
+			t.AddLine(op.name, op.kind, strings.Join(op.changes, ", "))
+		}
+		t.Print()
+		text.Break(out)
+		text.Info(out, "Dry run: %s", summary)
+		return nil
+	}
+
+	undoStack := undo.NewStack()
+	defer func() { undoStack.RunIfError(out, err) }()
+
+	if failed := c.apply(ops, undoStack, out); len(failed) > 0 {
+		for _, ferr := range failed {
+			c.Globals.ErrLog.AddWithContext(ferr, map[string]interface{}{
+				"Service ID":      serviceID,
+				"Service Version": serviceVersion.Number,
+			})
+		}
+		text.Warning(out, "Rolling back the %d backend changes which were made.", undoStack.Len())
+		return fmt.Errorf("failed to import %d of %d backend changes: %w", len(failed), len(ops), failed[0])
+	}
+
+	text.Success(out, "Imported backends to service %s version %d: %s", serviceID, serviceVersion.Number, summary)
+	return nil
+}
+
+// operations returns the changes, ordered by backend name, which make the
+// existing backends match the file. Backends which aren't in the file are
+// only deleted when pruning.
+func (c *ImportCommand) operations(serviceID string, serviceVersion int, specs []backendSpec, existing map[string]*fastly.Backend) []operation {
+	var (
+		ops    []operation
+		client = c.Globals.Client
+		inFile = make(map[string]bool, len(specs))
+	)
+
+	for _, s := range specs {
+		inFile[s.Name] = true
+
+		b, ok := existing[s.Name]
+		if !ok {
+			input := createInput(serviceID, serviceVersion, s)
+			ops = append(ops, operation{
+				kind: opCreate,
+				name: s.Name,
+				apply: func() error {
+					_, err := client.CreateBackend(input)
+					return err
+				},
+				undo: func() error {
+					return client.DeleteBackend(&fastly.DeleteBackendInput{
+						ServiceID:      serviceID,
+						ServiceVersion: serviceVersion,
+						Name:           input.Name,
+					})
+				},
+			})
+			continue
+		}
+
+		update, revert, changes := updateInputs(b, s)
+		if len(changes) == 0 {
+			continue
+		}
+		update.ServiceID, update.ServiceVersion = serviceID, serviceVersion
+		revert.ServiceID, revert.ServiceVersion = serviceID, serviceVersion
+		ops = append(ops, operation{
+			kind:    opUpdate,
+			name:    s.Name,
+			changes: changes,
+			apply: func() error {
+				_, err := client.UpdateBackend(update)
+				return err
+			},
+			undo: func() error {
+				_, err := client.UpdateBackend(revert)
+				return err
+			},
+		})
+	}
+
+	if c.prune {
+		for name, b := range existing {
+			if inFile[name] {
+				continue
+			}
+			recreate := createInput(serviceID, serviceVersion, specFromBackend(b))
+			ops = append(ops, operation{
+				kind: opDelete,
+				name: name,
+				apply: func() error {
+					return client.DeleteBackend(&fastly.DeleteBackendInput{
+						ServiceID:      serviceID,
+						ServiceVersion: serviceVersion,
+						Name:           recreate.Name,
+					})
+				},
+				undo: func() error {
+					_, err := client.CreateBackend(recreate)
+					return err
+				},
+			})
+		}
+	}
+
+	sort.SliceStable(ops, func(i, j int) bool {
+		return ops[i].name < ops[j].name
+	})
+	return ops
+}
+
+// apply makes the changes with up to --concurrency at once, pushing the undo
+// of each successful change onto the stack. Once a change fails no more are
+// started. The errors of the failed changes are returned.
+func (c *ImportCommand) apply(ops []operation, undoStack undo.Stacker, out io.Writer) []error {
+	workers := c.concurrency
+	if workers < 1 {
+		workers = 1
+	}
+
+	var (
+		mu     sync.Mutex
+		failed []error
+		wg     sync.WaitGroup
+		jobs   = make(chan operation)
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for op := range jobs {
+				mu.Lock()
+				stop := len(failed) > 0
+				mu.Unlock()
+				if stop {
+					continue
+				}
+
+				err := op.apply()
+
+				mu.Lock()
+				if err != nil {
+					failed = append(failed, fmt.Errorf("error %s backend %s: %w", opVerbs[op.kind][0], op.name, err))
+				} else {
+					undoStack.Push(op.undo)
+					if c.Globals.Verbose() {
+						text.Output(out, "%s backend %s", opVerbs[op.kind][1], op.name)
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, op := range ops {
+		jobs <- op
+	}
+	close(jobs)
+	wg.Wait()
+
+	return failed
+}
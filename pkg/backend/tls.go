@@ -0,0 +1,191 @@
+package backend
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/fastly/go-fastly/v3/fastly"
+)
+
+// tlsVersions maps the TLS versions of a backend to crypto/tls versions.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// cipherSuites maps the OpenSSL names of the ciphers which may be configured
+// on a backend to the crypto/tls cipher suites. TLS 1.3 cipher suites aren't
+// configurable.
+var cipherSuites = map[string]uint16{
+	"ECDHE-ECDSA-AES128-GCM-SHA256": tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	"ECDHE-RSA-AES128-GCM-SHA256":   tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	"ECDHE-ECDSA-AES256-GCM-SHA384": tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	"ECDHE-RSA-AES256-GCM-SHA384":   tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	"ECDHE-ECDSA-CHACHA20-POLY1305": tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+	"ECDHE-RSA-CHACHA20-POLY1305":   tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+	"ECDHE-ECDSA-AES128-SHA256":     tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256,
+	"ECDHE-RSA-AES128-SHA256":       tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256,
+	"ECDHE-ECDSA-AES128-SHA":        tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,
+	"ECDHE-RSA-AES128-SHA":          tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+	"ECDHE-ECDSA-AES256-SHA":        tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA,
+	"ECDHE-RSA-AES256-SHA":          tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+	"ECDHE-RSA-DES-CBC3-SHA":        tls.TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA,
+	"AES128-GCM-SHA256":             tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
+	"AES256-GCM-SHA384":             tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+	"AES128-SHA256":                 tls.TLS_RSA_WITH_AES_128_CBC_SHA256,
+	"AES128-SHA":                    tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+	"AES256-SHA":                    tls.TLS_RSA_WITH_AES_256_CBC_SHA,
+	"DES-CBC3-SHA":                  tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA,
+}
+
+// Address returns the host and port a backend's connections are made to. The
+// port defaults by protocol.
+func Address(b *fastly.Backend) string {
+	port := b.Port
+	switch {
+	case port != 0:
+	case b.UseSSL:
+		port = 443
+	default:
+		port = 80
+	}
+	return net.JoinHostPort(b.Address, strconv.Itoa(int(port)))
+}
+
+// SNIHostname returns the hostname a backend's TLS connections send as the
+// server name. The certificate hostname only affects verification, so like
+// Fastly it is never sent.
+func SNIHostname(b *fastly.Backend) string {
+	return firstNonEmpty(b.SSLSNIHostname, b.SSLHostname, b.Address)
+}
+
+// CertHostname returns the hostname a backend's certificate must be valid for.
+func CertHostname(b *fastly.Backend) string {
+	return firstNonEmpty(b.SSLCertHostname, b.SSLHostname, b.Address)
+}
+
+// TLSConfig returns the TLS configuration of a backend's connections. The SNI
+// hostname is sent to the backend and, when certificate checking is enabled,
+// its certificate chain is verified against the configured CA and the
+// certificate hostname.
+func TLSConfig(b *fastly.Backend) (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName: SNIHostname(b),
+		// The certificate is verified by VerifyConnection, against the
+		// certificate hostname rather than the SNI hostname.
+		InsecureSkipVerify: true, // #nosec G402
+	}
+
+	if b.MinTLSVersion != "" {
+		v, ok := tlsVersions[b.MinTLSVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported minimum TLS version %q", b.MinTLSVersion)
+		}
+		cfg.MinVersion = v
+	}
+	if b.MaxTLSVersion != "" {
+		v, ok := tlsVersions[b.MaxTLSVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported maximum TLS version %q", b.MaxTLSVersion)
+		}
+		cfg.MaxVersion = v
+	}
+
+	suites, _ := CipherSuites(b)
+	cfg.CipherSuites = suites
+
+	if b.SSLClientCert != "" || b.SSLClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(b.SSLClientCert), []byte(b.SSLClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("error reading client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	roots, err := RootCAs(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.SSLCheckCert {
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return fmt.Errorf("backend presented no certificate")
+			}
+			return VerifyChain(cs.PeerCertificates, roots, CertHostname(b))
+		}
+	}
+
+	return cfg, nil
+}
+
+// CipherSuites returns the cipher suites configured on a backend, and the
+// names of any ciphers which have no crypto/tls equivalent. No cipher suites
+// means the defaults are used.
+func CipherSuites(b *fastly.Backend) (suites []uint16, unknown []string) {
+	for _, list := range b.SSLCiphers {
+		for _, name := range strings.FieldsFunc(list, func(r rune) bool {
+			return r == ':' || r == ',' || r == ' '
+		}) {
+			if id, ok := cipherSuites[strings.ToUpper(name)]; ok {
+				suites = append(suites, id)
+			} else {
+				unknown = append(unknown, name)
+			}
+		}
+	}
+	return suites, unknown
+}
+
+// RootCAs returns the pool of the backend's CA certificate, or nil to use the
+// system's pool when there isn't one.
+func RootCAs(b *fastly.Backend) (*x509.CertPool, error) {
+	if b.SSLCACert == "" {
+		return nil, nil
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM([]byte(b.SSLCACert)) {
+		return nil, fmt.Errorf("error reading CA certificate: no PEM certificates found")
+	}
+	return roots, nil
+}
+
+// VerifyChain verifies the certificate chain a backend presented, leaf first,
+// against the roots and the hostname. An empty hostname skips checking it.
+func VerifyChain(certs []*x509.Certificate, roots *x509.CertPool, hostname string) error {
+	opts := x509.VerifyOptions{
+		DNSName:       hostname,
+		Roots:         roots,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range certs[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(opts)
+	return err
+}
+
+// tlsVersionName returns the name of a crypto/tls version.
+func tlsVersionName(v uint16) string {
+	for name, version := range tlsVersions {
+		if version == v {
+			return "TLS " + name
+		}
+	}
+	return fmt.Sprintf("0x%04x", v)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
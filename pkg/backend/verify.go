@@ -0,0 +1,313 @@
+package backend
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/fastly/cli/pkg/cmd"
+	"github.com/fastly/cli/pkg/compute/manifest"
+	"github.com/fastly/cli/pkg/config"
+	"github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/text"
+	"github.com/fastly/go-fastly/v3/fastly"
+)
+
+// defaultConnectTimeout is the connection timeout used when a backend doesn't
+// have one.
+const defaultConnectTimeout = 10 * time.Second
+
+// Results of a TLS verification check.
+const (
+	checkOK      = "ok"
+	checkWarning = "warning"
+	checkError   = "error"
+)
+
+// VerifyCommand checks the TLS configuration of a backend against the backend
+// itself.
+type VerifyCommand struct {
+	cmd.Base
+	manifest       manifest.Data
+	Input          fastly.GetBackendInput
+	serviceVersion cmd.OptionalServiceVersion
+
+	expiryWarning time.Duration
+}
+
+// NewVerifyCommand returns a usable command registered under the parent.
+func NewVerifyCommand(parent cmd.Registerer, globals *config.Data) *VerifyCommand {
+	var c VerifyCommand
+	c.Globals = globals
+	c.manifest.File.SetOutput(c.Globals.Output)
+	c.manifest.File.Read(manifest.Filename)
+	c.CmdClause = parent.Command("verify", "Verify the TLS configuration of a backend by connecting to it from this machine")
+	c.RegisterServiceIDFlag(&c.manifest.Flag.ServiceID)
+	c.RegisterServiceVersionFlag(cmd.ServiceVersionFlagOpts{
+		Dst: &c.serviceVersion.Value,
+	})
+	c.CmdClause.Flag("name", "Name of backend").Short('n').Required().StringVar(&c.Input.Name)
+	c.CmdClause.Flag("expiry-warning", "Warn when the certificate expires within this long, e.g. 720h").Default("720h").DurationVar(&c.expiryWarning)
+	return &c
+}
+
+// check is the result of verifying one aspect of a backend's TLS.
+type check struct {
+	Name   string `json:"name"`
+	Result string `json:"result"`
+	Detail string `json:"detail"`
+}
+
+// verification is the result of verifying a backend's TLS.
+type verification struct {
+	Backend      string      `json:"backend"`
+	Address      string      `json:"address"`
+	SNIHostname  string      `json:"sni_hostname"`
+	CertHostname string      `json:"cert_hostname"`
+	Version      string      `json:"version"`
+	CipherSuite  string      `json:"cipher_suite"`
+	Certificate  certSummary `json:"certificate"`
+	Checks       []check     `json:"checks"`
+}
+
+// certSummary describes the certificate a backend presented.
+type certSummary struct {
+	Subject   string    `json:"subject"`
+	Issuer    string    `json:"issuer"`
+	DNSNames  []string  `json:"dns_names"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+}
+
+// Exec invokes the application logic for the command.
+func (c *VerifyCommand) Exec(in io.Reader, out io.Writer) error {
+	serviceID, serviceVersion, err := cmd.ServiceDetails(cmd.ServiceDetailsOpts{
+		AllowActiveLocked:  true,
+		Client:             c.Globals.Client,
+		Manifest:           c.manifest,
+		Out:                out,
+		ServiceVersionFlag: c.serviceVersion,
+		VerboseMode:        c.Globals.Flag.Verbose,
+	})
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID":      serviceID,
+			"Service Version": errors.ServiceVersion(serviceVersion),
+		})
+		return err
+	}
+
+	c.Input.ServiceID = serviceID
+	c.Input.ServiceVersion = serviceVersion.Number
+
+	b, err := c.Globals.Client.GetBackend(&c.Input)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID":      serviceID,
+			"Service Version": serviceVersion.Number,
+		})
+		return err
+	}
+
+	if !b.UseSSL {
+		return errors.RemediationError{
+			Inner:       fmt.Errorf("backend %s doesn't use TLS", b.Name),
+			Remediation: "Enable TLS with `fastly backend update --use-ssl`, or use `fastly healthcheck test` to check a plain HTTP backend.",
+		}
+	}
+
+	v, err := verify(b, c.expiryWarning, time.Now())
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Backend": b.Name,
+		})
+		return err
+	}
+
+	var failed int
+	for _, ch := range v.Checks {
+		if ch.Result == checkError {
+			failed++
+		}
+	}
+
+	if text.IsMachineFormat(c.Globals.Flag.Format) {
+		if err := text.Render(out, c.Globals.Flag.Format, v); err != nil {
+			return err
+		}
+	} else {
+		printVerification(out, v)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("backend %s failed %d of %d TLS checks", b.Name, failed, len(v.Checks))
+	}
+	if !text.IsMachineFormat(c.Globals.Flag.Format) {
+		text.Break(out)
+		text.Success(out, "Verified TLS of backend %s", b.Name)
+	}
+	return nil
+}
+
+// verify connects to the backend with its TLS configuration and checks the
+// negotiation and certificate.
+func verify(b *fastly.Backend, expiryWarning time.Duration, now time.Time) (*verification, error) {
+	cfg, err := TLSConfig(b)
+	if err != nil {
+		return nil, err
+	}
+	// The certificate is checked separately, so that every problem is
+	// reported rather than only the first.
+	cfg.VerifyConnection = nil
+
+	roots, err := RootCAs(b)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &verification{
+		Backend:      b.Name,
+		Address:      Address(b),
+		SNIHostname:  cfg.ServerName,
+		CertHostname: CertHostname(b),
+	}
+
+	timeout := time.Duration(b.ConnectTimeout) * time.Millisecond
+	if timeout == 0 {
+		timeout = defaultConnectTimeout
+	}
+
+	state, err := handshake(v.Address, cfg, timeout)
+	if err != nil {
+		detail := err.Error()
+
+		// When the configured versions or ciphers are the problem, a
+		// handshake without them shows what the backend supports.
+		if cfg.MinVersion != 0 || cfg.MaxVersion != 0 || len(cfg.CipherSuites) > 0 {
+			relaxed := cfg.Clone()
+			relaxed.MinVersion, relaxed.MaxVersion, relaxed.CipherSuites = 0, 0, nil
+			if rs, rerr := handshake(v.Address, relaxed, timeout); rerr == nil {
+				detail = fmt.Sprintf("%s; without the backend's TLS version and cipher settings it negotiates %s with %s", detail, tlsVersionName(rs.Version), tls.CipherSuiteName(rs.CipherSuite))
+				state = rs
+			}
+		}
+
+		v.Checks = append(v.Checks, check{Name: "Handshake", Result: checkError, Detail: detail})
+		if state == nil {
+			return v, nil
+		}
+	} else {
+		v.Version = tlsVersionName(state.Version)
+		v.CipherSuite = tls.CipherSuiteName(state.CipherSuite)
+		v.Checks = append(v.Checks, check{
+			Name:   "Handshake",
+			Result: checkOK,
+			Detail: fmt.Sprintf("negotiated %s with %s", v.Version, v.CipherSuite),
+		})
+	}
+
+	if _, unknown := CipherSuites(b); len(unknown) > 0 {
+		v.Checks = append(v.Checks, check{
+			Name:   "Ciphers",
+			Result: checkWarning,
+			Detail: fmt.Sprintf("can't check ciphers %s", strings.Join(unknown, ", ")),
+		})
+	}
+
+	if len(state.PeerCertificates) == 0 {
+		v.Checks = append(v.Checks, check{Name: "Certificate", Result: checkError, Detail: "backend presented no certificate"})
+		return v, nil
+	}
+	leaf := state.PeerCertificates[0]
+	v.Certificate = certSummary{
+		Subject:   leaf.Subject.String(),
+		Issuer:    leaf.Issuer.String(),
+		DNSNames:  leaf.DNSNames,
+		NotBefore: leaf.NotBefore,
+		NotAfter:  leaf.NotAfter,
+	}
+
+	// Fastly doesn't check the certificate unless asked to, so problems
+	// with it are only warnings then.
+	severity := checkError
+	if !b.SSLCheckCert {
+		severity = checkWarning
+		v.Checks = append(v.Checks, check{
+			Name:   "Certificate checking",
+			Result: checkWarning,
+			Detail: "disabled, so Fastly accepts any certificate from the backend",
+		})
+	}
+
+	chain := check{Name: "Certificate chain", Result: checkOK, Detail: "trusted by the system's CAs"}
+	if roots != nil {
+		chain.Detail = "trusted by the backend's CA certificate"
+	}
+	if err := VerifyChain(state.PeerCertificates, roots, ""); err != nil {
+		chain.Result, chain.Detail = severity, err.Error()
+	}
+	v.Checks = append(v.Checks, chain)
+
+	hostname := check{Name: "Certificate hostname", Result: checkOK, Detail: fmt.Sprintf("valid for %s", v.CertHostname)}
+	if err := leaf.VerifyHostname(v.CertHostname); err != nil {
+		hostname.Result, hostname.Detail = severity, err.Error()
+	}
+	v.Checks = append(v.Checks, hostname)
+
+	expiry := check{Name: "Certificate expiry", Result: checkOK, Detail: fmt.Sprintf("expires %s (in %s)", leaf.NotAfter.Format(time.RFC3339), days(leaf.NotAfter.Sub(now)))}
+	switch {
+	case now.Before(leaf.NotBefore):
+		expiry.Result, expiry.Detail = severity, fmt.Sprintf("not valid until %s", leaf.NotBefore.Format(time.RFC3339))
+	case now.After(leaf.NotAfter):
+		expiry.Result, expiry.Detail = severity, fmt.Sprintf("expired %s (%s ago)", leaf.NotAfter.Format(time.RFC3339), days(now.Sub(leaf.NotAfter)))
+	case leaf.NotAfter.Sub(now) < expiryWarning:
+		expiry.Result = checkWarning
+	}
+	v.Checks = append(v.Checks, expiry)
+
+	return v, nil
+}
+
+// handshake connects to the address and completes a TLS handshake.
+func handshake(addr string, cfg *tls.Config, timeout time.Duration) (*tls.ConnectionState, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", addr, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	state := conn.ConnectionState()
+	return &state, nil
+}
+
+// printVerification writes the certificate and checks of a verification.
+func printVerification(out io.Writer, v *verification) {
+	fmt.Fprintf(out, "Backend: %s (%s)\n", v.Backend, v.Address)
+	fmt.Fprintf(out, "SNI hostname: %s\n", v.SNIHostname)
+	fmt.Fprintf(out, "Certificate hostname: %s\n", v.CertHostname)
+	if v.Certificate.Subject != "" || len(v.Certificate.DNSNames) > 0 {
+		fmt.Fprintf(out, "Certificate subject: %s\n", v.Certificate.Subject)
+		fmt.Fprintf(out, "Certificate issuer: %s\n", v.Certificate.Issuer)
+		fmt.Fprintf(out, "Certificate names: %s\n", strings.Join(v.Certificate.DNSNames, ", "))
+	}
+	text.Break(out)
+
+	t := text.NewTable(out)
+	t.AddHeader("CHECK", "RESULT", "DETAIL")
+	for _, ch := range v.Checks {
+		t.AddLine(ch.Name, ch.Result, ch.Detail)
+	}
+	t.Print()
+}
+
+// days formats a duration in whole days.
+func days(d time.Duration) string {
+	n := int(d.Hours() / 24)
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
@@ -194,6 +194,23 @@ func (a AssemblyScript) Build(out io.Writer, verbose bool) error {
 	return nil
 }
 
+// Versions implements the ToolchainVersioner interface and returns the
+// versions of Node.js and the AssemblyScript compiler.
+func (a AssemblyScript) Versions() map[string]string {
+	versions := make(map[string]string)
+
+	if v := commandOutput("node", "--version"); v != "" {
+		versions["node"] = v
+	}
+	if npmdir, err := getNpmBinPath(); err == nil {
+		if v := commandOutput(filepath.Join(npmdir, "asc"), "--version"); v != "" {
+			versions["asc"] = v
+		}
+	}
+
+	return versions
+}
+
 func getNpmBinPath() (string, error) {
 	path, err := exec.Command("npm", "bin").Output()
 	if err != nil {
@@ -1,23 +1,26 @@
 package compute
 
 import (
-	"bufio"
-	"crypto/rand"
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/fastly/cli/pkg/api"
 	"github.com/fastly/cli/pkg/cmd"
 	"github.com/fastly/cli/pkg/compute/manifest"
 	"github.com/fastly/cli/pkg/config"
 	"github.com/fastly/cli/pkg/errors"
-	"github.com/fastly/cli/pkg/filesystem"
 	"github.com/fastly/cli/pkg/text"
 	"github.com/kennygrant/sanitize"
-	"github.com/mholt/archiver/v3"
 )
 
 // IgnoreFilePath is the filepath name of the Fastly ignore file.
@@ -75,6 +78,7 @@ type BuildCommand struct {
 	IncludeSrc  bool
 	Force       bool
 	Timeout     int
+	ListFiles   bool
 }
 
 // NewBuildCommand returns a usable command registered under the parent.
@@ -91,6 +95,7 @@ func NewBuildCommand(parent cmd.Registerer, client api.HTTPClient, globals *conf
 	c.CmdClause.Flag("include-source", "Include source code in built package").BoolVar(&c.IncludeSrc)
 	c.CmdClause.Flag("force", "Skip verification steps and force build").BoolVar(&c.Force)
 	c.CmdClause.Flag("timeout", "Timeout, in seconds, for the build compilation step").IntVar(&c.Timeout)
+	c.CmdClause.Flag("list-files", "List the files the package would contain, and why others are excluded, without building it").BoolVar(&c.ListFiles)
 
 	return &c
 }
@@ -98,9 +103,13 @@ func NewBuildCommand(parent cmd.Registerer, client api.HTTPClient, globals *conf
 // Exec implements the command interface.
 func (c *BuildCommand) Exec(in io.Reader, out io.Writer) (err error) {
 	var progress text.Progress
-	if c.Globals.Verbose() {
+	switch {
+	case c.ListFiles:
+		// Only the list of files is written, so it can be machine readable.
+		progress = text.NewNullProgress()
+	case c.Globals.Verbose():
 		progress = text.NewVerboseProgress(out)
-	} else {
+	default:
 		progress = text.NewQuietProgress(out)
 	}
 
@@ -120,17 +129,11 @@ func (c *BuildCommand) Exec(in io.Reader, out io.Writer) (err error) {
 		return fmt.Errorf("error reading package manifest: %w", err)
 	}
 
-	// Language from flag takes priority, otherwise infer from manifest and
-	// error if neither are provided. Sanitize by trim and lowercase.
-	var lang string
-	if c.Lang != "" {
-		lang = c.Lang
-	} else if m.Language != "" {
-		lang = m.Language
-	} else {
-		return fmt.Errorf("language cannot be empty, please provide a language")
+	language, err := c.Language(m)
+	if err != nil {
+		return err
 	}
-	lang = strings.ToLower(strings.TrimSpace(lang))
+	lang := language.Name
 
 	// Name from flag takes priority, otherwise infer from manifest
 	// error if neither are provided. Sanitize value to ensure it is a safe
@@ -145,24 +148,14 @@ func (c *BuildCommand) Exec(in io.Reader, out io.Writer) (err error) {
 	}
 	name = sanitize.BaseName(name)
 
-	var language *Language
-	switch lang {
-	case "assemblyscript":
-		language = NewLanguage(&LanguageOptions{
-			Name:            "assemblyscript",
-			SourceDirectory: "assembly",
-			IncludeFiles:    []string{"package.json"},
-			Toolchain:       NewAssemblyScript(c.Timeout),
-		})
-	case "rust":
-		language = NewLanguage(&LanguageOptions{
-			Name:            "rust",
-			SourceDirectory: "src",
-			IncludeFiles:    []string{"Cargo.toml"},
-			Toolchain:       NewRust(c.client, c.Globals, c.Timeout),
-		})
-	default:
-		return fmt.Errorf("unsupported language %s", lang)
+	ignore, err := ReadIgnoreFile(IgnoreFilePath)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+
+	if c.ListFiles {
+		return c.listFiles(language, ignore, out)
 	}
 
 	if !c.Force {
@@ -190,39 +183,27 @@ func (c *BuildCommand) Exec(in io.Reader, out io.Writer) (err error) {
 
 	dest := filepath.Join("pkg", fmt.Sprintf("%s.tar.gz", name))
 
-	files := []string{
-		manifest.Filename,
-	}
-	files = append(files, language.IncludeFiles...)
-
-	ignoreFiles, err := GetIgnoredFiles(IgnoreFilePath)
+	pkgFiles, err := PackageFiles(language, c.IncludeSrc, ignore)
 	if err != nil {
 		c.Globals.ErrLog.Add(err)
 		return err
 	}
+	var files []string
+	for _, f := range pkgFiles {
+		if f.Included {
+			files = append(files, f.Path)
+		}
+	}
 
-	binFiles, err := GetNonIgnoredFiles("bin", ignoreFiles)
+	info, err := NewBuildInfo(language, files)
 	if err != nil {
 		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
-			"Ignore files": ignoreFiles,
+			"Files": files,
 		})
 		return err
 	}
-	files = append(files, binFiles...)
 
-	if c.IncludeSrc {
-		srcFiles, err := GetNonIgnoredFiles(language.SourceDirectory, ignoreFiles)
-		if err != nil {
-			c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
-				"Source directory": language.SourceDirectory,
-				"Ignore files":     ignoreFiles,
-			})
-			return err
-		}
-		files = append(files, srcFiles...)
-	}
-
-	err = CreatePackageArchive(files, dest)
+	err = CreatePackageArchive(files, dest, info)
 	if err != nil {
 		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
 			"Files":       files,
@@ -237,55 +218,183 @@ func (c *BuildCommand) Exec(in io.Reader, out io.Writer) (err error) {
 	return nil
 }
 
+// Language returns the Language the package is built with. The language from
+// the flag takes priority, otherwise it's inferred from the manifest.
+func (c *BuildCommand) Language(m manifest.File) (*Language, error) {
+	var lang string
+	if c.Lang != "" {
+		lang = c.Lang
+	} else if m.Language != "" {
+		lang = m.Language
+	} else {
+		return nil, fmt.Errorf("language cannot be empty, please provide a language")
+	}
+	// Sanitize by trim and lowercase.
+	lang = strings.ToLower(strings.TrimSpace(lang))
+
+	language, ok := NewToolchainLanguage(lang, ToolchainOptions{
+		Client:   c.client,
+		Globals:  c.Globals,
+		Manifest: m,
+		Timeout:  c.Timeout,
+	})
+	if !ok {
+		return nil, errors.RemediationError{
+			Inner:       fmt.Errorf("unsupported language %s", lang),
+			Remediation: fmt.Sprintf("Set the language to one of: %s", strings.Join(ToolchainNames(), ", ")),
+		}
+	}
+	return language, nil
+}
+
 // CreatePackageArchive packages build artifacts as a Fastly package, which
-// must be a GZipped Tar archive such as: package-name.tar.gz.
+// must be a GZipped Tar archive such as: package-name.tar.gz. The files are
+// written under a top-level directory named after the package, along with the
+// build info if there is any.
 //
-// Due to a behavior of archiver.Archive() which recursively writes all files in
-// a provided directory to the archive we first copy our input files to a
-// temporary directory to ensure only the specified files are included and not
-// any in the directory which may be ignored.
-func CreatePackageArchive(files []string, destination string) error {
-	// Create temporary directory to copy files into.
-	p := make([]byte, 8)
-	n, err := rand.Read(p)
-	if err != nil {
-		return fmt.Errorf("error creating temporary directory: %w", err)
+// The archive is reproducible, so that identical builds have an identical hash
+// sum: entries are sorted, their modification times, ownership and
+// permissions are normalised, and the gzip header has no name or time.
+func CreatePackageArchive(files []string, destination string, info *BuildInfo) error {
+	sources := make(map[string]string, len(files))
+	for _, f := range files {
+		sources[f] = f
 	}
+	return createPackageArchive(sources, destination, info)
+}
 
-	tmpDir := filepath.Join(
-		os.TempDir(),
-		fmt.Sprintf("fastly-build-%x", p[:n]),
-	)
+// createPackageArchive is CreatePackageArchive for files which are read from
+// elsewhere than their path in the package: sources maps each path in the
+// package to the file it's read from.
+func createPackageArchive(sources map[string]string, destination string, info *BuildInfo) (err error) {
+	root := FileNameWithoutExtension(destination)
+
+	// Archive entry names, which always use forward slashes, to the files
+	// they're read from.
+	entries := make(map[string]string, len(sources))
+	for name, src := range sources {
+		entries[path.Join(root, filepath.ToSlash(filepath.Clean(name)))] = src
+	}
 
-	if err := os.MkdirAll(tmpDir, 0700); err != nil {
-		return fmt.Errorf("error creating temporary directory: %w", err)
+	var buildInfo []byte
+	infoName := path.Join(root, BuildInfoFilename)
+	if info != nil {
+		buildInfo, err = json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error encoding build info: %w", err)
+		}
+		entries[infoName] = BuildInfoFilename
+	}
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
 	}
-	defer os.RemoveAll(tmpDir)
+	sort.Strings(names)
 
-	// Create implicit top-level directory within temp which will become the
-	// root of the archive. This replaces the `tar.ImplicitTopLevelFolder`
-	// behavior.
-	dir := filepath.Join(tmpDir, FileNameWithoutExtension(destination))
-	if err := os.Mkdir(dir, 0700); err != nil {
-		return fmt.Errorf("error creating temporary directory: %w", err)
+	if err := os.MkdirAll(filepath.Dir(destination), 0750); err != nil {
+		return fmt.Errorf("error creating package directory: %w", err)
 	}
 
-	for _, src := range files {
-		dst := filepath.Join(dir, src)
-		if err = filesystem.CopyFile(src, dst); err != nil {
-			return fmt.Errorf("error copying file: %w", err)
+	// gosec flagged this:
+	// G304 (CWE-22): Potential file inclusion via variable
+	// Disabling as we trust the source of the filepath variable.
+	/* #nosec */
+	f, err := os.Create(destination)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		cerr := f.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	dirs := make(map[string]bool)
+	for _, name := range names {
+		if err := writeArchiveDirs(tw, path.Dir(name), dirs); err != nil {
+			return err
+		}
+
+		if info != nil && name == infoName {
+			err = writeArchiveEntry(tw, name, int64(len(buildInfo)), bytes.NewReader(buildInfo))
+		} else {
+			err = writeArchiveFile(tw, name, entries[name])
+		}
+		if err != nil {
+			return err
 		}
 	}
 
-	tar := archiver.NewTarGz()
-	tar.OverwriteExisting = true //
-	tar.MkdirAll = true          // make destination directory if it doesn't exist
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// archiveModTime is the modification time of every entry of a package archive.
+var archiveModTime = time.Unix(0, 0)
 
-	if err = tar.Archive([]string{dir}, destination); err != nil {
+// writeArchiveDirs writes the entries of a directory and its parents which
+// haven't been written yet.
+func writeArchiveDirs(tw *tar.Writer, dir string, written map[string]bool) error {
+	if dir == "." || dir == "/" || written[dir] {
+		return nil
+	}
+	if err := writeArchiveDirs(tw, path.Dir(dir), written); err != nil {
 		return err
 	}
+	written[dir] = true
+	return tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     dir + "/",
+		Mode:     0755,
+		ModTime:  archiveModTime,
+	})
+}
+
+// writeArchiveFile writes the entry of a file.
+func writeArchiveFile(tw *tar.Writer, name, src string) (err error) {
+	// gosec flagged this:
+	// G304 (CWE-22): Potential file inclusion via variable
+	// Disabling as we trust the source of the filepath variable.
+	/* #nosec */
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+	defer f.Close() // #nosec G307
 
-	return nil
+	fi, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+	if !fi.Mode().IsRegular() {
+		return fmt.Errorf("error reading file: %s isn't a regular file", src)
+	}
+
+	return writeArchiveEntry(tw, name, fi.Size(), f)
+}
+
+// writeArchiveEntry writes the entry of a regular file with normalised
+// metadata.
+func writeArchiveEntry(tw *tar.Writer, name string, size int64, r io.Reader) error {
+	err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0644,
+		ModTime:  archiveModTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, r)
+	return err
 }
 
 // FileNameWithoutExtension returns a filename with its extension stripped.
@@ -298,68 +407,149 @@ func FileNameWithoutExtension(filename string) string {
 	return base
 }
 
-// GetIgnoredFiles reads the .fastlyignore file line-by-line and expands the
-// glob pattern into a map containing all files it matches. If no ignore file
-// is present it returns an empty map.
-func GetIgnoredFiles(filePath string) (files map[string]bool, err error) {
-	files = make(map[string]bool)
+// Reasons a file is included in, or excluded from, a package.
+const (
+	reasonManifest   = "package manifest"
+	reasonBinary     = "Wasm binary"
+	reasonLanguage   = "%s package file"
+	reasonBuildOut   = "build output"
+	reasonSource     = "source, --include-source is set"
+	reasonNoSource   = "source, --include-source isn't set"
+	reasonBuildInfo  = "build info, written by the build"
+	reasonIgnoredFmt = "ignored by %s"
+)
 
-	if !filesystem.FileExists(filePath) {
-		return files, nil
-	}
+// PackageFile is a file considered for a package, and whether and why it's
+// included.
+type PackageFile struct {
+	Path     string `json:"path"`
+	Included bool   `json:"included"`
+	Reason   string `json:"reason"`
+}
 
-	// gosec flagged this:
-	// G304 (CWE-22): Potential file inclusion via variable
-	// Disabling as we trust the source of the filepath variable as it comes
-	// from the IgnoreFilePath constant.
-	/* #nosec */
-	file, err := os.Open(filePath)
-	if err != nil {
-		return files, err
-	}
-	defer func() {
-		cerr := file.Close()
-		if err == nil {
-			err = cerr
+// PackageFiles returns the files considered for a package of the language:
+// the manifest, the language's package files, the build output in bin and,
+// optionally, the source. The manifest and Wasm binary are always included,
+// and any other files matching the ignore patterns are excluded. Ignored
+// directories are returned with a trailing slash rather than their files.
+func PackageFiles(language *Language, includeSrc bool, ignore *IgnoreMatcher) ([]PackageFile, error) {
+	var files []PackageFile
+	seen := make(map[string]bool)
+	add := func(f PackageFile) {
+		if !seen[f.Path] {
+			seen[f.Path] = true
+			files = append(files, f)
 		}
-	}()
+	}
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		glob := strings.TrimSpace(scanner.Text())
-		globFiles, err := filepath.Glob(glob)
-		if err != nil {
-			return files, fmt.Errorf("parsing glob %s: %w", glob, err)
-		}
-		for _, f := range globFiles {
-			files[f] = true
+	add(PackageFile{Path: manifest.Filename, Included: true, Reason: reasonManifest})
+	add(PackageFile{Path: filepath.Join("bin", "main.wasm"), Included: true, Reason: reasonBinary})
+
+	for _, f := range language.IncludeFiles {
+		if ignored, reason := ignore.Match(f, false); ignored {
+			add(PackageFile{Path: f, Reason: fmt.Sprintf(reasonIgnoredFmt, reason)})
+			continue
 		}
+		add(PackageFile{Path: f, Included: true, Reason: fmt.Sprintf(reasonLanguage, language.Name)})
 	}
 
-	if err := scanner.Err(); err != nil {
-		return files, fmt.Errorf("reading %s file: %w", filePath, err)
+	walk := func(base, reason string, included bool) error {
+		return walkPackageDir(base, ignore, func(path string, ignoredBy string) {
+			switch {
+			case ignoredBy != "":
+				add(PackageFile{Path: path, Reason: fmt.Sprintf(reasonIgnoredFmt, ignoredBy)})
+			default:
+				add(PackageFile{Path: path, Included: included, Reason: reason})
+			}
+		})
+	}
+
+	// The Wasm binary was added first, so it's included even if it's ignored.
+	if err := walk("bin", reasonBuildOut, true); err != nil {
+		return nil, err
+	}
+	if language.SourceDirectory != "" {
+		if includeSrc {
+			if err := walk(language.SourceDirectory, reasonSource, true); err != nil {
+				return nil, err
+			}
+		} else if err := walk(language.SourceDirectory, reasonNoSource, false); err != nil {
+			return nil, err
+		}
 	}
 
 	return files, nil
 }
 
-// GetNonIgnoredFiles walks a filepath and returns all files don't exist in the
-// provided ignore files map.
-func GetNonIgnoredFiles(base string, ignoredFiles map[string]bool) ([]string, error) {
-	var files []string
+// walkPackageDir walks a directory, if it exists, calling fn with each file
+// and the pattern ignoring it, if any. Ignored directories aren't walked, and
+// fn is called with their path and a trailing separator instead.
+func walkPackageDir(base string, ignore *IgnoreMatcher, fn func(path, ignoredBy string)) error {
 	err := filepath.Walk(base, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		ignored, reason := ignore.Match(path, info.IsDir())
 		if info.IsDir() {
+			if ignored && path != base {
+				fn(path+string(filepath.Separator), reason)
+				return filepath.SkipDir
+			}
 			return nil
 		}
-		if ignoredFiles[path] {
-			return nil
+		if !ignored {
+			reason = ""
 		}
-		files = append(files, path)
+		fn(path, reason)
 		return nil
 	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
 
+// GetNonIgnoredFiles walks a filepath and returns all files which aren't
+// ignored.
+func GetNonIgnoredFiles(base string, ignore *IgnoreMatcher) ([]string, error) {
+	var files []string
+	err := walkPackageDir(base, ignore, func(path, ignoredBy string) {
+		if ignoredBy == "" {
+			files = append(files, path)
+		}
+	})
 	return files, err
 }
+
+// listFiles writes the files a package would contain, and those excluded.
+func (c *BuildCommand) listFiles(language *Language, ignore *IgnoreMatcher, out io.Writer) error {
+	files, err := PackageFiles(language, c.IncludeSrc, ignore)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+	files = append(files, PackageFile{Path: BuildInfoFilename, Included: true, Reason: reasonBuildInfo})
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].Included != files[j].Included {
+			return files[i].Included
+		}
+		return files[i].Path < files[j].Path
+	})
+
+	if text.IsMachineFormat(c.Globals.Flag.Format) {
+		return text.Render(out, c.Globals.Flag.Format, files)
+	}
+
+	t := text.NewTable(out)
+	t.AddHeader("STATUS", "FILE", "REASON")
+	for _, f := range files {
+		status := "excluded"
+		if f.Included {
+			status = "included"
+		}
+		t.AddLine(status, filepath.ToSlash(f.Path), f.Reason)
+	}
+	t.Print()
+	text.Info(out, "The files in bin are listed as they are now, building the package may change them.")
+	return nil
+}
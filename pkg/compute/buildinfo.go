@@ -0,0 +1,118 @@
+package compute
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// BuildInfoFilename is the name of the file, at the root of a package
+// archive, which describes how the package was built.
+const BuildInfoFilename = "build-info.json"
+
+// BuildInfo describes how a package was built. It only records values which
+// are the same for identical builds, such as versions and hashes rather than
+// times, so that the package archive is reproducible. The hash of the archive
+// is compared to skip uploading unchanged packages, so the git SHA is the last
+// commit which changed the packaged files rather than the commit checked out,
+// and the CLI version isn't recorded.
+type BuildInfo struct {
+	Language  string            `json:"language"`
+	Toolchain map[string]string `json:"toolchain,omitempty"`
+	GitSHA    string            `json:"git_sha,omitempty"`
+	Files     map[string]string `json:"files"`
+}
+
+// ToolchainVersioner is implemented by toolchains which can report the
+// versions of the tools a package is built with.
+type ToolchainVersioner interface {
+	Versions() map[string]string
+}
+
+// NewBuildInfo returns the build info of a package of the language made up of
+// the files, which are hashed.
+func NewBuildInfo(language *Language, files []string) (*BuildInfo, error) {
+	info := &BuildInfo{
+		Language: language.Name,
+		GitSHA:   gitSHA(files),
+		Files:    make(map[string]string, len(files)),
+	}
+
+	if v, ok := language.Toolchain.(ToolchainVersioner); ok {
+		if versions := v.Versions(); len(versions) > 0 {
+			info.Toolchain = versions
+		}
+	}
+
+	for _, f := range files {
+		sum, err := fileSHA256(f)
+		if err != nil {
+			return nil, fmt.Errorf("error hashing %s: %w", f, err)
+		}
+		info.Files[filepath.ToSlash(filepath.Clean(f))] = sum
+	}
+
+	return info, nil
+}
+
+// ReadBuildInfo decodes the build info of a package.
+func ReadBuildInfo(r io.Reader) (*BuildInfo, error) {
+	var info BuildInfo
+	if err := json.NewDecoder(r).Decode(&info); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", BuildInfoFilename, err)
+	}
+	return &info, nil
+}
+
+// fileSHA256 returns the hex encoded SHA-256 hash of a file.
+func fileSHA256(path string) (sum string, err error) {
+	// gosec flagged this:
+	// G304 (CWE-22): Potential file inclusion via variable
+	// Disabling as we trust the source of the filepath variable.
+	/* #nosec */
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		cerr := f.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// gitSHA returns the last commit which changed any of the files, or an empty
+// string if they aren't in a git repository.
+func gitSHA(files []string) string {
+	if len(files) == 0 {
+		return ""
+	}
+	args := append([]string{"log", "-1", "--format=%H", "--"}, files...)
+	return commandOutput("git", args...)
+}
+
+// commandOutput returns the first line a command writes to stdout, or an empty
+// string if the command fails.
+func commandOutput(name string, args ...string) string {
+	// gosec flagged this:
+	// G204 (CWE-78): Subprocess launched with variable
+	// Disabling as the variables come from trusted sources.
+	/* #nosec */
+	bs, err := exec.Command(name, args...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.SplitN(string(bs), "\n", 2)[0])
+}
@@ -1,16 +1,22 @@
 package compute_test
 
 import (
+	"archive/tar"
 	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/fastly/cli/pkg/api"
 	"github.com/fastly/cli/pkg/app"
+	"github.com/fastly/cli/pkg/compute"
 	"github.com/fastly/cli/pkg/compute/manifest"
 	"github.com/fastly/cli/pkg/config"
 	"github.com/fastly/cli/pkg/testutil"
+	"github.com/mholt/archiver/v3"
 )
 
 // TestBuildRust validates that the rust ecosystem is in place and accurate.
@@ -436,3 +442,298 @@ func TestBuildAssemblyScript(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildOther(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("build commands are run with sh")
+	}
+	args := testutil.Args
+
+	for _, testcase := range []struct {
+		name                 string
+		args                 []string
+		fastlyManifest       string
+		wantError            string
+		wantRemediationError string
+		wantOutputContains   string
+		wantArchiveFile      string
+	}{
+		{
+			name: "no build command",
+			args: args("compute build"),
+			fastlyManifest: `
+			manifest_version = 1
+			name = "test"
+			language = "other"`,
+			wantError:            "build command not found in fastly.toml",
+			wantRemediationError: "[scripts]",
+		},
+		{
+			name: "no build command with force",
+			args: args("compute build --force"),
+			fastlyManifest: `
+			manifest_version = 1
+			name = "test"
+			language = "other"`,
+			wantError: "build command not found in fastly.toml",
+		},
+		{
+			name: "build command fails",
+			args: args("compute build"),
+			fastlyManifest: `
+			manifest_version = 1
+			name = "test"
+			language = "other"
+			[scripts]
+			build = "echo compile error >&2; exit 1"`,
+			wantError: "compile error",
+		},
+		{
+			name: "build command writes no binary",
+			args: args("compute build"),
+			fastlyManifest: `
+			manifest_version = 1
+			name = "test"
+			language = "other"
+			[scripts]
+			build = "true"`,
+			wantError:            "build command didn't create bin/main.wasm",
+			wantRemediationError: "[scripts] section of fastly.toml",
+		},
+		{
+			name: "success",
+			args: args("compute build"),
+			fastlyManifest: `
+			manifest_version = 1
+			name = "test"
+			language = "other"
+			[scripts]
+			build = "printf wasm > bin/main.wasm"`,
+			wantOutputContains: "Built other package test",
+			wantArchiveFile:    "test/bin/main.wasm",
+		},
+		{
+			name: "language flag",
+			args: args("compute build --language other"),
+			fastlyManifest: `
+			manifest_version = 1
+			name = "test"
+			language = "rust"
+			[scripts]
+			build = "printf wasm > bin/main.wasm"`,
+			wantOutputContains: "Built other package test",
+			wantArchiveFile:    "test/fastly.toml",
+		},
+		{
+			name: "unknown language",
+			args: args("compute build"),
+			fastlyManifest: `
+			manifest_version = 1
+			name = "test"
+			language = "cobol"`,
+			wantError:            "unsupported language cobol",
+			wantRemediationError: "assemblyscript, other, rust",
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			// We're going to chdir to a build environment,
+			// so save the PWD to return to, afterwards.
+			pwd, err := os.Getwd()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Create test environment
+			rootdir := testutil.NewEnv(testutil.EnvOpts{
+				T: t,
+				Write: []testutil.FileIO{
+					{Src: testcase.fastlyManifest, Dst: manifest.Filename},
+				},
+			})
+			defer os.RemoveAll(rootdir)
+
+			// Before running the test, chdir into the build environment.
+			// When we're done, chdir back to our original location.
+			// This is so we can reliably copy the testdata/ fixtures.
+			if err := os.Chdir(rootdir); err != nil {
+				t.Fatal(err)
+			}
+			defer os.Chdir(pwd)
+
+			var stdout bytes.Buffer
+			opts := testutil.NewRunOpts(testcase.args, &stdout)
+			err = app.Run(opts)
+			testutil.AssertErrorContains(t, err, testcase.wantError)
+			testutil.AssertRemediationErrorContains(t, err, testcase.wantRemediationError)
+			if testcase.wantOutputContains != "" {
+				testutil.AssertStringContains(t, stdout.String(), testcase.wantOutputContains)
+			}
+			if testcase.wantArchiveFile != "" {
+				var found bool
+				err := archiver.Walk(filepath.Join(rootdir, "pkg", "test.tar.gz"), func(f archiver.File) error {
+					if h, ok := f.Header.(*tar.Header); ok && h.Name == testcase.wantArchiveFile {
+						found = true
+					}
+					return nil
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !found {
+					t.Fatalf("want %s in package archive", testcase.wantArchiveFile)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildListFiles(t *testing.T) {
+	args := testutil.Args
+
+	fastlyManifest := `
+	manifest_version = 1
+	name = "test"
+	language = "other"
+	[scripts]
+	build = "exit 1"`
+
+	for _, testcase := range []struct {
+		name            string
+		args            []string
+		fastlyManifest  string
+		fastlyignore    string
+		wantOutput      []string
+		wantNotOutput   []string
+		wantIncluded    []string
+		wantNotIncluded []string
+	}{
+		{
+			name: "without source",
+			args: args("compute build --list-files"),
+			wantOutput: []string{
+				"included fastly.toml package manifest",
+				"included bin/main.wasm Wasm binary",
+				"included bin/extra.txt build output",
+				"included build-info.json build info, written by the build",
+				"excluded src/main.go source, --include-source isn't set",
+			},
+		},
+		{
+			name:         "ignored files",
+			args:         args("compute build --list-files --include-source"),
+			fastlyignore: "# generated code\ngen/\n*.txt\n!keep.txt\nbin/*.wasm",
+			wantOutput: []string{
+				"included bin/main.wasm Wasm binary",
+				"included src/keep.txt source, --include-source is set",
+				"included src/main.go source, --include-source is set",
+				"excluded bin/extra.txt ignored by .fastlyignore:3: *.txt",
+				"excluded src/gen/ ignored by .fastlyignore:2: gen/",
+			},
+			wantNotOutput: []string{"src/gen/code.go"},
+		},
+		{
+			name: "source directory",
+			args: args("compute build --list-files --include-source"),
+			fastlyManifest: fastlyManifest + `
+	source_directory = "src/gen"`,
+			wantOutput:    []string{"included src/gen/code.go source, --include-source is set"},
+			wantNotOutput: []string{"src/main.go"},
+		},
+		{
+			name:            "machine format",
+			args:            args("compute build --list-files --include-source --output json"),
+			fastlyignore:    "src/gen/",
+			wantIncluded:    []string{"bin/main.wasm", "src/main.go", "src/keep.txt"},
+			wantNotIncluded: []string{"src/gen/"},
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			// We're going to chdir to a build environment,
+			// so save the PWD to return to, afterwards.
+			pwd, err := os.Getwd()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if testcase.fastlyManifest == "" {
+				testcase.fastlyManifest = fastlyManifest
+			}
+			rootdir := testutil.NewEnv(testutil.EnvOpts{
+				T: t,
+				Write: []testutil.FileIO{
+					{Src: testcase.fastlyManifest, Dst: manifest.Filename},
+					{Src: testcase.fastlyignore, Dst: compute.IgnoreFilePath},
+				},
+			})
+			defer os.RemoveAll(rootdir)
+
+			for path, content := range map[string]string{
+				"bin/main.wasm":   "wasm",
+				"bin/extra.txt":   "extra",
+				"src/main.go":     "package main",
+				"src/keep.txt":    "keep",
+				"src/gen/code.go": "package gen",
+			} {
+				path = filepath.Join(rootdir, filepath.FromSlash(path))
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := os.Chdir(rootdir); err != nil {
+				t.Fatal(err)
+			}
+			defer os.Chdir(pwd)
+
+			var stdout bytes.Buffer
+			opts := testutil.NewRunOpts(testcase.args, &stdout)
+			err = app.Run(opts)
+			testutil.AssertNoError(t, err)
+
+			// Nothing is built, so the failing build command never runs.
+			if _, err := os.Stat(filepath.Join(rootdir, "pkg")); !os.IsNotExist(err) {
+				t.Fatalf("want no package to be built, got %v", err)
+			}
+
+			// The table's column widths depend on the files, so compare the
+			// lines with their whitespace collapsed.
+			var lines []string
+			for _, line := range strings.Split(stdout.String(), "\n") {
+				lines = append(lines, strings.Join(strings.Fields(line), " "))
+			}
+			output := strings.Join(lines, "\n")
+			for _, s := range testcase.wantOutput {
+				testutil.AssertStringContains(t, output, s)
+			}
+			for _, s := range testcase.wantNotOutput {
+				if strings.Contains(stdout.String(), s) {
+					t.Errorf("want %q not in output:\n%s", s, stdout.String())
+				}
+			}
+
+			if testcase.wantIncluded != nil || testcase.wantNotIncluded != nil {
+				var files []compute.PackageFile
+				if err := json.Unmarshal(stdout.Bytes(), &files); err != nil {
+					t.Fatal(err)
+				}
+				included := make(map[string]bool)
+				for _, f := range files {
+					included[filepath.ToSlash(f.Path)] = f.Included
+				}
+				for _, path := range testcase.wantIncluded {
+					if !included[path] {
+						t.Errorf("want %s included", path)
+					}
+				}
+				for _, path := range testcase.wantNotIncluded {
+					if inc, ok := included[path]; !ok || inc {
+						t.Errorf("want %s excluded", path)
+					}
+				}
+			}
+		})
+	}
+}
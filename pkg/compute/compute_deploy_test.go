@@ -12,6 +12,7 @@ import (
 	"github.com/fastly/cli/pkg/compute/manifest"
 	"github.com/fastly/cli/pkg/mock"
 	"github.com/fastly/cli/pkg/testutil"
+	"github.com/fastly/cli/pkg/undo"
 	"github.com/fastly/go-fastly/v3/fastly"
 )
 
@@ -25,6 +26,8 @@ func TestDeploy(t *testing.T) {
 		wantError        string
 		wantOutput       []string
 		manifestIncludes string
+		journal          string
+		wantJournal      bool
 	}{
 		{
 			name:      "no token",
@@ -378,6 +381,45 @@ func TestDeploy(t *testing.T) {
 				"Deployed package (service 123, version 4)",
 			},
 		},
+		{
+			name:        "interrupted deploy",
+			args:        args("compute deploy --token 123"),
+			manifest:    "name = \"package\"\n",
+			journal:     `{"command": "compute deploy", "actions": [{"name": "delete-service", "params": {"service_id": "12345"}}]}`,
+			wantError:   "a previous deploy was interrupted before its changes could be undone",
+			wantJournal: true,
+		},
+		{
+			name: "rollback incomplete",
+			args: args("compute deploy --token 123 --rollback-incomplete"),
+			api: mock.API{
+				DeleteBackendFn: deleteBackendOK,
+				DeleteServiceFn: deleteServiceOK,
+			},
+			manifest: "name = \"package\"\n",
+			journal:  `{"command": "compute deploy", "actions": [{"name": "delete-service", "params": {"service_id": "12345"}}, {"name": "delete-backend", "params": {"service_id": "12345", "service_version": "1", "name": "127.0.0.1"}}]}`,
+			wantOutput: []string{
+				"Rolling back the deploy",
+				"Rolled back 2 change(s)",
+			},
+		},
+		{
+			name: "rollback incomplete error",
+			args: args("compute deploy --token 123 --rollback-incomplete"),
+			api: mock.API{
+				DeleteServiceFn: deleteServiceError,
+			},
+			manifest:    "name = \"package\"\n",
+			journal:     `{"command": "compute deploy", "actions": [{"name": "delete-service", "params": {"service_id": "12345"}}]}`,
+			wantError:   "1 undo action(s) failed",
+			wantJournal: true,
+		},
+		{
+			name:       "rollback incomplete without journal",
+			args:       args("compute deploy --token 123 --rollback-incomplete"),
+			manifest:   "name = \"package\"\n",
+			wantOutput: []string{"There is no incomplete deploy to roll back"},
+		},
 	} {
 		t.Run(testcase.name, func(t *testing.T) {
 			// We're going to chdir to a deploy environment,
@@ -410,6 +452,23 @@ func TestDeploy(t *testing.T) {
 			}
 			defer os.Chdir(pwd)
 
+			// Keep the undo journals inside the test environment.
+			defer func(dir string) { undo.JournalDir = dir }(undo.JournalDir)
+			undo.JournalDir = filepath.Join(rootdir, "undo")
+			wd, err := os.Getwd()
+			if err != nil {
+				t.Fatal(err)
+			}
+			journalPath := undo.JournalPath(wd)
+			if testcase.journal != "" {
+				if err := os.MkdirAll(undo.JournalDir, 0700); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(journalPath, []byte(testcase.journal), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
 			var stdout bytes.Buffer
 			opts := testutil.NewRunOpts(testcase.args, &stdout)
 			opts.APIClient = mock.APIClient(testcase.api)
@@ -434,6 +493,12 @@ func TestDeploy(t *testing.T) {
 				}
 				testutil.AssertStringContains(t, string(content), testcase.manifestIncludes)
 			}
+
+			// The journal should only remain if there are changes left to undo.
+			_, err = os.Stat(journalPath)
+			if exists := err == nil; exists != testcase.wantJournal {
+				t.Fatalf("want journal %t, have %t", testcase.wantJournal, exists)
+			}
 		})
 	}
 }
@@ -454,6 +519,10 @@ func deleteServiceOK(i *fastly.DeleteServiceInput) error {
 	return nil
 }
 
+func deleteServiceError(i *fastly.DeleteServiceInput) error {
+	return testutil.Err
+}
+
 func createDomainError(i *fastly.CreateDomainInput) (*fastly.Domain, error) {
 	return nil, testutil.Err
 }
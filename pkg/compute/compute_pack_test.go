@@ -1,14 +1,18 @@
 package compute_test
 
 import (
+	"archive/tar"
 	"bytes"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/fastly/cli/pkg/app"
+	"github.com/fastly/cli/pkg/compute"
 	"github.com/fastly/cli/pkg/compute/manifest"
 	"github.com/fastly/cli/pkg/testutil"
+	"github.com/mholt/archiver/v3"
 )
 
 func TestPack(t *testing.T) {
@@ -17,9 +21,12 @@ func TestPack(t *testing.T) {
 		name          string
 		args          []string
 		manifest      string
+		fastlyignore  string
+		binFiles      []string
 		wantError     string
 		wantOutput    []string
 		expectedFiles [][]string
+		wantArchive   []string
 	}{
 		// The following test validates that the expected directory struture was
 		// created successfully.
@@ -38,6 +45,40 @@ func TestPack(t *testing.T) {
 				{"pkg", "precompiled", "fastly.toml"},
 				{"pkg", "precompiled.tar.gz"},
 			},
+			wantArchive: []string{
+				"precompiled/",
+				"precompiled/bin/",
+				"precompiled/bin/main.wasm",
+				"precompiled/fastly.toml",
+			},
+		},
+		// The following tests validate that the rest of the build output is
+		// only packaged when asked for, unless it's ignored.
+		{
+			name:     "build output",
+			args:     args("compute pack --path ./main.wasm"),
+			manifest: `name = "precompiled"`,
+			binFiles: []string{"main.wasm", "data.bin"},
+			wantArchive: []string{
+				"precompiled/",
+				"precompiled/bin/",
+				"precompiled/bin/main.wasm",
+				"precompiled/fastly.toml",
+			},
+		},
+		{
+			name:         "fastlyignore",
+			args:         args("compute pack --path ./main.wasm --include-build-output"),
+			manifest:     `name = "precompiled"`,
+			fastlyignore: "*.map\ndebug/\n",
+			binFiles:     []string{"main.wasm", "data.bin", "main.wasm.map", filepath.Join("debug", "main.wasm")},
+			wantArchive: []string{
+				"precompiled/",
+				"precompiled/bin/",
+				"precompiled/bin/data.bin",
+				"precompiled/bin/main.wasm",
+				"precompiled/fastly.toml",
+			},
 		},
 		// The following tests validate that a valid path flag value should be
 		// provided.
@@ -63,13 +104,18 @@ func TestPack(t *testing.T) {
 			}
 
 			// Create test environment
+			copies := []testutil.FileIO{
+				{Src: filepath.Join("testdata", "pack", "main.wasm"), Dst: "main.wasm"},
+			}
+			for _, f := range testcase.binFiles {
+				copies = append(copies, testutil.FileIO{Src: filepath.Join("testdata", "pack", "main.wasm"), Dst: filepath.Join("bin", f)})
+			}
 			rootdir := testutil.NewEnv(testutil.EnvOpts{
-				T: t,
-				Copy: []testutil.FileIO{
-					{Src: filepath.Join("testdata", "pack", "main.wasm"), Dst: "main.wasm"},
-				},
+				T:    t,
+				Copy: copies,
 				Write: []testutil.FileIO{
 					{Src: testcase.manifest, Dst: manifest.Filename},
+					{Src: testcase.fastlyignore, Dst: compute.IgnoreFilePath},
 				},
 			})
 			defer os.RemoveAll(rootdir)
@@ -96,6 +142,21 @@ func TestPack(t *testing.T) {
 					t.Fatalf("the specified file is not in the expected location: %v", err)
 				}
 			}
+
+			if testcase.wantArchive != nil {
+				var names []string
+				if err := archiver.Walk(filepath.Join(rootdir, "pkg", "precompiled.tar.gz"), func(f archiver.File) error {
+					h := f.Header.(*tar.Header)
+					if !h.ModTime.Equal(time.Unix(0, 0)) || h.Uid != 0 || h.Gid != 0 {
+						t.Errorf("%s: want normalised metadata, have mtime %s uid %d gid %d", h.Name, h.ModTime, h.Uid, h.Gid)
+					}
+					names = append(names, h.Name)
+					return nil
+				}); err != nil {
+					t.Fatal(err)
+				}
+				testutil.AssertEqual(t, testcase.wantArchive, names)
+			}
 		})
 	}
 }
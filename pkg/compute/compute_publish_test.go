@@ -13,6 +13,7 @@ import (
 	"github.com/fastly/cli/pkg/config"
 	"github.com/fastly/cli/pkg/mock"
 	"github.com/fastly/cli/pkg/testutil"
+	"github.com/fastly/cli/pkg/undo"
 )
 
 func TestPublish(t *testing.T) {
@@ -242,6 +243,10 @@ func TestPublish(t *testing.T) {
 			}
 			defer os.Chdir(pwd)
 
+			// Keep the undo journals inside the test environment.
+			defer func(dir string) { undo.JournalDir = dir }(undo.JournalDir)
+			undo.JournalDir = filepath.Join(rootdir, "undo")
+
 			var stdout bytes.Buffer
 			testcase.args = append(testcase.args, "--verbose") // verbose has a side effect of avoiding spinners when the test fails in CI
 			testcase.args = append(testcase.args, "--timeout", "120")
@@ -1,15 +1,19 @@
 package compute_test
 
 import (
+	"archive/tar"
+	"bytes"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"reflect"
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/fastly/cli/pkg/api"
@@ -136,7 +140,7 @@ func TestCreatePackageArchive(t *testing.T) {
 			}
 			defer os.Chdir(pwd)
 
-			err = compute.CreatePackageArchive(testcase.inputFiles, testcase.destination)
+			err = compute.CreatePackageArchive(testcase.inputFiles, testcase.destination, nil)
 			testutil.AssertNoError(t, err)
 
 			var files, directories []string
@@ -157,6 +161,144 @@ func TestCreatePackageArchive(t *testing.T) {
 	}
 }
 
+// TestCreatePackageArchiveReproducible validates that archiving identical
+// files gives an identical package, whatever their modification times.
+func TestCreatePackageArchiveReproducible(t *testing.T) {
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootdir := testutil.NewEnv(testutil.EnvOpts{
+		T: t,
+		Copy: []testutil.FileIO{
+			{Src: filepath.Join("testdata", "build", "Cargo.toml"), Dst: "Cargo.toml"},
+			{Src: filepath.Join("testdata", "build", "src", "main.rs"), Dst: filepath.Join("src", "main.rs")},
+		},
+	})
+	defer os.RemoveAll(rootdir)
+
+	if err := os.Chdir(rootdir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(pwd)
+
+	info := &compute.BuildInfo{
+		Language: "rust",
+		Files:    map[string]string{"Cargo.toml": "abc"},
+	}
+
+	// The files are listed in a different order, and modified in between.
+	err = compute.CreatePackageArchive([]string{"Cargo.toml", "src/main.rs"}, filepath.Join("a", "cli.tar.gz"), info)
+	testutil.AssertNoError(t, err)
+	mtime := time.Now().Add(time.Hour)
+	if err := os.Chtimes("Cargo.toml", mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	err = compute.CreatePackageArchive([]string{"src/main.rs", "Cargo.toml"}, filepath.Join("b", "cli.tar.gz"), info)
+	testutil.AssertNoError(t, err)
+
+	a, err := os.ReadFile(filepath.Join("a", "cli.tar.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join("b", "cli.tar.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a, b) {
+		t.Fatal("want identical package archives")
+	}
+
+	var names []string
+	if err := archiver.Walk(filepath.Join("a", "cli.tar.gz"), func(f archiver.File) error {
+		h := f.Header.(*tar.Header)
+		if !h.ModTime.Equal(time.Unix(0, 0)) || h.Uid != 0 || h.Gid != 0 {
+			t.Errorf("%s: want normalised metadata, have mtime %s uid %d gid %d", h.Name, h.ModTime, h.Uid, h.Gid)
+		}
+		names = append(names, h.Name)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertEqual(t, []string{"cli/", "cli/Cargo.toml", "cli/build-info.json", "cli/src/", "cli/src/main.rs"}, names)
+}
+
+// TestBuildInfoGitSHA validates that the build info records the last commit
+// which changed the packaged files, so that building identical files from a
+// later commit gives an identical package whose hash sum still matches the
+// deployed package.
+func TestBuildInfoGitSHA(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootdir := testutil.NewEnv(testutil.EnvOpts{
+		T: t,
+		Copy: []testutil.FileIO{
+			{Src: filepath.Join("testdata", "build", "Cargo.toml"), Dst: "Cargo.toml"},
+		},
+	})
+	defer os.RemoveAll(rootdir)
+
+	if err := os.Chdir(rootdir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(pwd)
+
+	git := func(args ...string) string {
+		args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+		out, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	language := compute.NewLanguage(&compute.LanguageOptions{Name: "rust"})
+	build := func(dst string) (*compute.BuildInfo, []byte) {
+		info, err := compute.NewBuildInfo(language, []string{"Cargo.toml"})
+		testutil.AssertNoError(t, err)
+		err = compute.CreatePackageArchive([]string{"Cargo.toml"}, filepath.Join(dst, "cli.tar.gz"), info)
+		testutil.AssertNoError(t, err)
+		bs, err := os.ReadFile(filepath.Join(dst, "cli.tar.gz"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info, bs
+	}
+
+	git("init", "--quiet")
+	git("add", "Cargo.toml")
+	git("commit", "--quiet", "-m", "first")
+	first := git("rev-parse", "HEAD")
+	infoA, a := build("a")
+	testutil.AssertEqual(t, first, infoA.GitSHA)
+
+	git("commit", "--quiet", "--allow-empty", "-m", "second")
+	infoB, b := build("b")
+	testutil.AssertEqual(t, first, infoB.GitSHA)
+	if !bytes.Equal(a, b) {
+		t.Fatal("want identical package archives")
+	}
+
+	f, err := os.OpenFile("Cargo.toml", os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("# changed\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	git("commit", "--quiet", "--all", "-m", "third")
+	infoC, _ := build("c")
+	testutil.AssertEqual(t, git("rev-parse", "HEAD"), infoC.GitSHA)
+}
+
 func TestFileNameWithoutExtension(t *testing.T) {
 	for _, testcase := range []struct {
 		input      string
@@ -177,76 +319,92 @@ func TestFileNameWithoutExtension(t *testing.T) {
 	} {
 		t.Run(testcase.input, func(t *testing.T) {
 			output := compute.FileNameWithoutExtension(testcase.input)
-			testutil.AssertString(t, testcase.wantOutput, output)
+			testutil.AssertEqual(t, testcase.wantOutput, output)
 		})
 	}
 }
 
-func TestGetIgnoredFiles(t *testing.T) {
+func TestIgnoreMatcher(t *testing.T) {
 	for _, testcase := range []struct {
 		name         string
 		fastlyignore string
-		wantfiles    map[string]bool
+		wantIgnored  []string
+		wantKept     []string
+		wantReason   map[string]string
 	}{
+		{
+			name:         "no patterns",
+			fastlyignore: "",
+			wantKept:     []string{"Cargo.toml", "src/main.rs", "bin/main.wasm"},
+		},
 		{
 			name:         "ignore src",
 			fastlyignore: "src/*",
-			wantfiles: map[string]bool{
-				filepath.Join("src/main.rs"): true,
-			},
+			wantIgnored:  []string{"src/main.rs"},
+			wantKept:     []string{"Cargo.toml", "lib/src/main.rs"},
 		},
 		{
 			name:         "ignore cargo files",
 			fastlyignore: "Cargo.*",
-			wantfiles: map[string]bool{
-				"Cargo.lock": true,
-				"Cargo.toml": true,
-			},
+			wantIgnored:  []string{"Cargo.lock", "Cargo.toml", "src/Cargo.toml"},
+			wantKept:     []string{"src/main.rs"},
 		},
 		{
 			name:         "ignore all",
 			fastlyignore: "*",
-			wantfiles: map[string]bool{
-				".fastlyignore": true,
-				"Cargo.lock":    true,
-				"Cargo.toml":    true,
-				"src":           true,
-			},
+			wantIgnored:  []string{".fastlyignore", "Cargo.lock", "Cargo.toml", "src/main.rs"},
+		},
+		{
+			name:         "comments and blank lines",
+			fastlyignore: "# *.rs\n\n\\#notes.md\n",
+			wantIgnored:  []string{"#notes.md"},
+			wantKept:     []string{"src/main.rs", "# *.rs"},
+		},
+		{
+			name:         "double asterisks",
+			fastlyignore: "**/testdata\nsrc/**/*.snap\nassets/**",
+			wantIgnored:  []string{"testdata/a.json", "src/x/testdata/b.json", "src/a.snap", "src/x/y/b.snap", "assets/img/logo.png"},
+			wantKept:     []string{"src/a.rs", "snap/a.snap", "assets"},
+		},
+		{
+			name:         "negation",
+			fastlyignore: "bin/*.wasm\n!bin/keep.wasm",
+			wantIgnored:  []string{"bin/debug.wasm"},
+			wantKept:     []string{"bin/keep.wasm"},
+			wantReason:   map[string]string{"bin/keep.wasm": ".fastlyignore:2: !bin/keep.wasm"},
+		},
+		{
+			name:         "parent directory can't be re-included",
+			fastlyignore: "generated/\n!generated/keep.rs",
+			wantIgnored:  []string{"generated/keep.rs", "src/generated/a.rs"},
+			wantKept:     []string{"generated.rs"},
+			wantReason:   map[string]string{"generated/keep.rs": ".fastlyignore:1: generated/"},
+		},
+		{
+			name:         "anchored and character classes",
+			fastlyignore: "/README.md\n*.[oa]\nlog?.txt\n[!a]*.tmp",
+			wantIgnored:  []string{"README.md", "src/lib.o", "lib.a", "log1.txt", "b.tmp"},
+			wantKept:     []string{"docs/README.md", "lib.c", "log10.txt", "a.tmp"},
 		},
 	} {
 		t.Run(testcase.name, func(t *testing.T) {
-			// we're going to chdir to a build environment,
-			// so save the pwd to return to, afterwards.
-			pwd, err := os.Getwd()
-			if err != nil {
-				t.Fatal(err)
-			}
-
-			// Create test environment
-			rootdir := testutil.NewEnv(testutil.EnvOpts{
-				T: t,
-				Copy: []testutil.FileIO{
-					{Src: filepath.Join("testdata", "build", "Cargo.lock"), Dst: "Cargo.lock"},
-					{Src: filepath.Join("testdata", "build", "Cargo.toml"), Dst: "Cargo.toml"},
-					{Src: filepath.Join("testdata", "build", "src", "main.rs"), Dst: filepath.Join("src", "main.rs")},
-				},
-				Write: []testutil.FileIO{
-					{Src: testcase.fastlyignore, Dst: compute.IgnoreFilePath},
-				},
-			})
-			defer os.RemoveAll(rootdir)
+			m, err := compute.NewIgnoreMatcher(strings.NewReader(testcase.fastlyignore), compute.IgnoreFilePath)
+			testutil.AssertNoError(t, err)
 
-			// before running the test, chdir into the build environment.
-			// when we're done, chdir back to our original location.
-			// this is so we can reliably copy the testdata/ fixtures.
-			if err := os.Chdir(rootdir); err != nil {
-				t.Fatal(err)
+			for _, path := range testcase.wantIgnored {
+				if ignored, _ := m.Match(path, false); !ignored {
+					t.Errorf("want %s ignored", path)
+				}
+			}
+			for _, path := range testcase.wantKept {
+				if ignored, reason := m.Match(path, false); ignored {
+					t.Errorf("want %s kept, ignored by %s", path, reason)
+				}
+			}
+			for path, want := range testcase.wantReason {
+				_, reason := m.Match(path, false)
+				testutil.AssertEqual(t, want, reason)
 			}
-			defer os.Chdir(pwd)
-
-			output, err := compute.GetIgnoredFiles(compute.IgnoreFilePath)
-			testutil.AssertNoError(t, err)
-			testutil.AssertEqual(t, testcase.wantfiles, output)
 		})
 	}
 }
@@ -255,13 +413,13 @@ func TestGetNonIgnoredFiles(t *testing.T) {
 	for _, testcase := range []struct {
 		name         string
 		path         string
-		ignoredFiles map[string]bool
+		fastlyignore string
 		wantFiles    []string
 	}{
 		{
 			name:         "no ignored files",
 			path:         ".",
-			ignoredFiles: map[string]bool{},
+			fastlyignore: "",
 			wantFiles: []string{
 				"Cargo.lock",
 				"Cargo.toml",
@@ -269,27 +427,31 @@ func TestGetNonIgnoredFiles(t *testing.T) {
 			},
 		},
 		{
-			name: "one ignored file",
-			path: ".",
-			ignoredFiles: map[string]bool{
-				filepath.Join("src/main.rs"): true,
-			},
+			name:         "one ignored file",
+			path:         ".",
+			fastlyignore: "src/main.rs",
 			wantFiles: []string{
 				"Cargo.lock",
 				"Cargo.toml",
 			},
 		},
 		{
-			name: "multiple ignored files",
-			path: ".",
-			ignoredFiles: map[string]bool{
-				"Cargo.toml": true,
-				"Cargo.lock": true,
-			},
+			name:         "multiple ignored files",
+			path:         ".",
+			fastlyignore: "Cargo.toml\nCargo.lock",
 			wantFiles: []string{
 				filepath.Join("src/main.rs"),
 			},
 		},
+		{
+			name:         "ignored directory",
+			path:         ".",
+			fastlyignore: "src/",
+			wantFiles: []string{
+				"Cargo.lock",
+				"Cargo.toml",
+			},
+		},
 	} {
 		t.Run(testcase.name, func(t *testing.T) {
 			// We're going to chdir to a build environment,
@@ -318,7 +480,10 @@ func TestGetNonIgnoredFiles(t *testing.T) {
 			}
 			defer os.Chdir(pwd)
 
-			output, err := compute.GetNonIgnoredFiles(testcase.path, testcase.ignoredFiles)
+			ignore, err := compute.NewIgnoreMatcher(strings.NewReader(testcase.fastlyignore), compute.IgnoreFilePath)
+			testutil.AssertNoError(t, err)
+
+			output, err := compute.GetNonIgnoredFiles(testcase.path, ignore)
 			testutil.AssertNoError(t, err)
 			testutil.AssertEqual(t, testcase.wantFiles, output)
 		})
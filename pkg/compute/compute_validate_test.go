@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/fastly/cli/pkg/app"
+	"github.com/fastly/cli/pkg/compute"
 	"github.com/fastly/cli/pkg/testutil"
 )
 
@@ -57,3 +58,92 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateBuildInfo(t *testing.T) {
+	args := testutil.Args
+	for _, testcase := range []struct {
+		name       string
+		info       *compute.BuildInfo
+		wantError  string
+		wantOutput []string
+	}{
+		{
+			name: "no build info",
+		},
+		{
+			name: "build info",
+			info: &compute.BuildInfo{
+				Language:  "other",
+				GitSHA:    "0123456789abcdef",
+				Toolchain: map[string]string{"tinygo": "0.20.0"},
+				Files: map[string]string{
+					"fastly.toml": "4d12f07a44ff1355e59954763516e6501c7831bd93174691a79b28dbf994a26f",
+					"main.wasm":   "336154bf67f765f8f75d16a0accee61b5ee5f6a75b2a2905703df913bd550f3e",
+				},
+			},
+			wantOutput: []string{
+				"Language: other",
+				"Git SHA: 0123456789abcdef",
+				"tinygo: 0.20.0",
+				"336154bf67f765f8f75d16a0accee61b5ee5f6a75b2a2905703df913bd550f3e  main.wasm",
+			},
+		},
+		{
+			name: "modified file",
+			info: &compute.BuildInfo{
+				Language: "other",
+				Files: map[string]string{
+					"main.wasm": "0000000000000000000000000000000000000000000000000000000000000000",
+				},
+			},
+			wantError: "error validating package: main.wasm doesn't match the hash in build-info.json",
+		},
+		{
+			name: "missing file",
+			info: &compute.BuildInfo{
+				Language: "other",
+				Files: map[string]string{
+					"src/main.go": "0000000000000000000000000000000000000000000000000000000000000000",
+				},
+			},
+			wantError: "build-info.json lists src/main.go, which the package doesn't contain",
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			pwd, err := os.Getwd()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rootdir := testutil.NewEnv(testutil.EnvOpts{
+				T: t,
+				Write: []testutil.FileIO{
+					{Src: "name = \"test\"\n", Dst: "fastly.toml"},
+					{Src: "wasm", Dst: "main.wasm"},
+				},
+			})
+			defer os.RemoveAll(rootdir)
+
+			if err := os.Chdir(rootdir); err != nil {
+				t.Fatal(err)
+			}
+			defer os.Chdir(pwd)
+
+			files := []string{"fastly.toml", "main.wasm"}
+			if err := compute.CreatePackageArchive(files, filepath.Join("pkg", "test.tar.gz"), testcase.info); err != nil {
+				t.Fatal(err)
+			}
+
+			var stdout bytes.Buffer
+			opts := testutil.NewRunOpts(args("compute validate -p pkg/test.tar.gz"), &stdout)
+			err = app.Run(opts)
+			testutil.AssertErrorContains(t, err, testcase.wantError)
+			if testcase.wantError == "" {
+				testutil.AssertStringContains(t, stdout.String(), "Validated package")
+			}
+			for _, want := range testcase.wantOutput {
+				testutil.AssertStringContains(t, stdout.String(), want)
+			}
+		})
+	}
+}
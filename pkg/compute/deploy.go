@@ -35,19 +35,29 @@ const (
 	resourceBackend
 )
 
+// Names of the undo actions recorded by the deploy command, which are
+// journaled so that `compute deploy --rollback-incomplete` can replay them.
+const (
+	undoClearServiceID = "clear-manifest-service-id"
+	undoDeleteBackend  = "delete-backend"
+	undoDeleteDomain   = "delete-domain"
+	undoDeleteService  = "delete-service"
+)
+
 // DeployCommand deploys an artifact previously produced by build.
 type DeployCommand struct {
 	cmd.Base
 
 	// NOTE: these are public so that the "publish" composite command can set the
 	// values appropriately before calling the Exec() function.
-	Manifest       manifest.Data
-	Path           string
-	Domain         string
-	Backend        string
-	BackendPort    uint
-	Comment        cmd.OptionalString
-	ServiceVersion cmd.OptionalServiceVersion
+	Manifest           manifest.Data
+	Path               string
+	Domain             string
+	Backend            string
+	BackendPort        uint
+	Comment            cmd.OptionalString
+	ServiceVersion     cmd.OptionalServiceVersion
+	RollbackIncomplete bool
 }
 
 // NewDeployCommand returns a usable command registered under the parent.
@@ -71,6 +81,7 @@ func NewDeployCommand(parent cmd.Registerer, client api.HTTPClient, globals *con
 	c.CmdClause.Flag("backend", "A hostname, IPv4, or IPv6 address for the package backend").StringVar(&c.Backend)
 	c.CmdClause.Flag("backend-port", "A port number for the package backend").UintVar(&c.BackendPort)
 	c.CmdClause.Flag("comment", "Human-readable comment").Action(c.Comment.Set).StringVar(&c.Comment.Value)
+	c.CmdClause.Flag("rollback-incomplete", "Undo the changes made by a previous deploy which was interrupted").BoolVar(&c.RollbackIncomplete)
 	return &c
 }
 
@@ -82,6 +93,25 @@ func (c *DeployCommand) Exec(in io.Reader, out io.Writer) (err error) {
 		return errors.ErrNoToken
 	}
 
+	if c.RollbackIncomplete {
+		return c.rollback(out)
+	}
+
+	journalPath, err := projectJournalPath()
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+
+	// A journal left behind by an earlier deploy of this project means its
+	// changes weren't fully undone, and we don't want to lose track of them.
+	if _, err := os.Stat(journalPath); err == nil {
+		return errors.RemediationError{
+			Inner:       fmt.Errorf("a previous deploy was interrupted before its changes could be undone"),
+			Remediation: fmt.Sprintf("Run `fastly compute deploy --rollback-incomplete` to undo them, or delete %s to ignore them.", journalPath),
+		}
+	}
+
 	// The first thing we want to do is validate that a package has been built.
 	// There is no point prompting a user for info if we know we're going to
 	// fail any way because the user didn't build a package first.
@@ -95,7 +125,7 @@ func (c *DeployCommand) Exec(in io.Reader, out io.Writer) (err error) {
 		})
 		return err
 	}
-	if err := validate(path); err != nil {
+	if _, err := validate(path); err != nil {
 		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
 			"Path": path,
 		})
@@ -245,7 +275,7 @@ func (c *DeployCommand) Exec(in io.Reader, out io.Writer) (err error) {
 		progress = text.NewQuietProgress(out)
 	}
 
-	undoStack := undo.NewStack()
+	undoStack := undo.NewJournaledStack(undo.NewJournal(journalPath, "compute deploy"), undoHandlers(c.Globals.Client))
 
 	defer func(errLog errors.LogInterface) {
 		if err != nil {
@@ -271,16 +301,24 @@ func (c *DeployCommand) Exec(in io.Reader, out io.Writer) (err error) {
 			return err
 		}
 
-		undoStack.Push(func() error {
-			clearServiceID := ""
-			return updateManifestServiceID(&c.Manifest.File, manifest.Filename, nil, clearServiceID)
+		manifestPath, err := filepath.Abs(manifest.Filename)
+		if err != nil {
+			return err
+		}
+		err = undoStack.PushAction(undo.Action{
+			Name:   undoClearServiceID,
+			Params: map[string]string{"manifest": manifestPath},
 		})
-
-		undoStack.Push(func() error {
-			return c.Globals.Client.DeleteService(&fastly.DeleteServiceInput{
-				ID: serviceID,
-			})
+		if err != nil {
+			return err
+		}
+		err = undoStack.PushAction(undo.Action{
+			Name:   undoDeleteService,
+			Params: map[string]string{"service_id": serviceID},
 		})
+		if err != nil {
+			return err
+		}
 
 		// We can't create the domain/backend earlier in the logic flow as it
 		// requires the use of a text.Progress which overwrites the current line
@@ -658,15 +696,19 @@ func cfgBackend(backend string, backendPort uint, out io.Writer, in io.Reader, f
 func createDomain(progress text.Progress, client api.Interface, serviceID string, version int, domain string, undoStack undo.Stacker) error {
 	progress.Step("Creating domain...")
 
-	undoStack.Push(func() error {
-		return client.DeleteDomain(&fastly.DeleteDomainInput{
-			ServiceID:      serviceID,
-			ServiceVersion: version,
-			Name:           domain,
-		})
+	err := undoStack.PushAction(undo.Action{
+		Name: undoDeleteDomain,
+		Params: map[string]string{
+			"service_id":      serviceID,
+			"service_version": strconv.Itoa(version),
+			"name":            domain,
+		},
 	})
+	if err != nil {
+		return err
+	}
 
-	_, err := client.CreateDomain(&fastly.CreateDomainInput{
+	_, err = client.CreateDomain(&fastly.CreateDomainInput{
 		ServiceID:      serviceID,
 		ServiceVersion: version,
 		Name:           domain,
@@ -683,15 +725,19 @@ func createDomain(progress text.Progress, client api.Interface, serviceID string
 func createBackend(progress text.Progress, client api.Interface, serviceID string, version int, backend string, backendPort uint, undoStack undo.Stacker) error {
 	progress.Step("Creating backend...")
 
-	undoStack.Push(func() error {
-		return client.DeleteBackend(&fastly.DeleteBackendInput{
-			ServiceID:      serviceID,
-			ServiceVersion: version,
-			Name:           backend,
-		})
+	err := undoStack.PushAction(undo.Action{
+		Name: undoDeleteBackend,
+		Params: map[string]string{
+			"service_id":      serviceID,
+			"service_version": strconv.Itoa(version),
+			"name":            backend,
+		},
 	})
+	if err != nil {
+		return err
+	}
 
-	_, err := client.CreateBackend(&fastly.CreateBackendInput{
+	_, err = client.CreateBackend(&fastly.CreateBackendInput{
 		ServiceID:      serviceID,
 		ServiceVersion: version,
 		Name:           backend,
@@ -705,6 +751,101 @@ func createBackend(progress text.Progress, client api.Interface, serviceID strin
 	return nil
 }
 
+// rollback replays the undo journal left behind by an interrupted deploy.
+func (c *DeployCommand) rollback(out io.Writer) error {
+	journalPath, err := projectJournalPath()
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+
+	j, err := undo.ReadJournal(journalPath)
+	if os.IsNotExist(err) {
+		text.Info(out, "There is no incomplete deploy to roll back")
+		return nil
+	}
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+	if j.Command != "compute deploy" {
+		return fmt.Errorf("the undo journal %s was recorded by `fastly %s`", j.Path(), j.Command)
+	}
+
+	n := len(j.Actions)
+	text.Output(out, "Rolling back the deploy started at %s...", j.CreatedAt.Format(time.RFC3339))
+	if err := j.Replay(out, undoHandlers(c.Globals.Client)); err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Journal": j.Path(),
+		})
+		return err
+	}
+
+	text.Success(out, "Rolled back %d change(s)", n)
+	return nil
+}
+
+// projectJournalPath returns the location of the undo journal of the project
+// in the current directory. Each project has its own journal, as a service ID
+// isn't known until a new service is created.
+func projectJournalPath() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("error getting current working directory: %w", err)
+	}
+	return undo.JournalPath(wd), nil
+}
+
+// undoHandlers returns the handlers which perform the undo actions recorded by
+// the deploy command.
+//
+// A resource which no longer exists doesn't need deleting, and so a 404 from
+// the API isn't treated as an error. This is required when replaying a
+// journal, as actions are recorded before the resource they undo is created.
+func undoHandlers(client api.Interface) undo.Handlers {
+	return undo.Handlers{
+		undoClearServiceID: func(params map[string]string) error {
+			var m manifest.File
+			return updateManifestServiceID(&m, params["manifest"], nil, "")
+		},
+		undoDeleteService: func(params map[string]string) error {
+			return ignoreNotFound(client.DeleteService(&fastly.DeleteServiceInput{
+				ID: params["service_id"],
+			}))
+		},
+		undoDeleteDomain: func(params map[string]string) error {
+			version, err := strconv.Atoi(params["service_version"])
+			if err != nil {
+				return err
+			}
+			return ignoreNotFound(client.DeleteDomain(&fastly.DeleteDomainInput{
+				ServiceID:      params["service_id"],
+				ServiceVersion: version,
+				Name:           params["name"],
+			}))
+		},
+		undoDeleteBackend: func(params map[string]string) error {
+			version, err := strconv.Atoi(params["service_version"])
+			if err != nil {
+				return err
+			}
+			return ignoreNotFound(client.DeleteBackend(&fastly.DeleteBackendInput{
+				ServiceID:      params["service_id"],
+				ServiceVersion: version,
+				Name:           params["name"],
+			}))
+		},
+	}
+}
+
+// ignoreNotFound discards an API error reporting the resource doesn't exist.
+func ignoreNotFound(err error) error {
+	if herr, ok := err.(*fastly.HTTPError); ok && herr.IsNotFound() {
+		return nil
+	}
+	return err
+}
+
 func getHashSum(path string) (hash string, err error) {
 	// gosec flagged this:
 	// G304 (CWE-22): Potential file inclusion via variable
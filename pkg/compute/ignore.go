@@ -0,0 +1,214 @@
+package compute
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreMatcher matches paths against the patterns of a Fastly ignore file,
+// which have the syntax and semantics of a .gitignore file:
+//
+//   - blank lines and lines starting with # are skipped
+//   - a leading ! re-includes paths an earlier pattern ignored
+//   - a trailing / only matches directories
+//   - a pattern with a / at its start or middle is relative to the package
+//     root, otherwise it matches at any level
+//   - * and ? match within a path segment, and ** matches across them
+//
+// As with git, a path can't be re-included if a parent directory is ignored.
+type IgnoreMatcher struct {
+	patterns []ignorePattern
+}
+
+// ignorePattern is a compiled line of an ignore file.
+type ignorePattern struct {
+	source  string
+	line    int
+	text    string
+	negate  bool
+	dirOnly bool
+	re      *regexp.Regexp
+}
+
+// ReadIgnoreFile reads the patterns of an ignore file. If the file doesn't
+// exist nothing is ignored.
+func ReadIgnoreFile(filePath string) (m *IgnoreMatcher, err error) {
+	// gosec flagged this:
+	// G304 (CWE-22): Potential file inclusion via variable
+	// Disabling as we trust the source of the filepath variable as it comes
+	// from the IgnoreFilePath constant.
+	/* #nosec */
+	file, err := os.Open(filePath)
+	if os.IsNotExist(err) {
+		return &IgnoreMatcher{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		cerr := file.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	return NewIgnoreMatcher(file, filePath)
+}
+
+// NewIgnoreMatcher parses the patterns of an ignore file, read from source.
+func NewIgnoreMatcher(r io.Reader, source string) (*IgnoreMatcher, error) {
+	var m IgnoreMatcher
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		p, ok, err := parseIgnorePattern(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s line %d: %w", source, n, err)
+		}
+		if !ok {
+			continue
+		}
+		p.source, p.line = source, n
+		m.patterns = append(m.patterns, p)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s file: %w", source, err)
+	}
+
+	return &m, nil
+}
+
+// Match reports whether a path, relative to the package root, is ignored, and
+// describes the pattern which decided it. A path is ignored if a parent
+// directory is.
+func (m *IgnoreMatcher) Match(name string, isDir bool) (ignored bool, reason string) {
+	name = strings.TrimPrefix(path.Clean(filepath.ToSlash(name)), "./")
+
+	segs := strings.Split(name, "/")
+	for i := 1; i < len(segs); i++ {
+		if ignored, reason := m.match(strings.Join(segs[:i], "/"), true); ignored {
+			return true, reason
+		}
+	}
+	return m.match(name, isDir)
+}
+
+// match applies the patterns to a path, the last matching pattern deciding
+// whether it's ignored.
+func (m *IgnoreMatcher) match(name string, isDir bool) (ignored bool, reason string) {
+	for _, p := range m.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.re.MatchString(name) {
+			ignored = !p.negate
+			reason = fmt.Sprintf("%s:%d: %s", p.source, p.line, p.text)
+		}
+	}
+	return ignored, reason
+}
+
+// parseIgnorePattern compiles a line of an ignore file, returning false if
+// the line has no pattern.
+func parseIgnorePattern(line string) (ignorePattern, bool, error) {
+	// Trailing spaces are ignored unless they're escaped.
+	line = strings.TrimSuffix(line, "\r")
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false, nil
+	}
+
+	p := ignorePattern{text: line}
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignorePattern{}, false, nil
+	}
+
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+
+	r := []rune(line)
+	for i := 0; i < len(r); i++ {
+		atSegmentStart := i == 0 || r[i-1] == '/'
+		switch {
+		case r[i] == '*' && i+1 < len(r) && r[i+1] == '*' && atSegmentStart && (i+2 == len(r) || r[i+2] == '/'):
+			if i+2 == len(r) {
+				// A trailing ** matches everything inside.
+				b.WriteString(".*")
+			} else {
+				// A leading or middle **/ matches zero or more directories.
+				b.WriteString("(?:.*/)?")
+			}
+			i += 2
+		case r[i] == '*':
+			b.WriteString("[^/]*")
+		case r[i] == '?':
+			b.WriteString("[^/]")
+		case r[i] == '[':
+			end := classEnd(r, i)
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := string(r[i+1 : end])
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i = end
+		case r[i] == '\\' && i+1 < len(r):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(r[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r[i])))
+		}
+	}
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return ignorePattern{}, false, fmt.Errorf("invalid pattern %q: %w", p.text, err)
+	}
+	p.re = re
+	return p, true, nil
+}
+
+// classEnd returns the index of the ] closing the character class starting
+// at i, or -1 if it isn't closed.
+func classEnd(r []rune, i int) int {
+	j := i + 1
+	if j < len(r) && (r[j] == '!' || r[j] == '^') {
+		j++
+	}
+	// A ] straight after the opening [ is part of the class.
+	if j < len(r) && r[j] == ']' {
+		j++
+	}
+	for ; j < len(r); j++ {
+		if r[j] == ']' {
+			return j
+		}
+	}
+	return -1
+}
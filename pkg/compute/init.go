@@ -171,11 +171,11 @@ func (c *InitCommand) Exec(in io.Reader, out io.Writer) (err error) {
 	}
 
 	authors, _ = c.manifest.Authors()
-	authors, err = pkgAuthors(authors, c.Globals.File.User.Email, in, out)
+	authors, err = pkgAuthors(authors, c.Globals.Email(), in, out)
 	if err != nil {
 		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
 			"Authors": authors,
-			"Email":   c.Globals.File.User.Email,
+			"Email":   c.Globals.Email(),
 		})
 		return err
 	}
@@ -260,6 +260,7 @@ func (c *InitCommand) Exec(in io.Reader, out io.Writer) (err error) {
 
 	if language.Name == "other" {
 		text.Description(out, "To package a pre-compiled Wasm binary for deployment, run", "fastly compute pack")
+		text.Description(out, "To compile the package with your own build command, add it to the [scripts] section of fastly.toml and run", "fastly compute build")
 		text.Description(out, "To deploy the package, run", "fastly compute deploy")
 	} else {
 		text.Description(out, "To publish the package (build and deploy), run", "fastly compute publish")
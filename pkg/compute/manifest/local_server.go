@@ -0,0 +1,197 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Formats of the dictionary and geolocation data of the local server.
+const (
+	// FormatInlineTOML indicates the data is defined in the manifest.
+	FormatInlineTOML = "inline-toml"
+
+	// FormatJSON indicates the data is read from a JSON file.
+	FormatJSON = "json"
+)
+
+// Limits of the edge dictionary items of a Fastly service.
+const (
+	maxDictionaryKeyLength   = 256
+	maxDictionaryValueLength = 8000
+)
+
+// LocalServer represents the backends, dictionaries and geolocation data that
+// should be mocked by the local testing server as per the configuration values.
+type LocalServer struct {
+	Backends     map[string]Backend    `toml:"backends"`
+	Dictionaries map[string]Dictionary `toml:"dictionaries,omitempty"`
+	Geolocation  *Geolocation          `toml:"geolocation,omitempty"`
+}
+
+// Backend represents a backend to be mocked by the local testing server.
+type Backend struct {
+	URL          string `toml:"url"`
+	OverrideHost string `toml:"override_host,omitempty"`
+}
+
+// Dictionary represents an edge dictionary to be mocked by the local testing
+// server. The items are either defined inline as the contents or read from a
+// JSON file of string values.
+type Dictionary struct {
+	Format   string            `toml:"format"`
+	File     string            `toml:"file,omitempty"`
+	Contents map[string]string `toml:"contents,omitempty"`
+}
+
+// Geolocation represents the geolocation data, by client IP address, to be
+// returned by the local testing server. The data is either defined inline as
+// the addresses or read from a JSON file of the same structure.
+type Geolocation struct {
+	Format    string                            `toml:"format"`
+	File      string                            `toml:"file,omitempty"`
+	Addresses map[string]map[string]interface{} `toml:"addresses,omitempty"`
+}
+
+// Validate checks the local server configuration, reading any data files
+// relative to the directory of the manifest. All of the problems found are
+// returned in one error.
+func (l LocalServer) Validate(dir string) error {
+	var problems []string
+
+	for _, name := range sortedKeys(l.Backends) {
+		problems = append(problems, l.Backends[name].validate(name)...)
+	}
+
+	for _, name := range sortedKeys(l.Dictionaries) {
+		problems = append(problems, l.Dictionaries[name].validate(name, dir)...)
+	}
+
+	if l.Geolocation != nil {
+		problems = append(problems, l.Geolocation.validate(dir)...)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid [local_server] configuration:\n\t%s", strings.Join(problems, "\n\t"))
+	}
+	return nil
+}
+
+func (b Backend) validate(name string) (problems []string) {
+	if b.URL == "" {
+		return []string{fmt.Sprintf("backend %s: url is required", name)}
+	}
+	u, err := url.Parse(b.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		problems = append(problems, fmt.Sprintf("backend %s: url %q must be an absolute http or https URL", name, b.URL))
+	}
+	if b.OverrideHost != "" && strings.ContainsAny(b.OverrideHost, "/ ") {
+		problems = append(problems, fmt.Sprintf("backend %s: override_host %q must be a hostname, without a scheme or path", name, b.OverrideHost))
+	}
+	return problems
+}
+
+func (d Dictionary) validate(name, dir string) []string {
+	prefix := fmt.Sprintf("dictionary %s", name)
+
+	var items map[string]string
+	switch d.Format {
+	case FormatInlineTOML:
+		if d.File != "" {
+			return []string{fmt.Sprintf("%s: file can't be used with format %q, define the items in contents instead", prefix, FormatInlineTOML)}
+		}
+		items = d.Contents
+	case FormatJSON:
+		if len(d.Contents) > 0 {
+			return []string{fmt.Sprintf("%s: contents can't be used with format %q", prefix, FormatJSON)}
+		}
+		if d.File == "" {
+			return []string{fmt.Sprintf("%s: file is required with format %q", prefix, FormatJSON)}
+		}
+		if err := readJSON(dir, d.File, &items); err != nil {
+			return []string{fmt.Sprintf("%s: %s", prefix, err)}
+		}
+	default:
+		return []string{fmt.Sprintf("%s: format %q must be %q or %q", prefix, d.Format, FormatInlineTOML, FormatJSON)}
+	}
+
+	var problems []string
+	for _, key := range sortedKeys(items) {
+		if len(key) > maxDictionaryKeyLength {
+			problems = append(problems, fmt.Sprintf("%s: key %s... is longer than %d characters", prefix, key[:20], maxDictionaryKeyLength))
+		}
+		if len(items[key]) > maxDictionaryValueLength {
+			problems = append(problems, fmt.Sprintf("%s: value of key %q is longer than %d characters", prefix, key, maxDictionaryValueLength))
+		}
+	}
+	return problems
+}
+
+func (g Geolocation) validate(dir string) []string {
+	var addresses map[string]map[string]interface{}
+	switch g.Format {
+	case FormatInlineTOML:
+		if g.File != "" {
+			return []string{fmt.Sprintf("geolocation: file can't be used with format %q, define the data in addresses instead", FormatInlineTOML)}
+		}
+		addresses = g.Addresses
+	case FormatJSON:
+		if len(g.Addresses) > 0 {
+			return []string{fmt.Sprintf("geolocation: addresses can't be used with format %q", FormatJSON)}
+		}
+		if g.File == "" {
+			return []string{fmt.Sprintf("geolocation: file is required with format %q", FormatJSON)}
+		}
+		if err := readJSON(dir, g.File, &addresses); err != nil {
+			return []string{fmt.Sprintf("geolocation: %s", err)}
+		}
+	default:
+		return []string{fmt.Sprintf("geolocation: format %q must be %q or %q", g.Format, FormatInlineTOML, FormatJSON)}
+	}
+
+	var problems []string
+	for _, addr := range sortedKeys(addresses) {
+		if net.ParseIP(addr) == nil {
+			problems = append(problems, fmt.Sprintf("geolocation: %q isn't an IP address", addr))
+		}
+	}
+	return problems
+}
+
+// readJSON decodes a JSON file, whose path is relative to dir unless absolute.
+func readJSON(dir, file string, v interface{}) error {
+	fpath := file
+	if !filepath.IsAbs(fpath) {
+		fpath = filepath.Join(dir, fpath)
+	}
+
+	// gosec flagged this:
+	// G304 (CWE-22): Potential file inclusion via variable.
+	// Disabling as the file is configured by the user in their fastly.toml.
+	/* #nosec */
+	bs, err := os.ReadFile(fpath)
+	if err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+	if err := json.Unmarshal(bs, v); err != nil {
+		return fmt.Errorf("error parsing %s: %w", file, err)
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of a map with string keys sorted, so that
+// problems are reported in a stable order.
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -198,20 +198,18 @@ type File struct {
 	Language        string      `toml:"language"`
 	ServiceID       string      `toml:"service_id"`
 	LocalServer     LocalServer `toml:"local_server"`
+	Scripts         Scripts     `toml:"scripts,omitempty"`
 
 	exists bool
 	output io.Writer
 }
 
-// LocalServer represents a list of backends that should be mocked as per the
-// configuration values.
-type LocalServer struct {
-	Backends map[string]Backend `toml:"backends"`
-}
-
-// Backend represents a backend to be mocked by the local testing server.
-type Backend struct {
-	URL string `toml:"url"`
+// Scripts represents the commands a package uses in place of a language
+// toolchain, such as the build command of a package whose language is "other",
+// and the directory of the source they build.
+type Scripts struct {
+	Build           string `toml:"build,omitempty"`
+	SourceDirectory string `toml:"source_directory,omitempty"`
 }
 
 // Exists yields whether the manifest exists.
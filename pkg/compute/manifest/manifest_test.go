@@ -288,3 +288,107 @@ func TestManifestPersistsLocalServerSection(t *testing.T) {
 		t.Fatal("testing section between original and updated fastly.toml do not match")
 	}
 }
+
+func TestLocalServerValidate(t *testing.T) {
+	dir, err := os.MkdirTemp("", "fastly-local-server-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, content := range map[string]string{
+		"dictionary.json":  `{"greeting": "hello"}`,
+		"invalid.json":     `{"greeting": 1}`,
+		"geolocation.json": `{"127.0.0.1": {"as_name": "Fastly Test"}, "localhost": {}}`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := map[string]struct {
+		localServer manifest.LocalServer
+		wantErrors  []string
+	}{
+		"valid": {
+			localServer: manifest.LocalServer{
+				Backends: map[string]manifest.Backend{
+					"origin": {URL: "https://example.com/", OverrideHost: "www.example.com"},
+				},
+				Dictionaries: map[string]manifest.Dictionary{
+					"inline": {Format: manifest.FormatInlineTOML, Contents: map[string]string{"greeting": "hello"}},
+					"file":   {Format: manifest.FormatJSON, File: "dictionary.json"},
+				},
+				Geolocation: &manifest.Geolocation{
+					Format: manifest.FormatInlineTOML,
+					Addresses: map[string]map[string]interface{}{
+						"2001:db8::1": {"country_code": "GB"},
+					},
+				},
+			},
+		},
+		"empty": {},
+		"invalid backends": {
+			localServer: manifest.LocalServer{
+				Backends: map[string]manifest.Backend{
+					"a": {},
+					"b": {URL: "example.com", OverrideHost: "https://www.example.com"},
+				},
+			},
+			wantErrors: []string{
+				"backend a: url is required",
+				`backend b: url "example.com" must be an absolute http or https URL`,
+				`backend b: override_host "https://www.example.com" must be a hostname`,
+			},
+		},
+		"invalid dictionaries": {
+			localServer: manifest.LocalServer{
+				Dictionaries: map[string]manifest.Dictionary{
+					"a": {Contents: map[string]string{"k": "v"}},
+					"b": {Format: manifest.FormatInlineTOML, File: "dictionary.json"},
+					"c": {Format: manifest.FormatJSON},
+					"d": {Format: manifest.FormatJSON, File: "missing.json"},
+					"e": {Format: manifest.FormatJSON, File: "invalid.json"},
+					"f": {Format: manifest.FormatInlineTOML, Contents: map[string]string{"k": strings.Repeat("v", 8001)}},
+				},
+			},
+			wantErrors: []string{
+				`dictionary a: format "" must be "inline-toml" or "json"`,
+				`dictionary b: file can't be used with format "inline-toml"`,
+				`dictionary c: file is required with format "json"`,
+				"dictionary d: error reading file",
+				"dictionary e: error parsing invalid.json",
+				`dictionary f: value of key "k" is longer than 8000 characters`,
+			},
+		},
+		"invalid geolocation file": {
+			localServer: manifest.LocalServer{
+				Geolocation: &manifest.Geolocation{
+					Format: manifest.FormatJSON,
+					File:   "geolocation.json",
+				},
+			},
+			wantErrors: []string{
+				`geolocation: "localhost" isn't an IP address`,
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.localServer.Validate(dir)
+			if len(tc.wantErrors) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, want := range tc.wantErrors {
+				testutil.AssertStringContains(t, err.Error(), want)
+			}
+		})
+	}
+}
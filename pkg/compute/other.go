@@ -0,0 +1,106 @@
+package compute
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/fastly/cli/pkg/compute/manifest"
+	"github.com/fastly/cli/pkg/errors"
+	fstexec "github.com/fastly/cli/pkg/exec"
+	"github.com/fastly/cli/pkg/filesystem"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// Other implements a Toolchain for languages the CLI doesn't support directly,
+// building the package with the command in the [scripts] section of the
+// package manifest.
+type Other struct {
+	build   string
+	timeout int
+}
+
+// NewOther constructs a new Other toolchain which runs the build command.
+func NewOther(build string, timeout int) *Other {
+	return &Other{
+		build:   build,
+		timeout: timeout,
+	}
+}
+
+// Initialize implements the Toolchain interface. There are no dependencies to
+// install, so it's a no-op.
+func (o Other) Initialize(out io.Writer) error {
+	return nil
+}
+
+// Verify implements the Toolchain interface and checks the package manifest
+// has a build command.
+func (o Other) Verify(out io.Writer) error {
+	if o.build == "" {
+		return errBuildScriptMissing()
+	}
+
+	fmt.Fprintf(out, "Found build command: %s\n", o.build)
+
+	return nil
+}
+
+// Build implements the Toolchain interface and runs the build command, which
+// is expected to write the Wasm binary to bin/main.wasm.
+func (o Other) Build(out io.Writer, verbose bool) error {
+	if o.build == "" {
+		return errBuildScriptMissing()
+	}
+
+	// Check if bin directory exists and create if not.
+	pwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting current working directory: %w", err)
+	}
+	binDir := filepath.Join(pwd, "bin")
+	if err := filesystem.MakeDirectoryIfNotExists(binDir); err != nil {
+		return fmt.Errorf("making bin directory: %w", err)
+	}
+
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+
+	cmd := fstexec.Streaming{
+		Command: shell,
+		Args:    []string{flag, o.build},
+		Env:     []string{},
+		Output:  out,
+	}
+	if o.timeout > 0 {
+		cmd.Timeout = time.Duration(o.timeout) * time.Second
+	}
+	if err := cmd.Exec(); err != nil {
+		return err
+	}
+
+	bin := filepath.Join(binDir, "main.wasm")
+	if !filesystem.FileExists(bin) {
+		return errors.RemediationError{
+			Inner:       fmt.Errorf("build command didn't create %s", filepath.Join("bin", "main.wasm")),
+			Remediation: "Change the build command in the [scripts] section of fastly.toml to write the Wasm binary to bin/main.wasm.",
+		}
+	}
+
+	return nil
+}
+
+// errBuildScriptMissing returns the error for a package of language "other"
+// which has no build command.
+func errBuildScriptMissing() error {
+	return errors.RemediationError{
+		Inner: fmt.Errorf("build command not found in %s", manifest.Filename),
+		Remediation: fmt.Sprintf("To fix this error, add the command which compiles the package to bin/main.wasm to %s, for example:\n\n\t%s\n\t%s",
+			manifest.Filename, text.Bold("[scripts]"), text.Bold(`build = "tinygo build -target=wasi -o bin/main.wasm ."`)),
+	}
+}
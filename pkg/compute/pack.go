@@ -11,14 +11,14 @@ import (
 	"github.com/fastly/cli/pkg/errors"
 	"github.com/fastly/cli/pkg/filesystem"
 	"github.com/fastly/cli/pkg/text"
-	"github.com/mholt/archiver/v3"
 )
 
 // PackCommand takes a .wasm and builds the required tar/gzip package ready to be uploaded.
 type PackCommand struct {
 	cmd.Base
-	manifest manifest.Data
-	path     string
+	manifest     manifest.Data
+	path         string
+	includeBuild bool
 }
 
 // NewPackCommand returns a usable command registered under the parent.
@@ -31,6 +31,7 @@ func NewPackCommand(parent cmd.Registerer, globals *config.Data) *PackCommand {
 
 	c.CmdClause = parent.Command("pack", "Package a pre-compiled Wasm binary for a Fastly Compute@Edge service")
 	c.CmdClause.Flag("path", "Path to a pre-compiled Wasm binary").Short('p').Required().StringVar(&c.path)
+	c.CmdClause.Flag("include-build-output", "Also package the other files in bin, unless they're ignored by .fastlyignore").BoolVar(&c.includeBuild)
 
 	return &c
 }
@@ -102,14 +103,33 @@ func (c *PackCommand) Exec(in io.Reader, out io.Writer) (err error) {
 		return fmt.Errorf("error copying manifest to '%s': %w", dst, err)
 	}
 
+	// Only the Wasm binary and manifest are packaged, unless the rest of the
+	// build output in bin is asked for, as packaged by compute build.
+	var files []string
+	if c.includeBuild {
+		ignore, err := ReadIgnoreFile(IgnoreFilePath)
+		if err != nil {
+			c.Globals.ErrLog.Add(err)
+			return err
+		}
+		files, err = GetNonIgnoredFiles("bin", ignore)
+		if err != nil {
+			c.Globals.ErrLog.Add(err)
+			return err
+		}
+	}
+
 	progress.Step("Creating .tar.gz file...")
-	tar := archiver.NewTarGz()
-	tar.OverwriteExisting = true
 	{
 		dir := fmt.Sprintf("pkg/%s", c.manifest.File.Name)
-		src := []string{dir}
+		sources := make(map[string]string, len(files)+2)
+		for _, f := range files {
+			sources[filepath.ToSlash(f)] = f
+		}
+		sources["bin/main.wasm"] = pkg
+		sources[manifest.Filename] = dst
 		dst := fmt.Sprintf("%s.tar.gz", dir)
-		if err = tar.Archive(src, dst); err != nil {
+		if err = createPackageArchive(sources, dst, nil); err != nil {
 			c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
 				"Tar source":      dir,
 				"Tar destination": dst,
@@ -23,6 +23,7 @@ type PublishCommand struct {
 	backendPort    cmd.OptionalUint
 	serviceVersion cmd.OptionalServiceVersion
 	comment        cmd.OptionalString
+	rollback       cmd.OptionalBool
 
 	// Build fields
 	name       cmd.OptionalString
@@ -30,6 +31,7 @@ type PublishCommand struct {
 	includeSrc cmd.OptionalBool
 	force      cmd.OptionalBool
 	timeout    cmd.OptionalInt
+	listFiles  cmd.OptionalBool
 }
 
 // NewPublishCommand returns a usable command registered under the parent.
@@ -48,6 +50,7 @@ func NewPublishCommand(parent cmd.Registerer, globals *config.Data, build *Build
 	c.CmdClause.Flag("include-source", "Include source code in built package").Action(c.includeSrc.Set).BoolVar(&c.includeSrc.Value)
 	c.CmdClause.Flag("force", "Skip verification steps and force build").Action(c.force.Set).BoolVar(&c.force.Value)
 	c.CmdClause.Flag("timeout", "Timeout, in seconds, for the build compilation step").Action(c.timeout.Set).IntVar(&c.timeout.Value)
+	c.CmdClause.Flag("list-files", "List the files the package would contain, and why others are excluded, without building or deploying it").Action(c.listFiles.Set).BoolVar(&c.listFiles.Value)
 
 	// Deploy flags
 	c.RegisterServiceIDFlag(&c.manifest.Flag.ServiceID)
@@ -61,6 +64,7 @@ func NewPublishCommand(parent cmd.Registerer, globals *config.Data, build *Build
 	c.CmdClause.Flag("backend", "A hostname, IPv4, or IPv6 address for the package backend").Action(c.backend.Set).StringVar(&c.backend.Value)
 	c.CmdClause.Flag("backend-port", "A port number for the package backend").Action(c.backendPort.Set).UintVar(&c.backendPort.Value)
 	c.CmdClause.Flag("comment", "Human-readable comment").Action(c.comment.Set).StringVar(&c.comment.Value)
+	c.CmdClause.Flag("rollback-incomplete", "Undo the changes made by a previous deploy which was interrupted").Action(c.rollback.Set).BoolVar(&c.rollback.Value)
 
 	return &c
 }
@@ -73,6 +77,12 @@ func NewPublishCommand(parent cmd.Registerer, globals *config.Data, build *Build
 // non-deterministic ways. It's best to leave those nested commands to handle
 // the progress indicator.
 func (c *PublishCommand) Exec(in io.Reader, out io.Writer) (err error) {
+	// Rolling back an interrupted deploy doesn't need a package to be built.
+	if c.rollback.WasSet && c.rollback.Value {
+		c.deploy.RollbackIncomplete = true
+		return c.deploy.Exec(in, out)
+	}
+
 	// Reset the fields on the BuildCommand based on PublishCommand values.
 	if c.name.WasSet {
 		c.build.PackageName = c.name.Value
@@ -89,6 +99,9 @@ func (c *PublishCommand) Exec(in io.Reader, out io.Writer) (err error) {
 	if c.timeout.WasSet {
 		c.build.Timeout = c.timeout.Value
 	}
+	if c.listFiles.WasSet {
+		c.build.ListFiles = c.listFiles.Value
+	}
 
 	err = c.build.Exec(in, out)
 	if err != nil {
@@ -96,6 +109,11 @@ func (c *PublishCommand) Exec(in io.Reader, out io.Writer) (err error) {
 		return err
 	}
 
+	// Nothing was built, so there's nothing to deploy.
+	if c.build.ListFiles {
+		return nil
+	}
+
 	text.Break(out)
 
 	// Reset the fields on the DeployCommand based on PublishCommand values.
@@ -312,17 +312,8 @@ func (r *Rust) Build(out io.Writer, verbose bool) error {
 	}
 	binName := m.Package.Name
 
-	if r.toolchain == nil {
-		rustConstraint, err := semver.NewConstraint(r.config.File.Language.Rust.ToolchainConstraint)
-		if err != nil {
-			return fmt.Errorf("error parsing rust toolchain constraint: %w", err)
-		}
-
-		// Side-effect: sets r.toolchain
-		err = r.toolchainVersion(rustConstraint)
-		if err != nil {
-			return err
-		}
+	if err := r.resolveToolchain(); err != nil {
+		return err
 	}
 
 	toolchain := fmt.Sprintf("+%s", r.toolchain.String())
@@ -391,6 +382,44 @@ func (r *Rust) Build(out io.Writer, verbose bool) error {
 	return nil
 }
 
+// Versions implements the ToolchainVersioner interface and returns the
+// versions of the Rust compiler and the fastly crate. The compiler is the
+// toolchain the package is built with, whether or not Verify was run.
+func (r *Rust) Versions() map[string]string {
+	versions := make(map[string]string)
+
+	if err := r.resolveToolchain(); err == nil {
+		if v := commandOutput("rustc", fmt.Sprintf("+%s", r.toolchain.String()), "--version"); v != "" {
+			versions["rustc"] = v
+		}
+	}
+
+	var metadata CargoMetadata
+	if err := metadata.Read(); err == nil {
+		if v, err := GetCrateVersionFromMetadata(metadata, "fastly"); err == nil {
+			versions["fastly"] = v.String()
+		}
+	}
+
+	return versions
+}
+
+// resolveToolchain sets the toolchain the package is built with, if Verify
+// hasn't already.
+func (r *Rust) resolveToolchain() error {
+	if r.toolchain != nil {
+		return nil
+	}
+
+	rustConstraint, err := semver.NewConstraint(r.config.File.Language.Rust.ToolchainConstraint)
+	if err != nil {
+		return fmt.Errorf("error parsing rust toolchain constraint: %w", err)
+	}
+
+	// Side-effect: sets r.toolchain
+	return r.toolchainVersion(rustConstraint)
+}
+
 func (r *Rust) toolchainVersion(rustConstraint *semver.Constraints) error {
 	cmd := exec.Command("rustup", "toolchain", "list")
 	stdoutStderr, err := cmd.CombinedOutput()
@@ -6,9 +6,12 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/fastly/cli/pkg/cmd"
@@ -36,6 +39,7 @@ type ServeCommand struct {
 	name             cmd.OptionalString
 	skipBuild        bool
 	viceroyVersioner update.Versioner
+	watch            bool
 }
 
 // NewServeCommand returns a usable command registered under the parent.
@@ -59,12 +63,20 @@ func NewServeCommand(parent cmd.Registerer, globals *config.Data, build *BuildCo
 	c.CmdClause.Flag("language", "Language type").Action(c.lang.Set).StringVar(&c.lang.Value)
 	c.CmdClause.Flag("name", "Package name").Action(c.name.Set).StringVar(&c.name.Value)
 	c.CmdClause.Flag("skip-build", "Skip the build step").BoolVar(&c.skipBuild)
+	c.CmdClause.Flag("watch", "Rebuild the package and restart the local server when its files change").BoolVar(&c.watch)
 
 	return &c
 }
 
 // Exec implements the command interface.
 func (c *ServeCommand) Exec(in io.Reader, out io.Writer) (err error) {
+	if c.watch && c.skipBuild {
+		return errors.RemediationError{
+			Inner:       fmt.Errorf("--watch can't be used with --skip-build"),
+			Remediation: "Remove the --skip-build flag, as --watch rebuilds the package when it changes.",
+		}
+	}
+
 	if !c.skipBuild {
 		// Reset the fields on the BuildCommand based on ServeCommand values.
 		if c.name.WasSet {
@@ -88,6 +100,10 @@ func (c *ServeCommand) Exec(in io.Reader, out io.Writer) (err error) {
 		text.Break(out)
 	}
 
+	if err := c.validateLocalServer(); err != nil {
+		return err
+	}
+
 	var progress text.Progress
 	if c.Globals.Verbose() {
 		progress = text.NewVerboseProgress(out)
@@ -103,6 +119,10 @@ func (c *ServeCommand) Exec(in io.Reader, out io.Writer) (err error) {
 	progress.Step("Running local server...")
 	progress.Done()
 
+	if c.watch {
+		return c.serveAndWatch(bin, in, out)
+	}
+
 	err = local(bin, c.file, progress, out, c.addr, c.env.Value, c.Globals.Verbose())
 	if err != nil {
 		if err == errors.ErrSignalInterrupt || err == errors.ErrSignalKilled {
@@ -270,23 +290,42 @@ func updateViceroy(progress text.Progress, version string, out io.Writer, versio
 	return nil
 }
 
-// local spawns a subprocess that runs the compiled binary.
-func local(bin string, file string, progress text.Progress, out io.Writer, addr string, env string, verbose bool) error {
-	if env != "" {
-		env = "." + env
-	}
-
-	wd, err := os.Getwd()
+// validateLocalServer checks the [local_server] section of the manifest
+// Viceroy is run with, so that mistakes in the mocked backends, dictionaries
+// and geolocation data are reported before the server starts.
+func (c *ServeCommand) validateLocalServer() error {
+	fpath, err := localManifest(c.env.Value)
 	if err != nil {
 		return err
 	}
 
-	manifest := filepath.Join(wd, fmt.Sprintf("fastly%s.toml", env))
-	args := []string{"-C", manifest, "--addr", addr, file}
+	var m manifest.File
+	m.SetOutput(c.Globals.Output)
+	if err := m.Read(fpath); err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Manifest": fpath,
+		})
+		return fmt.Errorf("error reading package manifest: %w", err)
+	}
 
-	if verbose {
-		text.Output(out, "Wasm file: %s", file)
-		text.Output(out, "Manifest: %s", manifest)
+	if err := m.LocalServer.Validate(filepath.Dir(fpath)); err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Manifest": fpath,
+		})
+		return errors.RemediationError{
+			Inner:       err,
+			Remediation: fmt.Sprintf("Fix the [local_server] section of %s. Refer to the fastly.toml package manifest format: %s", filepath.Base(fpath), manifest.SpecURL),
+		}
+	}
+
+	return nil
+}
+
+// local spawns a subprocess that runs the compiled binary.
+func local(bin string, file string, progress text.Progress, out io.Writer, addr string, env string, verbose bool) error {
+	args, err := localArgs(file, out, addr, env, verbose)
+	if err != nil {
+		return err
 	}
 
 	cmd := fstexec.Streaming{
@@ -300,15 +339,235 @@ func local(bin string, file string, progress text.Progress, out io.Writer, addr
 	text.Break(out)
 
 	if err := cmd.Exec(); err != nil {
-		e := strings.TrimSpace(err.Error())
-		if strings.Contains(e, "interrupt") {
-			return errors.ErrSignalInterrupt
-		}
-		if strings.Contains(e, "killed") {
-			return errors.ErrSignalKilled
-		}
-		return err
+		return signalError(err)
 	}
 
 	return nil
 }
+
+// localArgs returns the arguments Viceroy runs the compiled binary with.
+func localArgs(file string, out io.Writer, addr string, env string, verbose bool) ([]string, error) {
+	manifest, err := localManifest(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if verbose {
+		text.Output(out, "Wasm file: %s", file)
+		text.Output(out, "Manifest: %s", manifest)
+	}
+
+	return []string{"-C", manifest, "--addr", addr, file}, nil
+}
+
+// localManifest returns the path of the manifest for the environment, e.g.
+// fastly.stage.toml, or fastly.toml when there's no environment.
+func localManifest(env string) (string, error) {
+	if env != "" {
+		env = "." + env
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(wd, fmt.Sprintf("fastly%s.toml", env)), nil
+}
+
+// signalError returns the error of a subprocess, or the signal error if it was
+// interrupted or killed.
+func signalError(err error) error {
+	e := strings.TrimSpace(err.Error())
+	if strings.Contains(e, "interrupt") {
+		return errors.ErrSignalInterrupt
+	}
+	if strings.Contains(e, "killed") {
+		return errors.ErrSignalKilled
+	}
+	return err
+}
+
+// watchInterval is how often the package files are polled for changes, and
+// watchDebounce is how long they must be unchanged before rebuilding, so that
+// a burst of changes (e.g. a branch checkout) only rebuilds once.
+//
+// NOTE: These are package level variables so that the test code can shorten
+// them.
+var (
+	watchInterval = 500 * time.Millisecond
+	watchDebounce = time.Second
+)
+
+// stopTimeout is how long the local server is given to stop gracefully
+// before it's killed.
+const stopTimeout = 5 * time.Second
+
+// serveAndWatch runs the local server, rebuilding the package and restarting
+// the server whenever the language's source files change. A failed build is
+// reported and the server keeps running the previous build.
+func (c *ServeCommand) serveAndWatch(bin string, in io.Reader, out io.Writer) error {
+	var m manifest.File
+	m.SetOutput(c.Globals.Output)
+	if err := m.Read(manifest.Filename); err != nil {
+		c.Globals.ErrLog.Add(err)
+		return fmt.Errorf("error reading package manifest: %w", err)
+	}
+
+	language, err := c.build.Language(m)
+	if err != nil {
+		return err
+	}
+
+	paths := append([]string{manifest.Filename, language.SourceDirectory}, language.IncludeFiles...)
+	w, err := newWatcher(paths)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Paths": paths,
+		})
+		return fmt.Errorf("error watching package files: %w", err)
+	}
+
+	args, err := localArgs(c.file, out, c.addr, c.env.Value, c.Globals.Verbose())
+	if err != nil {
+		return err
+	}
+	server := &localServer{bin: bin, args: args, out: out}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sig)
+
+	text.Break(out)
+	text.Info(out, "Watching %s for changes", strings.Join(paths, ", "))
+	text.Break(out)
+	server.start()
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	// lastChange is when a change was last seen, and is zero when there are
+	// no changes waiting for a rebuild.
+	var lastChange time.Time
+
+	for {
+		select {
+		case <-sig:
+			server.stop()
+			text.Break(out)
+			text.Info(out, "Local server stopped")
+			return nil
+
+		case err := <-server.done:
+			server.done = nil
+			if err == nil {
+				text.Warning(out, "Local server exited. It will restart when the package is rebuilt.")
+				continue
+			}
+			if err = signalError(err); err == errors.ErrSignalInterrupt || err == errors.ErrSignalKilled {
+				text.Break(out)
+				text.Info(out, "Local server stopped")
+				return nil
+			}
+			text.Warning(out, "Local server exited: %s. It will restart when the package is rebuilt.", err)
+
+		case <-ticker.C:
+			changed, err := w.changed()
+			if err != nil {
+				c.Globals.ErrLog.Add(err)
+				text.Warning(out, "Error watching package files: %s", err)
+				continue
+			}
+			if len(changed) > 0 {
+				lastChange = time.Now()
+				if c.Globals.Verbose() {
+					text.Output(out, "Changed: %s", strings.Join(changed, ", "))
+				}
+				continue
+			}
+			if lastChange.IsZero() || time.Since(lastChange) < watchDebounce {
+				continue
+			}
+			lastChange = time.Time{}
+
+			text.Break(out)
+			text.Info(out, "Package files changed, rebuilding...")
+			if err := c.build.Exec(in, out); err != nil {
+				printRebuildError(out, err)
+				continue
+			}
+
+			if err := c.validateLocalServer(); err != nil {
+				printRebuildError(out, err)
+				continue
+			}
+
+			text.Break(out)
+			text.Info(out, "Restarting local server...")
+			server.stop()
+			text.Break(out)
+			server.start()
+		}
+	}
+}
+
+// printRebuildError reports a failed rebuild without stopping the watcher.
+func printRebuildError(out io.Writer, err error) {
+	text.Break(out)
+	errors.Deduce(err).Print(out)
+	text.Break(out)
+	text.Warning(out, "The local server is still running the previous build. Waiting for changes...")
+}
+
+// localServer is a Viceroy process which can be restarted.
+type localServer struct {
+	bin  string
+	args []string
+	out  io.Writer
+
+	cmd *fstexec.Streaming
+	// done receives the result of the process when it exits, and is nil when
+	// the process isn't running.
+	done chan error
+}
+
+// start runs the server in the background.
+func (s *localServer) start() {
+	s.cmd = &fstexec.Streaming{
+		Command: s.bin,
+		Args:    s.args,
+		Env:     os.Environ(),
+		Output:  s.out,
+	}
+	done := make(chan error, 1)
+	go func(cmd *fstexec.Streaming) {
+		done <- cmd.Exec()
+	}(s.cmd)
+	s.done = done
+}
+
+// stop interrupts the server and waits for it to exit, killing it if it
+// doesn't exit within stopTimeout.
+func (s *localServer) stop() {
+	if s.done == nil {
+		return
+	}
+	defer func() { s.done = nil }()
+
+	// Windows doesn't support sending interrupts to processes.
+	sig := os.Interrupt
+	if runtime.GOOS == "windows" {
+		sig = os.Kill
+	}
+	_ = s.cmd.Signal(sig)
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-time.After(stopTimeout):
+			// The process may not have started when it was interrupted.
+			_ = s.cmd.Signal(os.Kill)
+		}
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/fastly/cli/pkg/mock"
 	"github.com/fastly/cli/pkg/text"
@@ -97,4 +99,118 @@ func makeEnvironment(downloadedFilename string, t *testing.T) (string, string, s
 	return downloadDir, installDir, fpath
 }
 
+// TestWatcher validates that the watcher reports created, modified and deleted
+// files, except those matched by the Fastly ignore file.
+func TestWatcher(t *testing.T) {
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootdir, err := os.MkdirTemp("", "fastly-watch-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootdir)
+
+	if err := os.Chdir(rootdir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(pwd)
+
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("Cargo.toml", "[package]")
+	write(filepath.Join("src", "main.rs"), "fn main() {}")
+	write(IgnoreFilePath, filepath.Join("src", "*.swp"))
+
+	w, err := newWatcher([]string{"src", "Cargo.toml", "missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, step := range []struct {
+		name   string
+		change func()
+		want   []string
+	}{
+		{
+			name:   "no changes",
+			change: func() {},
+		},
+		{
+			name:   "modified",
+			change: func() { write(filepath.Join("src", "main.rs"), "fn main() { todo!() }") },
+			want:   []string{filepath.Join("src", "main.rs")},
+		},
+		{
+			name: "created",
+			change: func() {
+				write(filepath.Join("src", "lib", "lib.rs"), "")
+				write(filepath.Join("missing", "file"), "")
+			},
+			want: []string{filepath.Join("missing", "file"), filepath.Join("src", "lib", "lib.rs")},
+		},
+		{
+			name:   "ignored",
+			change: func() { write(filepath.Join("src", ".main.rs.swp"), "") },
+		},
+		{
+			name: "deleted",
+			change: func() {
+				if err := os.Remove("Cargo.toml"); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: []string{"Cargo.toml"},
+		},
+	} {
+		step.change()
+		have, err := w.changed()
+		if err != nil {
+			t.Fatalf("%s: %s", step.name, err)
+		}
+		if strings.Join(have, ",") != strings.Join(step.want, ",") {
+			t.Fatalf("%s: want changes %v, have %v", step.name, step.want, have)
+		}
+	}
+}
+
+// TestLocalServerStop validates that a restartable local server is stopped.
+func TestLocalServerStop(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the local server is stopped with an interrupt")
+	}
+
+	var out bytes.Buffer
+	s := &localServer{bin: "sleep", args: []string{"60"}, out: &out}
+	s.start()
+	time.Sleep(100 * time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		s.stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(stopTimeout):
+		t.Fatal("local server wasn't stopped")
+	}
+
+	if s.done != nil {
+		t.Fatal("want the local server to be marked stopped")
+	}
+
+	// Stopping a stopped server is a no-op.
+	s.stop()
+}
+
 // TODO: Write tests for the other functions in serve.go
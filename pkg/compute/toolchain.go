@@ -0,0 +1,78 @@
+package compute
+
+import (
+	"sort"
+
+	"github.com/fastly/cli/pkg/api"
+	"github.com/fastly/cli/pkg/compute/manifest"
+	"github.com/fastly/cli/pkg/config"
+)
+
+// ToolchainOptions models the values a Language is constructed from when
+// building a package.
+type ToolchainOptions struct {
+	Client   api.HTTPClient
+	Globals  *config.Data
+	Manifest manifest.File
+	Timeout  int
+}
+
+// LanguageConstructor returns the Language a package is built with.
+type LanguageConstructor func(opts ToolchainOptions) *Language
+
+// toolchains maps the name of each supported language to its constructor.
+var toolchains = map[string]LanguageConstructor{
+	"assemblyscript": func(opts ToolchainOptions) *Language {
+		return NewLanguage(&LanguageOptions{
+			Name:            "assemblyscript",
+			SourceDirectory: "assembly",
+			IncludeFiles:    []string{"package.json"},
+			Toolchain:       NewAssemblyScript(opts.Timeout),
+		})
+	},
+	"rust": func(opts ToolchainOptions) *Language {
+		return NewLanguage(&LanguageOptions{
+			Name:            "rust",
+			SourceDirectory: "src",
+			IncludeFiles:    []string{"Cargo.toml"},
+			Toolchain:       NewRust(opts.Client, opts.Globals, opts.Timeout),
+		})
+	},
+	"other": func(opts ToolchainOptions) *Language {
+		src := opts.Manifest.Scripts.SourceDirectory
+		if src == "" {
+			src = "src"
+		}
+		return NewLanguage(&LanguageOptions{
+			Name:            "other",
+			SourceDirectory: src,
+			Toolchain:       NewOther(opts.Manifest.Scripts.Build, opts.Timeout),
+		})
+	},
+}
+
+// RegisterToolchain makes a language available to the build command. A
+// language registered under an existing name replaces it.
+func RegisterToolchain(name string, fn LanguageConstructor) {
+	toolchains[name] = fn
+}
+
+// NewToolchainLanguage returns the registered Language of the given name, or
+// false if there isn't one.
+func NewToolchainLanguage(name string, opts ToolchainOptions) (*Language, bool) {
+	fn, ok := toolchains[name]
+	if !ok {
+		return nil, false
+	}
+	return fn(opts), true
+}
+
+// ToolchainNames returns the sorted names of the registered languages.
+func ToolchainNames() []string {
+	names := make([]string, 0, len(toolchains))
+	for name := range toolchains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
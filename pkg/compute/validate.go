@@ -1,10 +1,14 @@
 package compute
 
 import (
+	"archive/tar"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/fastly/cli/pkg/cmd"
 	"github.com/fastly/cli/pkg/config"
@@ -17,17 +21,20 @@ import (
 // if successful, it then iterates through (streams) each file in the archive
 // checking the filename against a list of required files. If one of the files
 // doesn't exist it returns an error.
-func validate(path string) error {
+//
+// If the package has build info it's returned, once the hashes of the files
+// it lists have been checked against the files in the package.
+func validate(path string) (*BuildInfo, error) {
 	file, err := os.Open(filepath.Clean(path))
 	if err != nil {
-		return fmt.Errorf("error reading package: %w", err)
+		return nil, fmt.Errorf("error reading package: %w", err)
 	}
 	defer file.Close() // #nosec G307
 
 	tar := archiver.NewTarGz()
 	err = tar.Open(file, 0)
 	if err != nil {
-		return fmt.Errorf("error unarchiving package: %w", err)
+		return nil, fmt.Errorf("error unarchiving package: %w", err)
 	}
 	defer tar.Close()
 
@@ -36,13 +43,18 @@ func validate(path string) error {
 		"main.wasm":   false,
 	}
 
+	// Hashes of the files in the package, by their path within the package
+	// directory.
+	hashes := make(map[string]string)
+	var info *BuildInfo
+
 	for {
 		f, err := tar.Read()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return fmt.Errorf("error reading package: %w", err)
+			return nil, fmt.Errorf("error reading package: %w", err)
 		}
 
 		for k := range files {
@@ -51,19 +63,69 @@ func validate(path string) error {
 			}
 		}
 
+		if name, ok := packagePath(f); ok {
+			if name == BuildInfoFilename {
+				info, err = ReadBuildInfo(f)
+			} else {
+				h := sha256.New()
+				_, err = io.Copy(h, f)
+				hashes[name] = fmt.Sprintf("%x", h.Sum(nil))
+			}
+			if err != nil {
+				f.Close()
+				return nil, fmt.Errorf("error reading package: %w", err)
+			}
+		}
+
 		err = f.Close()
 		if err != nil {
-			return fmt.Errorf("error closing package: %w", err)
+			return nil, fmt.Errorf("error closing package: %w", err)
 		}
 	}
 
 	for k, found := range files {
 		if !found {
-			return fmt.Errorf("error validating package: package must contain a %s file", k)
+			return nil, fmt.Errorf("error validating package: package must contain a %s file", k)
 		}
 	}
 
-	return nil
+	if info != nil {
+		for _, name := range sortedKeys(info.Files) {
+			hash, ok := hashes[name]
+			if !ok {
+				return nil, fmt.Errorf("error validating package: %s lists %s, which the package doesn't contain", BuildInfoFilename, name)
+			}
+			if hash != info.Files[name] {
+				return nil, fmt.Errorf("error validating package: %s doesn't match the hash in %s", name, BuildInfoFilename)
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// packagePath returns the path of a regular file in a package archive,
+// relative to the package's top-level directory.
+func packagePath(f archiver.File) (string, bool) {
+	h, ok := f.Header.(*tar.Header)
+	if !ok || h.Typeflag != tar.TypeReg {
+		return "", false
+	}
+	segs := strings.SplitN(strings.TrimPrefix(h.Name, "./"), "/", 2)
+	if len(segs) < 2 {
+		return "", false
+	}
+	return segs[1], true
+}
+
+// sortedKeys returns the keys of a map sorted.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 // ValidateCommand validates a package archive.
@@ -91,13 +153,36 @@ func (c *ValidateCommand) Exec(in io.Reader, out io.Writer) error {
 		return fmt.Errorf("error reading file path: %w", err)
 	}
 
-	if err := validate(p); err != nil {
+	info, err := validate(p)
+	if err != nil {
 		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
 			"Path": c.path,
 		})
 		return err
 	}
 
+	if info != nil {
+		printBuildInfo(out, info)
+	}
+
 	text.Success(out, "Validated package %s", p)
 	return nil
 }
+
+// printBuildInfo displays the build info of a package.
+func printBuildInfo(out io.Writer, info *BuildInfo) {
+	fmt.Fprintf(out, "Language: %s\n", info.Language)
+	if info.GitSHA != "" {
+		fmt.Fprintf(out, "Git SHA: %s\n", info.GitSHA)
+	}
+	if len(info.Toolchain) > 0 {
+		fmt.Fprintf(out, "Toolchain:\n")
+		for _, name := range sortedKeys(info.Toolchain) {
+			fmt.Fprintf(out, "\t%s: %s\n", name, info.Toolchain[name])
+		}
+	}
+	fmt.Fprintf(out, "Files:\n")
+	for _, name := range sortedKeys(info.Files) {
+		fmt.Fprintf(out, "\t%s  %s\n", info.Files[name], name)
+	}
+}
@@ -0,0 +1,98 @@
+package compute
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// fileState is the state of a watched file when it was last polled.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// watcher polls the files of a package for changes. Files matched by the
+// Fastly ignore file aren't watched.
+type watcher struct {
+	paths []string
+	files map[string]fileState
+}
+
+// newWatcher returns a watcher of the files and directories, recording their
+// current state. Paths which don't exist are watched for their creation.
+func newWatcher(paths []string) (*watcher, error) {
+	w := &watcher{paths: paths}
+	files, err := w.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	w.files = files
+	return w, nil
+}
+
+// changed returns the sorted paths of the files which have been created,
+// modified or deleted since it was last called.
+func (w *watcher) changed() ([]string, error) {
+	files, err := w.snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for path, state := range files {
+		if prev, ok := w.files[path]; !ok || prev != state {
+			changed = append(changed, path)
+		}
+	}
+	for path := range w.files {
+		if _, ok := files[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+
+	w.files = files
+	return changed, nil
+}
+
+// snapshot returns the state of every watched file which isn't ignored.
+func (w *watcher) snapshot() (map[string]fileState, error) {
+	ignore, err := ReadIgnoreFile(IgnoreFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]fileState)
+	for _, base := range w.paths {
+		err := filepath.Walk(base, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				// Files may be deleted or created while walking.
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			ignored, _ := ignore.Match(path, info.IsDir())
+			if info.IsDir() {
+				if ignored && path != base {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if ignored {
+				return nil
+			}
+			files[path] = fileState{
+				modTime: info.ModTime(),
+				size:    info.Size(),
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
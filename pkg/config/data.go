@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -100,6 +101,35 @@ type Data struct {
 	RTSClient api.RealtimeStatsInterface
 }
 
+// Profile yields the name of the configuration profile in use.
+//
+// An explicit profile (via flag or env var) takes priority over whichever
+// profile is marked as the default in the config file.
+func (d *Data) Profile() (string, Source) {
+	if d.Flag.Profile != "" {
+		return d.Flag.Profile, SourceFlag
+	}
+
+	if d.Env.Profile != "" {
+		return d.Env.Profile, SourceEnvironment
+	}
+
+	if name, _ := d.File.DefaultProfile(); name != "" {
+		return name, SourceFile
+	}
+
+	return "", SourceUndefined
+}
+
+// activeProfile returns the profile data for the profile in use, if any.
+func (d *Data) activeProfile() *Profile {
+	name, _ := d.Profile()
+	if p, ok := d.File.Profiles[name]; ok {
+		return p
+	}
+	return nil
+}
+
 // Token yields the Fastly API token.
 func (d *Data) Token() (string, Source) {
 	if d.Flag.Token != "" {
@@ -110,6 +140,10 @@ func (d *Data) Token() (string, Source) {
 		return d.Env.Token, SourceEnvironment
 	}
 
+	if p := d.activeProfile(); p != nil && p.Token != "" {
+		return p.Token, SourceFile
+	}
+
 	if d.File.User.Token != "" {
 		return d.File.User.Token, SourceFile
 	}
@@ -117,6 +151,14 @@ func (d *Data) Token() (string, Source) {
 	return "", SourceUndefined
 }
 
+// Email yields the email address associated with the profile in use.
+func (d *Data) Email() string {
+	if p := d.activeProfile(); p != nil && p.Email != "" {
+		return p.Email
+	}
+	return d.File.User.Email
+}
+
 // Verbose yields the verbose flag, which can only be set via flags.
 func (d *Data) Verbose() bool {
 	return d.Flag.Verbose
@@ -132,6 +174,10 @@ func (d *Data) Endpoint() (string, Source) {
 		return d.Env.Endpoint, SourceEnvironment
 	}
 
+	if p := d.activeProfile(); p != nil && p.Endpoint != "" {
+		return p.Endpoint, SourceFile
+	}
+
 	if d.File.Fastly.APIEndpoint != DefaultEndpoint && d.File.Fastly.APIEndpoint != "" {
 		return d.File.Fastly.APIEndpoint, SourceFile
 	}
@@ -173,10 +219,15 @@ type File struct {
 	ConfigVersion int                 `toml:"config_version"`
 	Fastly        Fastly              `toml:"fastly"`
 	CLI           CLI                 `toml:"cli"`
-	User          User                `toml:"user"`
+	Profiles      Profiles            `toml:"profiles"`
 	Language      Language            `toml:"language"`
 	StarterKits   StarterKitLanguages `toml:"starter-kits"`
 
+	// User is the single set of credentials used before profiles existed.
+	//
+	// DEPRECATED in favour of Profiles (see migrateLegacyData).
+	User User `toml:"user,omitempty"`
+
 	// We store off a possible legacy configuration so that we can later extract
 	// the relevant email and token values that may pre-exist.
 	Legacy LegacyFile `toml:"legacy"`
@@ -188,7 +239,7 @@ type File struct {
 
 // Fastly represents fastly specific configuration.
 type Fastly struct {
-	APIEndpoint string `toml:"api_endpoint"`
+	APIEndpoint string `toml:"api_endpoint,omitempty"`
 }
 
 // CLI represents CLI specific configuration.
@@ -205,6 +256,56 @@ type User struct {
 	Email string `toml:"email"`
 }
 
+// DefaultProfileName is the name given to the profile created when no other
+// profile exists, such as when migrating the credentials stored in the
+// deprecated [user] section.
+const DefaultProfileName = "user"
+
+// Profiles represents a set of named user profiles.
+type Profiles map[string]*Profile
+
+// Profile represents a named set of credentials and API endpoint.
+type Profile struct {
+	Default  bool   `toml:"default"`
+	Email    string `toml:"email"`
+	Endpoint string `toml:"api_endpoint,omitempty"`
+	Token    string `toml:"token"`
+}
+
+// DefaultProfile returns the name and data of the profile marked as the
+// default, if one exists.
+func (f *File) DefaultProfile() (string, *Profile) {
+	for _, name := range f.ProfileNames() {
+		if p := f.Profiles[name]; p.Default {
+			return name, p
+		}
+	}
+	return "", nil
+}
+
+// ProfileNames returns the names of all profiles in sorted order.
+func (f *File) ProfileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetDefaultProfile marks the named profile as the default, unsetting the
+// default flag on all other profiles. It returns false if the profile doesn't
+// exist.
+func (f *File) SetDefaultProfile(name string) bool {
+	if _, ok := f.Profiles[name]; !ok {
+		return false
+	}
+	for n, p := range f.Profiles {
+		p.Default = n == name
+	}
+	return true
+}
+
 // Language represents C@E language specific configuration.
 type Language struct {
 	Rust Rust `toml:"rust"`
@@ -295,6 +396,10 @@ func (f *File) Load(configEndpoint string, c api.HTTPClient, d time.Duration, fp
 }
 
 // migrateLegacyData ensures legacy data is transitioned to config new format.
+//
+// Credentials from the original top-level format are first moved into the
+// [user] section, and the [user] section is then moved into a profile (marked
+// as the default profile if no other profile claims to be the default).
 func migrateLegacyData(f *File) {
 	if f.Legacy.Token != "" && f.User.Token == "" {
 		f.User.Token = f.Legacy.Token
@@ -302,6 +407,23 @@ func migrateLegacyData(f *File) {
 	if f.Legacy.Email != "" && f.User.Email == "" {
 		f.User.Email = f.Legacy.Email
 	}
+	f.Legacy = LegacyFile{}
+
+	if f.User.Token == "" && f.User.Email == "" {
+		return
+	}
+	if f.Profiles == nil {
+		f.Profiles = make(Profiles)
+	}
+	if _, ok := f.Profiles[DefaultProfileName]; !ok {
+		name, _ := f.DefaultProfile()
+		f.Profiles[DefaultProfileName] = &Profile{
+			Default: name == "",
+			Email:   f.User.Email,
+			Token:   f.User.Token,
+		}
+	}
+	f.User = User{}
 }
 
 // createConfigDir creates the application configuration directory if it
@@ -394,6 +516,8 @@ func (f *File) Read(fpath string, in io.Reader, out io.Writer) error {
 		f.CLI.Version = revision.SemVer(revision.AppVersion)
 	}
 
+	migrateLegacyData(f)
+
 	err := createConfigDir(fpath)
 	if err != nil {
 		return err
@@ -479,12 +603,14 @@ func (f *File) Write(fpath string) error {
 type Environment struct {
 	Token    string
 	Endpoint string
+	Profile  string
 }
 
 // Read populates the fields from the provided environment.
 func (e *Environment) Read(state map[string]string) {
 	e.Token = state[env.Token]
 	e.Endpoint = state[env.Endpoint]
+	e.Profile = state[env.Profile]
 }
 
 // Flag represents all of the configuration parameters that can be set with
@@ -494,6 +620,8 @@ type Flag struct {
 	Token    string
 	Verbose  bool
 	Endpoint string
+	Profile  string
+	Format   string
 }
 
 // This suggests our embedded config is unexpectedly faulty and so we should
@@ -259,11 +259,18 @@ func TestUseStatic(t *testing.T) {
 			if f.CLI.LastChecked == "" || f.CLI.Version == "" {
 				t.Fatalf("expected LastChecked/Version to be set: %+v", f)
 			}
-			if f.User.Token != "foobar" {
-				t.Fatalf("wanted token: %s, got: %s", "foobar", f.User.Token)
+			p, ok := f.Profiles[config.DefaultProfileName]
+			if !ok {
+				t.Fatalf("wanted profile: %s, got: %+v", config.DefaultProfileName, f.Profiles)
 			}
-			if f.User.Email != "testing@fastly.com" {
-				t.Fatalf("wanted email: %s, got: %s", "testing@fastly.com", f.User.Email)
+			if p.Token != "foobar" {
+				t.Fatalf("wanted token: %s, got: %s", "foobar", p.Token)
+			}
+			if p.Email != "testing@fastly.com" {
+				t.Fatalf("wanted email: %s, got: %s", "testing@fastly.com", p.Email)
+			}
+			if !p.Default {
+				t.Fatal("wanted migrated profile to be the default")
 			}
 		})
 	}
@@ -420,3 +427,81 @@ func TestValidConfig(t *testing.T) {
 		})
 	}
 }
+
+// TestDataProfile validates the token and endpoint are resolved from the
+// selected profile, and that explicit flags and env vars take priority.
+func TestDataProfile(t *testing.T) {
+	file := config.File{
+		Fastly: config.Fastly{APIEndpoint: config.DefaultEndpoint},
+		Profiles: config.Profiles{
+			"prod": {Default: true, Token: "prod-token"},
+			"staging": {
+				Token:    "staging-token",
+				Endpoint: "https://staging.example.com",
+			},
+		},
+	}
+
+	for _, testcase := range []struct {
+		name         string
+		flag         config.Flag
+		env          config.Environment
+		wantProfile  string
+		wantToken    string
+		wantEndpoint string
+		wantSource   config.Source
+	}{
+		{
+			name:         "default profile",
+			wantProfile:  "prod",
+			wantToken:    "prod-token",
+			wantEndpoint: config.DefaultEndpoint,
+			wantSource:   config.SourceFile,
+		},
+		{
+			name:         "profile from env",
+			env:          config.Environment{Profile: "staging"},
+			wantProfile:  "staging",
+			wantToken:    "staging-token",
+			wantEndpoint: "https://staging.example.com",
+			wantSource:   config.SourceFile,
+		},
+		{
+			name:         "profile from flag takes priority over env",
+			flag:         config.Flag{Profile: "prod"},
+			env:          config.Environment{Profile: "staging"},
+			wantProfile:  "prod",
+			wantToken:    "prod-token",
+			wantEndpoint: config.DefaultEndpoint,
+			wantSource:   config.SourceFile,
+		},
+		{
+			name:         "token flag takes priority over profile",
+			flag:         config.Flag{Profile: "staging", Token: "flag-token"},
+			wantProfile:  "staging",
+			wantToken:    "flag-token",
+			wantEndpoint: "https://staging.example.com",
+			wantSource:   config.SourceFlag,
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			d := config.Data{
+				File: file,
+				Env:  testcase.env,
+				Flag: testcase.flag,
+			}
+
+			name, _ := d.Profile()
+			testutil.AssertString(t, testcase.wantProfile, name)
+
+			token, source := d.Token()
+			testutil.AssertString(t, testcase.wantToken, token)
+			if source != testcase.wantSource {
+				t.Fatalf("want source %d, have %d", testcase.wantSource, source)
+			}
+
+			endpoint, _ := d.Endpoint()
+			testutil.AssertString(t, testcase.wantEndpoint, endpoint)
+		})
+	}
+}
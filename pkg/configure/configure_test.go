@@ -63,7 +63,6 @@ func TestConfigure(t *testing.T) {
   version = ""
 
 [fastly]
-  api_endpoint = "http://local.dev"
 
 [language]
 
@@ -78,11 +77,15 @@ func TestConfigure(t *testing.T) {
   email = ""
   token = ""
 
-[starter-kits]
+[profiles]
+
+  [profiles.user]
+    api_endpoint = "http://local.dev"
+    default = true
+    email = "test@example.com"
+    token = "abcdef"
 
-[user]
-  email = "test@example.com"
-  token = "abcdef"
+[starter-kits]
 `,
 		},
 		{
@@ -111,7 +114,6 @@ func TestConfigure(t *testing.T) {
   version = ""
 
 [fastly]
-  api_endpoint = "http://staging.dev"
 
 [language]
 
@@ -126,11 +128,72 @@ func TestConfigure(t *testing.T) {
   email = ""
   token = ""
 
+[profiles]
+
+  [profiles.user]
+    api_endpoint = "http://staging.dev"
+    default = true
+    email = "test@example.com"
+    token = "abcdef"
+
 [starter-kits]
+`,
+		},
+		{
+			name: "endpoint for another profile leaves the default profile alone",
+			args: args("configure --profile staging --endpoint http://staging.dev --token abcdef"),
+			file: config.File{
+				Fastly: config.Fastly{APIEndpoint: config.DefaultEndpoint},
+				Profiles: config.Profiles{
+					"user": &config.Profile{Default: true, Email: "test@example.com", Token: "123"},
+				},
+			},
+			api: mock.API{
+				GetTokenSelfFn: goodToken,
+				GetUserFn:      goodUser,
+			},
+			wantOutput: []string{
+				"Fastly API endpoint (via --endpoint): http://staging.dev",
+				"Configured the Fastly CLI",
+			},
+			wantFile: `config_version = 0
+
+[cli]
+  last_checked = ""
+  remote_config = ""
+  ttl = ""
+  version = ""
+
+[fastly]
+  api_endpoint = "https://api.fastly.com"
+
+[language]
+
+  [language.rust]
+    fastly_sys_constraint = ""
+    rustup_constraint = ""
+    toolchain_constraint = ""
+    toolchain_version = ""
+    wasm_wasi_target = ""
 
-[user]
-  email = "test@example.com"
-  token = "abcdef"
+[legacy]
+  email = ""
+  token = ""
+
+[profiles]
+
+  [profiles.staging]
+    api_endpoint = "http://staging.dev"
+    default = false
+    email = "test@example.com"
+    token = "abcdef"
+
+  [profiles.user]
+    default = true
+    email = "test@example.com"
+    token = "123"
+
+[starter-kits]
 `,
 		},
 		{
@@ -156,7 +219,6 @@ func TestConfigure(t *testing.T) {
   version = ""
 
 [fastly]
-  api_endpoint = "https://api.fastly.com"
 
 [language]
 
@@ -171,11 +233,14 @@ func TestConfigure(t *testing.T) {
   email = ""
   token = ""
 
-[starter-kits]
+[profiles]
+
+  [profiles.user]
+    default = true
+    email = "test@example.com"
+    token = "abcdef"
 
-[user]
-  email = "test@example.com"
-  token = "abcdef"
+[starter-kits]
 `,
 		},
 		{
@@ -204,7 +269,6 @@ func TestConfigure(t *testing.T) {
   version = ""
 
 [fastly]
-  api_endpoint = "https://api.fastly.com"
 
 [language]
 
@@ -219,11 +283,14 @@ func TestConfigure(t *testing.T) {
   email = ""
   token = ""
 
-[starter-kits]
+[profiles]
+
+  [profiles.user]
+    default = true
+    email = "test@example.com"
+    token = "1234"
 
-[user]
-  email = "test@example.com"
-  token = "1234"
+[starter-kits]
 `,
 		},
 		{
@@ -250,7 +317,6 @@ func TestConfigure(t *testing.T) {
   version = ""
 
 [fastly]
-  api_endpoint = "https://api.fastly.com"
 
 [language]
 
@@ -265,11 +331,14 @@ func TestConfigure(t *testing.T) {
   email = ""
   token = ""
 
-[starter-kits]
+[profiles]
+
+  [profiles.user]
+    default = true
+    email = "test@example.com"
+    token = "hello"
 
-[user]
-  email = "test@example.com"
-  token = "hello"
+[starter-kits]
 `,
 		},
 		{
@@ -299,7 +368,6 @@ func TestConfigure(t *testing.T) {
   version = ""
 
 [fastly]
-  api_endpoint = "https://api.fastly.com"
 
 [language]
 
@@ -314,11 +382,14 @@ func TestConfigure(t *testing.T) {
   email = ""
   token = ""
 
-[starter-kits]
+[profiles]
+
+  [profiles.user]
+    default = true
+    email = "test@example.com"
+    token = "new_token"
 
-[user]
-  email = "test@example.com"
-  token = "new_token"
+[starter-kits]
 `,
 		},
 		{
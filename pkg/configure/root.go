@@ -63,8 +63,8 @@ func (c *RootCommand) Exec(in io.Reader, out io.Writer) (err error) {
 
 	// Get the endpoint provided by the user, if it was explicitly provided. If
 	// it wasn't provided use default.
-	endpoint, source := c.Globals.Endpoint()
-	switch source { // TODO(pb): this can be duplicate output if --verbose is passed
+	endpoint, endpointSource := c.Globals.Endpoint()
+	switch endpointSource { // TODO(pb): this can be duplicate output if --verbose is passed
 	case config.SourceFlag:
 		text.Output(out, "Fastly API endpoint (via --endpoint): %s", endpoint)
 	case config.SourceEnvironment:
@@ -130,10 +130,29 @@ func (c *RootCommand) Exec(in io.Reader, out io.Writer) (err error) {
 
 	progress.Step("Persisting configuration...")
 
-	// Set everything in the File struct based on provided user input.
-	c.Globals.File.User.Token = token
-	c.Globals.File.User.Email = user.Login
-	c.Globals.File.Fastly.APIEndpoint = endpoint
+	// Set everything in the File struct based on provided user input. The
+	// credentials are stored against the profile in use, which is created if
+	// it doesn't already exist.
+	name, _ := c.Globals.Profile()
+	if name == "" {
+		name = config.DefaultProfileName
+	}
+	if c.Globals.File.Profiles == nil {
+		c.Globals.File.Profiles = make(config.Profiles)
+	}
+	p, ok := c.Globals.File.Profiles[name]
+	if !ok {
+		defaultName, _ := c.Globals.File.DefaultProfile()
+		p = &config.Profile{Default: defaultName == ""}
+		c.Globals.File.Profiles[name] = p
+	}
+	p.Token = token
+	p.Email = user.Login
+	// Only an explicitly provided endpoint is stored, and only against the
+	// profile, so that other profiles keep using their own.
+	if endpointSource == config.SourceFlag || endpointSource == config.SourceEnvironment {
+		p.Endpoint = endpoint
+	}
 
 	// Make sure the config file directory exists.
 	dir := filepath.Dir(c.configFilePath)
@@ -137,6 +137,22 @@ func TestDomainDescribe(t *testing.T) {
 			},
 			WantOutput: describeDomainOutput,
 		},
+		{
+			Args: args("domain describe --service-id 123 --version 1 --name www.test.com --output json"),
+			API: mock.API{
+				ListVersionsFn: testutil.ListVersions,
+				GetDomainFn:    getDomainOK,
+			},
+			WantOutput: describeDomainJSONOutput,
+		},
+		{
+			Args: args("domain describe --service-id 123 --version 1 --name www.test.com --output yaml"),
+			API: mock.API{
+				ListVersionsFn: testutil.ListVersions,
+				GetDomainFn:    getDomainOK,
+			},
+			WantOutput: describeDomainYAMLOutput,
+		},
 	}
 	for _, testcase := range scenarios {
 		t.Run(testcase.Name, func(t *testing.T) {
@@ -299,6 +315,28 @@ func getDomainOK(i *fastly.GetDomainInput) (*fastly.Domain, error) {
 	}, nil
 }
 
+var describeDomainJSONOutput = strings.TrimSpace(`
+{
+  "comment": "test",
+  "created_at": null,
+  "deleted_at": null,
+  "name": "www.test.com",
+  "service_id": "123",
+  "updated_at": null,
+  "version": 1
+}
+`) + "\n"
+
+var describeDomainYAMLOutput = strings.TrimSpace(`
+comment: test
+created_at: null
+deleted_at: null
+name: www.test.com
+service_id: "123"
+updated_at: null
+version: 1
+`) + "\n"
+
 func getDomainError(i *fastly.GetDomainInput) (*fastly.Domain, error) {
 	return nil, errTest
 }
@@ -47,6 +47,10 @@ func (c *DescribeCommand) Exec(in io.Reader, out io.Writer) error {
 		return err
 	}
 
+	if text.IsMachineFormat(c.Globals.Flag.Format) {
+		return text.Render(out, c.Globals.Flag.Format, dictionary)
+	}
+
 	text.Output(out, "Service ID: %s", c.Input.ServiceID)
 	text.PrintDictionaryItem(out, "", dictionary)
 	return nil
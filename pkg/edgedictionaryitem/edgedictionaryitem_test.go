@@ -3,7 +3,9 @@ package edgedictionaryitem_test
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -256,6 +258,265 @@ func TestDictionaryItemBatchModify(t *testing.T) {
 	}
 }
 
+func TestDictionaryItemSync(t *testing.T) {
+	args := testutil.Args
+
+	var many strings.Builder
+	for i := 0; i < fastly.BatchModifyMaximumOperations+1; i++ {
+		fmt.Fprintf(&many, "key%04d,value\n", i)
+	}
+
+	for _, testcase := range []struct {
+		name        string
+		args        []string
+		file        string
+		fileData    string
+		wantError   string
+		wantOutput  []string
+		wantBatches []int
+	}{
+		{
+			name:      "missing file flag",
+			args:      args("dictionaryitem sync --service-id 123 --dictionary-id 456"),
+			wantError: "error parsing arguments: required flag --file not provided",
+		},
+		{
+			name:      "unsupported extension",
+			args:      args("dictionaryitem sync --service-id 123 --dictionary-id 456 --file filePath"),
+			file:      "items.txt",
+			fileData:  "foo=bar",
+			wantError: `unrecognised dictionary item file extension ".txt"`,
+		},
+		{
+			name:      "invalid csv",
+			args:      args("dictionaryitem sync --service-id 123 --dictionary-id 456 --file filePath"),
+			file:      "items.csv",
+			fileData:  "foo,bar,baz\n",
+			wantError: "wrong number of fields",
+		},
+		{
+			name:     "dry run",
+			args:     args("dictionaryitem sync --service-id 123 --dictionary-id 456 --file filePath --prune --dry-run"),
+			file:     "items.csv",
+			fileData: "item_key,item_value\nbaz,changed\nqux,1\n",
+			wantOutput: []string{
+				"\t~ baz\n\t- foo\n\t+ qux\n",
+				"Dry run: 1 created, 1 updated, 1 deleted",
+			},
+		},
+		{
+			name:        "sync without pruning",
+			args:        args("dictionaryitem sync --service-id 123 --dictionary-id 456 --file filePath"),
+			file:        "items.json",
+			fileData:    `{"baz": "changed", "qux": "1"}`,
+			wantOutput:  []string{"Synced dictionary 456 on service 123: 1 created, 1 updated, 0 deleted"},
+			wantBatches: []int{2},
+		},
+		{
+			name:        "sync with pruning",
+			args:        args("dictionaryitem sync --service-id 123 --dictionary-id 456 --file filePath --prune"),
+			file:        "items.yaml",
+			fileData:    "baz: bear\n",
+			wantOutput:  []string{"Synced dictionary 456 on service 123: 0 created, 0 updated, 1 deleted"},
+			wantBatches: []int{1},
+		},
+		{
+			name:        "sync in batches",
+			args:        args("dictionaryitem sync --service-id 123 --dictionary-id 456 --file filePath"),
+			file:        "items.csv",
+			fileData:    many.String(),
+			wantOutput:  []string{"1001 created, 0 updated, 0 deleted"},
+			wantBatches: []int{fastly.BatchModifyMaximumOperations, 1},
+		},
+		{
+			name:       "already in sync",
+			args:       args("dictionaryitem sync --service-id 123 --dictionary-id 456 --file filePath --prune"),
+			file:       "items.json",
+			fileData:   `{"foo": "bar", "baz": "bear"}`,
+			wantOutput: []string{"Dictionary 456 already matches"},
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			if testcase.file != "" {
+				filePath := filepath.Join(t.TempDir(), testcase.file)
+				if err := os.WriteFile(filePath, []byte(testcase.fileData), 0600); err != nil {
+					t.Fatal(err)
+				}
+				for i, v := range testcase.args {
+					if v == "filePath" {
+						testcase.args[i] = filePath
+					}
+				}
+			}
+
+			var batches []int
+			api := mock.API{
+				ListDictionaryItemsFn: listDictionaryItemsOK,
+				BatchModifyDictionaryItemsFn: func(i *fastly.BatchModifyDictionaryItemsInput) error {
+					batches = append(batches, len(i.Items))
+					return nil
+				},
+			}
+
+			var stdout bytes.Buffer
+			opts := testutil.NewRunOpts(testcase.args, &stdout)
+			opts.APIClient = mock.APIClient(api)
+			err := app.Run(opts)
+			testutil.AssertErrorContains(t, err, testcase.wantError)
+			for _, s := range testcase.wantOutput {
+				testutil.AssertStringContains(t, stdout.String(), s)
+			}
+			testutil.AssertEqual(t, testcase.wantBatches, batches)
+		})
+	}
+}
+
+func TestDictionaryItemExport(t *testing.T) {
+	args := testutil.Args
+	for _, testcase := range []struct {
+		name       string
+		args       []string
+		api        mock.API
+		file       string
+		wantError  string
+		wantOutput string
+		wantFile   string
+	}{
+		{
+			name:      "missing dictionary ID",
+			args:      args("dictionaryitem export --service-id 123"),
+			wantError: "error parsing arguments: required flag --dictionary-id not provided",
+		},
+		{
+			name:      "list error",
+			args:      args("dictionaryitem export --service-id 123 --dictionary-id 456"),
+			api:       mock.API{ListDictionaryItemsFn: listDictionaryItemsError},
+			wantError: errTest.Error(),
+		},
+		{
+			name:       "csv",
+			args:       args("dictionaryitem export --service-id 123 --dictionary-id 456"),
+			api:        mock.API{ListDictionaryItemsFn: listDictionaryItemsOK},
+			wantOutput: "item_key,item_value\nbaz,bear\nfoo,bar\n",
+		},
+		{
+			name:       "json",
+			args:       args("dictionaryitem export --service-id 123 --dictionary-id 456 --format json"),
+			api:        mock.API{ListDictionaryItemsFn: listDictionaryItemsOK},
+			wantOutput: "{\n  \"baz\": \"bear\",\n  \"foo\": \"bar\"\n}\n",
+		},
+		{
+			name:       "yaml",
+			args:       args("dictionaryitem export --service-id 123 --dictionary-id 456 --format yaml"),
+			api:        mock.API{ListDictionaryItemsFn: listDictionaryItemsOK},
+			wantOutput: "baz: bear\nfoo: bar\n",
+		},
+		{
+			name:       "env",
+			args:       args("dictionaryitem export --service-id 123 --dictionary-id 456 --format env"),
+			api:        mock.API{ListDictionaryItemsFn: listDictionaryItemsOK},
+			wantOutput: "baz=\"bear\"\nfoo=\"bar\"\n",
+		},
+		{
+			name:       "file",
+			args:       args("dictionaryitem export --service-id 123 --dictionary-id 456 --file filePath"),
+			api:        mock.API{ListDictionaryItemsFn: listDictionaryItemsOK},
+			file:       "items.env",
+			wantOutput: "\nSUCCESS: Exported 2 items of dictionary 456 to ",
+			wantFile:   "baz=\"bear\"\nfoo=\"bar\"\n",
+		},
+		{
+			name: "batch",
+			args: args("dictionaryitem export --service-id 123 --dictionary-id 456 --format batch"),
+			api:  mock.API{ListDictionaryItemsFn: listDictionaryItemsOK},
+			wantOutput: `{
+  "items": [
+    {
+      "op": "upsert",
+      "item_key": "baz",
+      "item_value": "bear"
+    },
+    {
+      "op": "upsert",
+      "item_key": "foo",
+      "item_value": "bar"
+    }
+  ]
+}
+`,
+		},
+		{
+			name: "env key with whitespace",
+			args: args("dictionaryitem export --service-id 123 --dictionary-id 456 --format env"),
+			api: mock.API{ListDictionaryItemsFn: func(i *fastly.ListDictionaryItemsInput) ([]*fastly.DictionaryItem, error) {
+				return []*fastly.DictionaryItem{{ItemKey: " padded", ItemValue: "bar"}}, nil
+			}},
+			wantError: `item key " padded" can't be written in the env format`,
+		},
+		{
+			name:      "unrecognised file extension",
+			args:      args("dictionaryitem export --service-id 123 --dictionary-id 456 --file filePath"),
+			file:      "items.txt",
+			wantError: `unrecognised dictionary item file extension ".txt"`,
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			var filePath string
+			if testcase.file != "" {
+				filePath = filepath.Join(t.TempDir(), testcase.file)
+				for i, v := range testcase.args {
+					if v == "filePath" {
+						testcase.args[i] = filePath
+					}
+				}
+			}
+
+			var stdout bytes.Buffer
+			opts := testutil.NewRunOpts(testcase.args, &stdout)
+			opts.APIClient = mock.APIClient(testcase.api)
+			err := app.Run(opts)
+			testutil.AssertErrorContains(t, err, testcase.wantError)
+			testutil.AssertStringContains(t, stdout.String(), testcase.wantOutput)
+
+			if testcase.wantFile != "" {
+				bs, err := os.ReadFile(filePath)
+				if err != nil {
+					t.Fatal(err)
+				}
+				testutil.AssertString(t, testcase.wantFile, string(bs))
+			}
+		})
+	}
+}
+
+func TestDictionaryItemExportBatchModify(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "items.json")
+
+	var stdout bytes.Buffer
+	opts := testutil.NewRunOpts(testutil.Args("dictionaryitem export --service-id 123 --dictionary-id 456 --format batch --file "+filePath), &stdout)
+	opts.APIClient = mock.APIClient(mock.API{ListDictionaryItemsFn: listDictionaryItemsOK})
+	err := app.Run(opts)
+	testutil.AssertNoError(t, err)
+
+	// The exported file can be fed back into a batch modification.
+	var input *fastly.BatchModifyDictionaryItemsInput
+	opts = testutil.NewRunOpts(testutil.Args("dictionaryitem batchmodify --service-id 123 --dictionary-id 789 --file "+filePath), &stdout)
+	opts.APIClient = mock.APIClient(mock.API{BatchModifyDictionaryItemsFn: func(i *fastly.BatchModifyDictionaryItemsInput) error {
+		input = i
+		return nil
+	}})
+	err = app.Run(opts)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, &fastly.BatchModifyDictionaryItemsInput{
+		ServiceID:    "123",
+		DictionaryID: "789",
+		Items: []*fastly.BatchDictionaryItem{
+			{Operation: fastly.UpsertBatchOperation, ItemKey: "baz", ItemValue: "bear"},
+			{Operation: fastly.UpsertBatchOperation, ItemKey: "foo", ItemValue: "bar"},
+		},
+	}, input)
+}
+
 func describeDictionaryItemOK(i *fastly.GetDictionaryItemInput) (*fastly.DictionaryItem, error) {
 	return &fastly.DictionaryItem{
 		ServiceID:    i.ServiceID,
@@ -407,3 +668,7 @@ func batchModifyDictionaryItemsError(i *fastly.BatchModifyDictionaryItemsInput)
 }
 
 var errTest = errors.New("an expected error ocurred")
+
+func listDictionaryItemsError(i *fastly.ListDictionaryItemsInput) ([]*fastly.DictionaryItem, error) {
+	return nil, errTest
+}
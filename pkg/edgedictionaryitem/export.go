@@ -0,0 +1,102 @@
+package edgedictionaryitem
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fastly/cli/pkg/cmd"
+	"github.com/fastly/cli/pkg/compute/manifest"
+	"github.com/fastly/cli/pkg/config"
+	"github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/text"
+	"github.com/fastly/go-fastly/v3/fastly"
+)
+
+// filePermissions is the file mode of exported dictionary item files, which
+// may contain secrets.
+const filePermissions = 0600
+
+// ExportCommand calls the Fastly API to write the items of a dictionary to a
+// file.
+type ExportCommand struct {
+	cmd.Base
+	manifest manifest.Data
+	Input    fastly.ListDictionaryItemsInput
+
+	file   string
+	format string
+}
+
+// NewExportCommand returns a usable command registered under the parent.
+func NewExportCommand(parent cmd.Registerer, globals *config.Data) *ExportCommand {
+	var c ExportCommand
+	c.Globals = globals
+	c.manifest.File.SetOutput(c.Globals.Output)
+	c.manifest.File.Read(manifest.Filename)
+	c.CmdClause = parent.Command("export", "Export the items of a Fastly edge dictionary to a file, sorted by key")
+	c.RegisterServiceIDFlag(&c.manifest.Flag.ServiceID)
+	c.CmdClause.Flag("dictionary-id", "Dictionary ID").Required().StringVar(&c.Input.DictionaryID)
+	c.CmdClause.Flag("file", "Path of the file to write (defaults to stdout)").Short('f').StringVar(&c.file)
+	c.CmdClause.Flag("format", "File format, inferred from the --file extension if not set (csv, json, yaml, env, or batch for the batchmodify command)").HintOptions(exportFormats...).EnumVar(&c.format, exportFormats...)
+	return &c
+}
+
+// Exec invokes the application logic for the command.
+func (c *ExportCommand) Exec(in io.Reader, out io.Writer) error {
+	serviceID, source := c.manifest.ServiceID()
+	if source == manifest.SourceUndefined {
+		return errors.ErrNoServiceID
+	}
+	c.Input.ServiceID = serviceID
+
+	format := c.format
+	if format == "" {
+		format = formatCSV
+		if c.file != "" {
+			f, err := formatFromPath(c.file)
+			if err != nil {
+				return errors.RemediationError{
+					Inner:       err,
+					Remediation: "Set the file format with the --format flag.",
+				}
+			}
+			format = f
+		}
+	}
+
+	dictionaryItems, err := c.Globals.Client.ListDictionaryItems(&c.Input)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID":    serviceID,
+			"Dictionary ID": c.Input.DictionaryID,
+		})
+		return err
+	}
+
+	items := make(map[string]string, len(dictionaryItems))
+	for _, item := range dictionaryItems {
+		items[item.ItemKey] = item.ItemValue
+	}
+	bs, err := encodeItems(items, format)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return fmt.Errorf("error encoding dictionary items: %w", err)
+	}
+
+	// Only the items are written to stdout so they can be redirected to a file.
+	if c.file == "" {
+		_, err = out.Write(bs)
+		return err
+	}
+
+	if err := os.WriteFile(c.file, bs, filePermissions); err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"File": c.file,
+		})
+		return fmt.Errorf("error writing dictionary items: %w", err)
+	}
+
+	text.Success(out, "Exported %d items of dictionary %s to %s", len(items), c.Input.DictionaryID, c.file)
+	return nil
+}
@@ -0,0 +1,226 @@
+package edgedictionaryitem
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fastly/go-fastly/v3/fastly"
+	"gopkg.in/yaml.v2"
+)
+
+// Dictionary item file formats.
+const (
+	formatCSV  = "csv"
+	formatJSON = "json"
+	formatYAML = "yaml"
+	formatEnv  = "env"
+
+	// formatBatch is the JSON format read by the batchmodify command, with an
+	// upsert operation per item. It's only written by export.
+	formatBatch = "batch"
+)
+
+// formats is every supported dictionary item file format.
+var formats = []string{formatCSV, formatJSON, formatYAML, formatEnv}
+
+// exportFormats is every format dictionary items can be exported in.
+var exportFormats = append(formats[:len(formats):len(formats)], formatBatch)
+
+// csvHeader is the optional header row of a CSV dictionary item file.
+var csvHeader = []string{"item_key", "item_value"}
+
+// formatFromPath deduces the format of a dictionary item file from its
+// extension.
+func formatFromPath(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return formatCSV, nil
+	case ".json":
+		return formatJSON, nil
+	case ".yaml", ".yml":
+		return formatYAML, nil
+	case ".env":
+		return formatEnv, nil
+	}
+	return "", fmt.Errorf("unrecognised dictionary item file extension %q (expected .csv, .json, .yaml, .yml or .env)", filepath.Ext(path))
+}
+
+// readItems reads the dictionary items in the file at path.
+//
+// CSV files contain a key and value per row, with an optional item_key,
+// item_value header row. JSON and YAML files contain an object mapping keys to
+// values. Env files contain a key=value assignment per line, where the value
+// may be a double quoted Go string.
+func readItems(path string) (map[string]string, error) {
+	format, err := formatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() // #nosec G307
+
+	items, err := decodeItems(f, format)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return items, nil
+}
+
+// decodeItems decodes dictionary items in the given format.
+func decodeItems(r io.Reader, format string) (map[string]string, error) {
+	items := make(map[string]string)
+	switch format {
+	case formatCSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = 2
+		records, err := cr.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		for i, record := range records {
+			if i == 0 && record[0] == csvHeader[0] && record[1] == csvHeader[1] {
+				continue
+			}
+			if _, ok := items[record[0]]; ok {
+				return nil, fmt.Errorf("duplicate key %q", record[0])
+			}
+			items[record[0]] = record[1]
+		}
+	case formatJSON:
+		if err := json.NewDecoder(r).Decode(&items); err != nil {
+			return nil, err
+		}
+	case formatYAML:
+		bs, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.UnmarshalStrict(bs, &items); err != nil {
+			return nil, err
+		}
+	case formatEnv:
+		scanner := bufio.NewScanner(r)
+		for n := 1; scanner.Scan(); n++ {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			i := strings.Index(line, "=")
+			if i < 0 {
+				return nil, fmt.Errorf("line %d: expected key=value", n)
+			}
+			key, value := line[:i], line[i+1:]
+			if strings.HasPrefix(value, `"`) {
+				v, err := strconv.Unquote(value)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", n, err)
+				}
+				value = v
+			}
+			if _, ok := items[key]; ok {
+				return nil, fmt.Errorf("duplicate key %q", key)
+			}
+			items[key] = value
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+
+	for key := range items {
+		if key == "" {
+			return nil, fmt.Errorf("item keys must not be empty")
+		}
+	}
+	return items, nil
+}
+
+// encodeItems encodes dictionary items in the given format, ordered by key so
+// the output is deterministic. The output can be read by decodeItems, or by the
+// batchmodify command for the batch format.
+func encodeItems(items map[string]string, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	switch format {
+	case formatCSV:
+		cw := csv.NewWriter(&buf)
+		if err := cw.Write(csvHeader); err != nil {
+			return nil, err
+		}
+		for _, key := range sortedKeys(items) {
+			if err := cw.Write([]string{key, items[key]}); err != nil {
+				return nil, err
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return nil, err
+		}
+	case formatJSON:
+		// Map keys are sorted by the encoder.
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(items); err != nil {
+			return nil, err
+		}
+	case formatYAML:
+		// Map keys are sorted by the encoder.
+		bs, err := yaml.Marshal(items)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(bs)
+	case formatBatch:
+		var batch fastly.BatchModifyDictionaryItemsInput
+		for _, key := range sortedKeys(items) {
+			batch.Items = append(batch.Items, &fastly.BatchDictionaryItem{
+				Operation: fastly.UpsertBatchOperation,
+				ItemKey:   key,
+				ItemValue: items[key],
+			})
+		}
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(batch); err != nil {
+			return nil, err
+		}
+	case formatEnv:
+		for _, key := range sortedKeys(items) {
+			// The key must read back unchanged, and lines are trimmed of
+			// whitespace when they're read.
+			if strings.ContainsAny(key, "=\r\n") || strings.HasPrefix(key, "#") || strings.TrimSpace(key) != key {
+				return nil, fmt.Errorf("item key %q can't be written in the %s format", key, formatEnv)
+			}
+			fmt.Fprintf(&buf, "%s=%s\n", key, strconv.Quote(items[key]))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+	return buf.Bytes(), nil
+}
+
+// sortedKeys returns the keys of items in order.
+func sortedKeys(items map[string]string) []string {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -46,6 +46,10 @@ func (c *ListCommand) Exec(in io.Reader, out io.Writer) error {
 		return err
 	}
 
+	if text.IsMachineFormat(c.Globals.Flag.Format) {
+		return text.Render(out, c.Globals.Flag.Format, dictionaries)
+	}
+
 	text.Output(out, "Service ID: %s\n", c.Input.ServiceID)
 	for i, dictionary := range dictionaries {
 		text.Output(out, "Item: %d/%d", i+1, len(dictionaries))
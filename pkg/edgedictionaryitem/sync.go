@@ -0,0 +1,169 @@
+package edgedictionaryitem
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/fastly/cli/pkg/cmd"
+	"github.com/fastly/cli/pkg/compute/manifest"
+	"github.com/fastly/cli/pkg/config"
+	"github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/text"
+	"github.com/fastly/go-fastly/v3/fastly"
+)
+
+// SyncCommand calls the Fastly API to make the items of a dictionary match a
+// local file.
+type SyncCommand struct {
+	cmd.Base
+	manifest     manifest.Data
+	dictionaryID string
+	file         string
+	dryRun       bool
+	prune        bool
+}
+
+// NewSyncCommand returns a usable command registered under the parent.
+func NewSyncCommand(parent cmd.Registerer, globals *config.Data) *SyncCommand {
+	var c SyncCommand
+	c.Globals = globals
+	c.manifest.File.SetOutput(c.Globals.Output)
+	c.manifest.File.Read(manifest.Filename)
+	c.CmdClause = parent.Command("sync", "Update a Fastly edge dictionary to match the items in a CSV, JSON, YAML or env file")
+	c.RegisterServiceIDFlag(&c.manifest.Flag.ServiceID)
+	c.CmdClause.Flag("dictionary-id", "Dictionary ID").Required().StringVar(&c.dictionaryID)
+	c.CmdClause.Flag("file", "Path of the CSV, JSON, YAML or env file of dictionary items").Short('f').Required().StringVar(&c.file)
+	c.CmdClause.Flag("dry-run", "Print the changes required to sync the dictionary without making them").BoolVar(&c.dryRun)
+	c.CmdClause.Flag("prune", "Delete dictionary items which aren't in the file").BoolVar(&c.prune)
+	return &c
+}
+
+// Exec invokes the application logic for the command.
+func (c *SyncCommand) Exec(in io.Reader, out io.Writer) (err error) {
+	serviceID, source := c.manifest.ServiceID()
+	if source == manifest.SourceUndefined {
+		return errors.ErrNoServiceID
+	}
+
+	desired, err := readItems(c.file)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"File": c.file,
+		})
+		return err
+	}
+
+	current, err := c.Globals.Client.ListDictionaryItems(&fastly.ListDictionaryItemsInput{
+		ServiceID:    serviceID,
+		DictionaryID: c.dictionaryID,
+	})
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID":    serviceID,
+			"Dictionary ID": c.dictionaryID,
+		})
+		return err
+	}
+
+	ops := syncOperations(current, desired, c.prune)
+	if len(ops) == 0 {
+		text.Info(out, "Dictionary %s already matches %s", c.dictionaryID, c.file)
+		return nil
+	}
+
+	counts := make(map[fastly.BatchOperation]int)
+	for _, op := range ops {
+		counts[op.Operation]++
+	}
+	summary := fmt.Sprintf("%d created, %d updated, %d deleted", counts[fastly.CreateBatchOperation], counts[fastly.UpdateBatchOperation], counts[fastly.DeleteBatchOperation])
+
+	if c.dryRun {
+		signs := map[fastly.BatchOperation]string{
+			fastly.CreateBatchOperation: "+",
+			fastly.UpdateBatchOperation: "~",
+			fastly.DeleteBatchOperation: "-",
+		}
+		for _, op := range ops {
+			fmt.Fprintf(out, "\t%s %s\n", signs[op.Operation], op.ItemKey)
+		}
+		text.Break(out)
+		text.Info(out, "Dry run: %s", summary)
+		return nil
+	}
+
+	var progress text.Progress
+	if c.Globals.Verbose() {
+		progress = text.NewVerboseProgress(out)
+	} else {
+		progress = text.NewQuietProgress(out)
+	}
+	defer func() {
+		if err != nil {
+			progress.Fail() // progress.Done is handled inline
+		}
+	}()
+
+	batches := (len(ops) + fastly.BatchModifyMaximumOperations - 1) / fastly.BatchModifyMaximumOperations
+	for i := 0; i < batches; i++ {
+		start := i * fastly.BatchModifyMaximumOperations
+		end := start + fastly.BatchModifyMaximumOperations
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		progress.Step(fmt.Sprintf("Applying batch %d/%d (%d operations)...", i+1, batches, end-start))
+		err = c.Globals.Client.BatchModifyDictionaryItems(&fastly.BatchModifyDictionaryItemsInput{
+			ServiceID:    serviceID,
+			DictionaryID: c.dictionaryID,
+			Items:        ops[start:end],
+		})
+		if err != nil {
+			c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+				"Service ID":    serviceID,
+				"Dictionary ID": c.dictionaryID,
+				"Batch":         i + 1,
+			})
+			if i > 0 {
+				return fmt.Errorf("error applying batch %d/%d (earlier batches were applied): %w", i+1, batches, err)
+			}
+			return err
+		}
+	}
+
+	progress.Done()
+	text.Success(out, "Synced dictionary %s on service %s: %s", c.dictionaryID, serviceID, summary)
+	return nil
+}
+
+// syncOperations returns the batch operations, ordered by key, which make the
+// current dictionary items match the desired items. Items which aren't desired
+// are only deleted when pruning.
+func syncOperations(current []*fastly.DictionaryItem, desired map[string]string, prune bool) []*fastly.BatchDictionaryItem {
+	existing := make(map[string]string, len(current))
+	for _, item := range current {
+		existing[item.ItemKey] = item.ItemValue
+	}
+
+	all := make(map[string]string, len(existing)+len(desired))
+	for key, value := range existing {
+		all[key] = value
+	}
+	for key, value := range desired {
+		all[key] = value
+	}
+
+	var ops []*fastly.BatchDictionaryItem
+	for _, key := range sortedKeys(all) {
+		value, ok := desired[key]
+		old, exists := existing[key]
+		switch {
+		case ok && !exists:
+			ops = append(ops, &fastly.BatchDictionaryItem{Operation: fastly.CreateBatchOperation, ItemKey: key, ItemValue: value})
+		case ok && old != value:
+			ops = append(ops, &fastly.BatchDictionaryItem{Operation: fastly.UpdateBatchOperation, ItemKey: key, ItemValue: value})
+		case !ok && prune:
+			ops = append(ops, &fastly.BatchDictionaryItem{Operation: fastly.DeleteBatchOperation, ItemKey: key})
+		}
+	}
+	return ops
+}
@@ -11,6 +11,9 @@ const (
 	// Endpoint is the env var we look in for the API endpoint.
 	Endpoint = "FASTLY_API_ENDPOINT"
 
+	// Profile is the env var we look in for the name of the config profile.
+	Profile = "FASTLY_PROFILE"
+
 	// ServiceID is the env var we look in for the required Service ID.
 	ServiceID = "FASTLY_SERVICE_ID"
 )
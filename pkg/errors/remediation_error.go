@@ -87,6 +87,12 @@ var ExistingDirRemediation = strings.Join([]string{
 	"`fastly compute init`.",
 }, " ")
 
+// ProfileRemediation suggests listing the available profiles.
+var ProfileRemediation = strings.Join([]string{
+	"Run `fastly profile list` to see the available profiles,",
+	"or `fastly profile create` to create a new profile.",
+}, " ")
+
 // AutoCloneRemediation suggests provide an --autoclone flag.
 var AutoCloneRemediation = strings.Join([]string{
 	"Repeat the command with the --autoclone flag to allow the version to be cloned",
@@ -3,6 +3,7 @@ package exec
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -10,6 +11,7 @@ import (
 	"os/exec"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 )
@@ -24,6 +26,8 @@ type Streaming struct {
 	Env     []string
 	Output  io.Writer
 	Timeout time.Duration
+
+	mu      sync.Mutex
 	process *os.Process
 }
 
@@ -55,7 +59,7 @@ func monitorSignals(s *Streaming) {
 // Exec executes the compiler command and pipes the child process stdout and
 // stderr output to the supplied io.Writer, it waits for the command to exit
 // cleanly or returns an error.
-func (s Streaming) Exec() error {
+func (s *Streaming) Exec() error {
 	// Construct the command with given arguments and environment.
 	var cmd *exec.Cmd
 	if s.Timeout > 0 {
@@ -75,16 +79,25 @@ func (s Streaming) Exec() error {
 	}
 	cmd.Env = append(os.Environ(), s.Env...)
 
-	// Store off Process so it can be killed by signals
-	//lint:ignore SA4005 because it doesn't fail on macOS but does when run in CI.
-	s.process = cmd.Process
-
 	// Pipe the child process stdout and stderr to our own output writer.
 	var stderrBuf bytes.Buffer
 	cmd.Stdout = s.Output
 	cmd.Stderr = io.MultiWriter(s.Output, &stderrBuf)
 
-	if err := cmd.Run(); err != nil {
+	err := cmd.Start()
+	if err == nil {
+		// Store off Process so it can be killed by signals.
+		s.mu.Lock()
+		s.process = cmd.Process
+		s.mu.Unlock()
+
+		err = cmd.Wait()
+
+		s.mu.Lock()
+		s.process = nil
+		s.mu.Unlock()
+	}
+	if err != nil {
 		var ctx string
 		if stderrBuf.Len() > 0 {
 			ctx = fmt.Sprintf(":\n%s", strings.TrimSpace(stderrBuf.String()))
@@ -97,11 +110,14 @@ func (s Streaming) Exec() error {
 	return nil
 }
 
-// Signal enables spawned subprocess to accept given signal.
-func (s Streaming) Signal(signal os.Signal) error {
+// Signal enables spawned subprocess to accept given signal. It's a no-op when
+// the subprocess isn't running.
+func (s *Streaming) Signal(signal os.Signal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if s.process != nil {
 		err := s.process.Signal(signal)
-		if err != nil {
+		if err != nil && !errors.Is(err, os.ErrProcessDone) {
 			return err
 		}
 	}
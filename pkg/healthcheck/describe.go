@@ -65,6 +65,10 @@ func (c *DescribeCommand) Exec(in io.Reader, out io.Writer) error {
 		return err
 	}
 
+	if text.IsMachineFormat(c.Globals.Flag.Format) {
+		return text.Render(out, c.Globals.Flag.Format, healthCheck)
+	}
+
 	fmt.Fprintf(out, "Service ID: %s\n", healthCheck.ServiceID)
 	fmt.Fprintf(out, "Version: %d\n", healthCheck.ServiceVersion)
 	text.PrintHealthCheck(out, "", healthCheck)
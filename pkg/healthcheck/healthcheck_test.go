@@ -2,7 +2,11 @@ package healthcheck_test
 
 import (
 	"bytes"
+	"encoding/pem"
 	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
@@ -389,3 +393,156 @@ func deleteHealthCheckOK(i *fastly.DeleteHealthCheckInput) error {
 func deleteHealthCheckError(i *fastly.DeleteHealthCheckInput) error {
 	return errTest
 }
+
+func TestHealthCheckTest(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/health" || r.Host != "www.test.com" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer healthy.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != "origin.test" || r.TLS.ServerName != "sni.test" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer secure.Close()
+	caCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: secure.Certificate().Raw}))
+
+	backends := map[string]*fastly.Backend{
+		"healthy": backendFor("healthy", healthy, "www.test.com"),
+		"failing": backendFor("failing", failing, "www.test.com"),
+		"none":    backendFor("none", healthy, ""),
+	}
+	tlsBackend := func(certHostname string) *fastly.Backend {
+		b := backendFor("secure", secure, "www.test.com")
+		b.UseSSL = true
+		b.SSLCheckCert = true
+		b.SSLCACert = caCert
+		b.SSLCertHostname = certHostname
+		b.SSLSNIHostname = "sni.test"
+		b.OverrideHost = "origin.test"
+		return b
+	}
+
+	args := testutil.Args
+	for _, testcase := range []struct {
+		name        string
+		args        []string
+		api         mock.API
+		wantError   string
+		wantOutputs []string
+	}{
+		{
+			name:      "neither name nor backend",
+			args:      args("healthcheck test --service-id 123 --version 1"),
+			wantError: "exactly one of --name or --backend is required",
+		},
+		{
+			name: "backends using healthcheck",
+			args: args("healthcheck test --service-id 123 --version 1 --name www.test.com"),
+			api: mock.API{
+				ListVersionsFn:   testutil.ListVersions,
+				GetHealthCheckFn: getHealthCheckProbe,
+				ListBackendsFn: func(i *fastly.ListBackendsInput) ([]*fastly.Backend, error) {
+					return []*fastly.Backend{backends["failing"], backends["healthy"], backends["none"]}, nil
+				},
+			},
+			wantError: "1 of 2 healthcheck windows were unhealthy",
+			wantOutputs: []string{
+				"Probing backend failing (" + failing.Listener.Addr().String() + ") with healthcheck www.test.com",
+				"Window 1: 0 of 3 probes passed (threshold 2): unhealthy\n  fail: 500 Internal Server Error, expected status 200",
+				"Probing backend healthy (" + healthy.Listener.Addr().String() + ") with healthcheck www.test.com",
+				"Window 1: 3 of 3 probes passed (threshold 2): healthy",
+			},
+		},
+		{
+			name: "backend over TLS",
+			args: args("healthcheck test --service-id 123 --version 1 --backend secure --windows 2 -v"),
+			api: mock.API{
+				ListVersionsFn: testutil.ListVersions,
+				// Without a healthcheck host, the backend's override
+				// host is used.
+				GetHealthCheckFn: func(i *fastly.GetHealthCheckInput) (*fastly.HealthCheck, error) {
+					h, err := getHealthCheckProbe(i)
+					h.Host = ""
+					return h, err
+				},
+				GetBackendFn: func(i *fastly.GetBackendInput) (*fastly.Backend, error) {
+					return tlsBackend("example.com"), nil
+				},
+			},
+			wantOutputs: []string{
+				"Window 1: 3 of 3 probes passed (threshold 2): healthy",
+				"Window 2: 3 of 3 probes passed (threshold 2): healthy",
+				"All backends passed healthcheck www.test.com",
+			},
+		},
+		{
+			name: "backend over TLS with the wrong certificate hostname",
+			args: args("healthcheck test --service-id 123 --version 1 --backend secure"),
+			api: mock.API{
+				ListVersionsFn:   testutil.ListVersions,
+				GetHealthCheckFn: getHealthCheckProbe,
+				GetBackendFn: func(i *fastly.GetBackendInput) (*fastly.Backend, error) {
+					return tlsBackend("wrong.test"), nil
+				},
+			},
+			wantError:   "1 of 1 healthcheck windows were unhealthy",
+			wantOutputs: []string{"certificate is valid for example.com"},
+		},
+		{
+			name: "backend without healthcheck",
+			args: args("healthcheck test --service-id 123 --version 1 --backend none"),
+			api: mock.API{
+				ListVersionsFn: testutil.ListVersions,
+				GetBackendFn: func(i *fastly.GetBackendInput) (*fastly.Backend, error) {
+					return backends["none"], nil
+				},
+			},
+			wantError: "backend none has no healthcheck",
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			opts := testutil.NewRunOpts(testcase.args, &stdout)
+			opts.APIClient = mock.APIClient(testcase.api)
+			err := app.Run(opts)
+			testutil.AssertErrorContains(t, err, testcase.wantError)
+			for _, want := range testcase.wantOutputs {
+				testutil.AssertStringContains(t, stdout.String(), want)
+			}
+		})
+	}
+}
+
+func getHealthCheckProbe(i *fastly.GetHealthCheckInput) (*fastly.HealthCheck, error) {
+	return &fastly.HealthCheck{
+		ServiceID:        i.ServiceID,
+		ServiceVersion:   i.ServiceVersion,
+		Name:             "www.test.com",
+		Method:           http.MethodGet,
+		Host:             "www.test.com",
+		Path:             "/health",
+		Timeout:          1000,
+		CheckInterval:    1,
+		ExpectedResponse: http.StatusOK,
+		Window:           3,
+		Threshold:        2,
+	}, nil
+}
+
+// backendFor returns a backend for the test server using the healthcheck.
+func backendFor(name string, server *httptest.Server, healthCheck string) *fastly.Backend {
+	addr := server.Listener.Addr().(*net.TCPAddr)
+	return &fastly.Backend{
+		Name:        name,
+		Address:     addr.IP.String(),
+		Port:        uint(addr.Port),
+		HealthCheck: healthCheck,
+	}
+}
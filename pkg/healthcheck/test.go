@@ -0,0 +1,359 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/fastly/cli/pkg/backend"
+	"github.com/fastly/cli/pkg/cmd"
+	"github.com/fastly/cli/pkg/compute/manifest"
+	"github.com/fastly/cli/pkg/config"
+	"github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/text"
+	"github.com/fastly/cli/pkg/useragent"
+	"github.com/fastly/go-fastly/v3/fastly"
+)
+
+// TestCommand probes the backends of a service version from the local machine
+// the way a Fastly healthcheck would.
+type TestCommand struct {
+	cmd.Base
+	manifest       manifest.Data
+	serviceVersion cmd.OptionalServiceVersion
+
+	name     string
+	backend  string
+	windows  int
+	interval time.Duration
+}
+
+// NewTestCommand returns a usable command registered under the parent.
+func NewTestCommand(parent cmd.Registerer, globals *config.Data) *TestCommand {
+	var c TestCommand
+	c.Globals = globals
+	c.manifest.File.SetOutput(c.Globals.Output)
+	c.manifest.File.Read(manifest.Filename)
+	c.CmdClause = parent.Command("test", "Probe the backends of a Fastly service version from this machine as a healthcheck would")
+	c.RegisterServiceIDFlag(&c.manifest.Flag.ServiceID)
+	c.RegisterServiceVersionFlag(cmd.ServiceVersionFlagOpts{
+		Dst: &c.serviceVersion.Value,
+	})
+	c.CmdClause.Flag("name", "Name of healthcheck, to probe every backend using it").Short('n').StringVar(&c.name)
+	c.CmdClause.Flag("backend", "Name of backend, to probe with its healthcheck").StringVar(&c.backend)
+	c.CmdClause.Flag("windows", "Number of healthcheck windows to probe for").Default("1").IntVar(&c.windows)
+	c.CmdClause.Flag("interval", "Time between probes, e.g. 1s (defaults to the healthcheck's check interval)").DurationVar(&c.interval)
+	return &c
+}
+
+// probe is the outcome of a single healthcheck request.
+type probe struct {
+	Status   int           `json:"status"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error"`
+	Pass     bool          `json:"pass"`
+}
+
+// window is the outcome of a healthcheck window of probes.
+type window struct {
+	Probes  []probe `json:"probes"`
+	Passed  int     `json:"passed"`
+	Healthy bool    `json:"healthy"`
+}
+
+// backendResult is the outcome of probing a backend.
+type backendResult struct {
+	Backend     string   `json:"backend"`
+	HealthCheck string   `json:"healthcheck"`
+	Address     string   `json:"address"`
+	Windows     []window `json:"windows"`
+}
+
+// Exec invokes the application logic for the command.
+func (c *TestCommand) Exec(in io.Reader, out io.Writer) error {
+	if (c.name == "") == (c.backend == "") {
+		return errors.RemediationError{
+			Inner:       fmt.Errorf("exactly one of --name or --backend is required"),
+			Remediation: "Use --name to probe every backend using a healthcheck, or --backend to probe a single backend.",
+		}
+	}
+	if c.windows < 1 {
+		return fmt.Errorf("--windows must be at least 1")
+	}
+
+	serviceID, serviceVersion, err := cmd.ServiceDetails(cmd.ServiceDetailsOpts{
+		AllowActiveLocked:  true,
+		Client:             c.Globals.Client,
+		Manifest:           c.manifest,
+		Out:                out,
+		ServiceVersionFlag: c.serviceVersion,
+		VerboseMode:        c.Globals.Flag.Verbose,
+	})
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID":      serviceID,
+			"Service Version": errors.ServiceVersion(serviceVersion),
+		})
+		return err
+	}
+
+	healthCheck, backends, err := c.definitions(serviceID, serviceVersion.Number)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID":      serviceID,
+			"Service Version": serviceVersion.Number,
+			"Healthcheck":     c.name,
+			"Backend":         c.backend,
+		})
+		return err
+	}
+
+	interval := c.interval
+	if interval == 0 {
+		interval = time.Duration(healthCheck.CheckInterval) * time.Millisecond
+	}
+
+	var (
+		results   []backendResult
+		unhealthy int
+	)
+	for i, b := range backends {
+		client, err := probeClient(b, healthCheck)
+		if err != nil {
+			c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+				"Backend": b.Name,
+			})
+			return fmt.Errorf("error configuring probes of backend %s: %w", b.Name, err)
+		}
+
+		r := backendResult{
+			Backend:     b.Name,
+			HealthCheck: healthCheck.Name,
+			Address:     backend.Address(b),
+		}
+		if !text.IsMachineFormat(c.Globals.Flag.Format) {
+			if i > 0 {
+				text.Break(out)
+			}
+			text.Output(out, "Probing backend %s (%s) with healthcheck %s", r.Backend, r.Address, r.HealthCheck)
+		}
+
+		for w := 0; w < c.windows; w++ {
+			win := window{}
+			for p := uint(0); p < healthCheck.Window; p++ {
+				if w > 0 || p > 0 {
+					time.Sleep(interval)
+				}
+				pr := runProbe(client, b, healthCheck)
+				if pr.Pass {
+					win.Passed++
+				}
+				win.Probes = append(win.Probes, pr)
+				if c.Globals.Verbose() && !text.IsMachineFormat(c.Globals.Flag.Format) {
+					fmt.Fprintf(out, "  %s\n", describeProbe(pr))
+				}
+			}
+			win.Healthy = uint(win.Passed) >= healthCheck.Threshold
+			if !win.Healthy {
+				unhealthy++
+			}
+			r.Windows = append(r.Windows, win)
+
+			if !text.IsMachineFormat(c.Globals.Flag.Format) {
+				state := "healthy"
+				if !win.Healthy {
+					state = "unhealthy"
+				}
+				text.Output(out, "Window %d: %d of %d probes passed (threshold %d): %s", w+1, win.Passed, len(win.Probes), healthCheck.Threshold, state)
+				if !win.Healthy && !c.Globals.Verbose() {
+					for _, pr := range win.Probes {
+						if !pr.Pass {
+							fmt.Fprintf(out, "  %s\n", describeProbe(pr))
+							break
+						}
+					}
+				}
+			}
+		}
+		results = append(results, r)
+	}
+
+	if text.IsMachineFormat(c.Globals.Flag.Format) {
+		if err := text.Render(out, c.Globals.Flag.Format, results); err != nil {
+			return err
+		}
+	}
+
+	if unhealthy > 0 {
+		return fmt.Errorf("%d of %d healthcheck windows were unhealthy", unhealthy, len(backends)*c.windows)
+	}
+	if !text.IsMachineFormat(c.Globals.Flag.Format) {
+		text.Break(out)
+		text.Success(out, "All backends passed healthcheck %s", healthCheck.Name)
+	}
+	return nil
+}
+
+// definitions reads the healthcheck and the backends to probe with it.
+func (c *TestCommand) definitions(serviceID string, serviceVersion int) (*fastly.HealthCheck, []*fastly.Backend, error) {
+	var backends []*fastly.Backend
+	name := c.name
+
+	if c.backend != "" {
+		b, err := c.Globals.Client.GetBackend(&fastly.GetBackendInput{
+			ServiceID:      serviceID,
+			ServiceVersion: serviceVersion,
+			Name:           c.backend,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if b.HealthCheck == "" {
+			return nil, nil, errors.RemediationError{
+				Inner:       fmt.Errorf("backend %s has no healthcheck", b.Name),
+				Remediation: "Set one with `fastly backend update --healthcheck`, or probe the backends using a healthcheck with --name.",
+			}
+		}
+		name = b.HealthCheck
+		backends = append(backends, b)
+	}
+
+	healthCheck, err := c.Globals.Client.GetHealthCheck(&fastly.GetHealthCheckInput{
+		ServiceID:      serviceID,
+		ServiceVersion: serviceVersion,
+		Name:           name,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if healthCheck.Window == 0 {
+		return nil, nil, fmt.Errorf("healthcheck %s has a window of 0 probes", healthCheck.Name)
+	}
+
+	if c.backend == "" {
+		all, err := c.Globals.Client.ListBackends(&fastly.ListBackendsInput{
+			ServiceID:      serviceID,
+			ServiceVersion: serviceVersion,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, b := range all {
+			if b.HealthCheck == healthCheck.Name {
+				backends = append(backends, b)
+			}
+		}
+		if len(backends) == 0 {
+			return nil, nil, errors.RemediationError{
+				Inner:       fmt.Errorf("no backends use healthcheck %s", healthCheck.Name),
+				Remediation: "Probe a backend which doesn't use the healthcheck yet with --backend, after setting it with `fastly backend update --healthcheck`.",
+			}
+		}
+	}
+
+	return healthCheck, backends, nil
+}
+
+// probeClient returns an HTTP client which connects to the backend's address
+// with its TLS settings, regardless of the host the request is for. Each probe
+// makes a new connection, as a healthcheck does.
+func probeClient(b *fastly.Backend, h *fastly.HealthCheck) (*http.Client, error) {
+	dialer := &net.Dialer{
+		Timeout: time.Duration(b.ConnectTimeout) * time.Millisecond,
+	}
+	addr := backend.Address(b)
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, addr)
+		},
+		DisableKeepAlives:     true,
+		ResponseHeaderTimeout: time.Duration(b.FirstByteTimeout) * time.Millisecond,
+	}
+
+	if b.UseSSL {
+		cfg, err := backend.TLSConfig(b)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = cfg
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   time.Duration(h.Timeout) * time.Millisecond,
+		// A healthcheck judges the response it gets, so redirects
+		// aren't followed.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}, nil
+}
+
+// runProbe makes a single healthcheck request of the backend.
+func runProbe(client *http.Client, b *fastly.Backend, h *fastly.HealthCheck) probe {
+	scheme := "http"
+	if b.UseSSL {
+		scheme = "https"
+	}
+	path := h.Path
+	if path == "" {
+		path = "/"
+	}
+	method := h.Method
+	if method == "" {
+		method = http.MethodHead
+	}
+
+	var pr probe
+	req, err := http.NewRequest(method, fmt.Sprintf("%s://%s%s", scheme, backend.Address(b), path), nil)
+	if err != nil {
+		pr.Error = err.Error()
+		return pr
+	}
+	req.Host = firstNonEmpty(h.Host, b.OverrideHost, b.Hostname, b.Address)
+	req.Header.Set("User-Agent", useragent.Name)
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	pr.Duration = time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		pr.Error = err.Error()
+		return pr
+	}
+	defer resp.Body.Close() // #nosec G307
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	expected := h.ExpectedResponse
+	if expected == 0 {
+		expected = http.StatusOK
+	}
+	pr.Status = resp.StatusCode
+	pr.Pass = uint(resp.StatusCode) == expected
+	if !pr.Pass {
+		pr.Error = fmt.Sprintf("expected status %d", expected)
+	}
+	return pr
+}
+
+func describeProbe(pr probe) string {
+	switch {
+	case pr.Status == 0:
+		return fmt.Sprintf("fail: %s (%s)", pr.Error, pr.Duration)
+	case !pr.Pass:
+		return fmt.Sprintf("fail: %d %s, %s (%s)", pr.Status, http.StatusText(pr.Status), pr.Error, pr.Duration)
+	}
+	return fmt.Sprintf("pass: %d %s (%s)", pr.Status, http.StatusText(pr.Status), pr.Duration)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
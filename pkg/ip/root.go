@@ -38,6 +38,13 @@ func (c *RootCommand) Exec(in io.Reader, out io.Writer) error {
 		return err
 	}
 
+	if text.IsMachineFormat(c.Globals.Flag.Format) {
+		return text.Render(out, c.Globals.Flag.Format, struct {
+			IPv4 []string `json:"ipv4"`
+			IPv6 []string `json:"ipv6"`
+		}{ipv4, ipv6})
+	}
+
 	text.Break(out)
 	fmt.Fprintf(out, "%s\n", text.Bold("IPv4"))
 	for _, ip := range ipv4 {
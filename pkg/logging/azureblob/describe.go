@@ -8,6 +8,7 @@ import (
 	"github.com/fastly/cli/pkg/compute/manifest"
 	"github.com/fastly/cli/pkg/config"
 	"github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/text"
 	"github.com/fastly/go-fastly/v3/fastly"
 )
 
@@ -64,6 +65,10 @@ func (c *DescribeCommand) Exec(in io.Reader, out io.Writer) error {
 		return err
 	}
 
+	if text.IsMachineFormat(c.Globals.Flag.Format) {
+		return text.Render(out, c.Globals.Flag.Format, azureblob)
+	}
+
 	fmt.Fprintf(out, "Service ID: %s\n", azureblob.ServiceID)
 	fmt.Fprintf(out, "Version: %d\n", azureblob.ServiceVersion)
 	fmt.Fprintf(out, "Name: %s\n", azureblob.Name)
@@ -0,0 +1,44 @@
+package logs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRotatingFile tests that the output file is rotated once it reaches its
+// maximum size, keeping only the configured number of backups.
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tail.log")
+
+	r, err := newRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		if _, err := fmt.Fprintf(r, "line %d\n", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for file, want := range map[string]string{
+		path:        "line 3\n",
+		path + ".1": "line 2\n",
+		path + ".2": "line 1\n",
+	} {
+		bs, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(bs) != want {
+			t.Errorf("%s: exp: %q != got: %q", file, want, bs)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only two backups, got: %v", err)
+	}
+}
@@ -7,16 +7,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"os"
 	"os/signal"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/fastly/cli/pkg/api"
 	"github.com/fastly/cli/pkg/cmd"
 	"github.com/fastly/cli/pkg/compute/manifest"
 	"github.com/fastly/cli/pkg/config"
@@ -26,29 +31,74 @@ import (
 	"github.com/tomnomnom/linkheader"
 )
 
+// Log output formats.
+const (
+	formatText   = "text"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+	formatRaw    = "raw"
+)
+
+// formats is every supported log output format.
+var formats = []string{formatText, formatJSON, formatNDJSON, formatRaw}
+
 type (
 	// TailCommand represents the CLI subcommand for Log Tailing.
 	TailCommand struct {
 		cmd.Base
 		manifest manifest.Data
-		Input    fastly.CreateManagedLoggingInput
+		cfg      cfg
 
-		cfg cfg
+		serviceIDs         []string // services given by repeated --service-id flags
+		allComputeServices bool     // tail every Compute@Edge service
+		streams            []*stream
 
-		dieCh   chan struct{} // channel to end output/printing
-		batchCh chan Batch    // send batches to output loop
-		doneCh  chan struct{} // channel to signal we've reached the end of the run
+		stderr  io.Writer // where diagnostics go in the machine readable formats
+		diagOut io.Writer // diagnostics, kept apart from the logs
+		logFile io.Writer // the output file, if any
+
+		dieCh      chan struct{} // channel to end output/printing
+		batchCh    chan Batch    // send batches to output loop
+		progressCh chan progress // send stream progress to output loop
+		doneCh     chan struct{} // channel to signal we've reached the end of the run
+
+		hClient api.HTTPClient // TODO: this will go away when GET is in go-fastly
+		token   string         // TODO: this will go away when GET is in go-fastly
+	}
+
+	// stream is the log stream of a single service.
+	stream struct {
+		// serviceID is the service the logs belong to.
+		serviceID string
+		// prefix labels each log in the text and raw formats when more
+		// than one service is tailed.
+		prefix string
+		// label is the prefix without colour, for the output file.
+		label string
+		// path is the full path to fetch.
+		path string
+		// backoff is the delay between retries of failed requests.
+		backoff backoff
+	}
+
+	// progress reports that a stream has sent every log which arrived
+	// before a time, in microseconds, to the output loop.
+	progress struct {
+		serviceID string
+		until     int64
+	}
 
-		hClient *http.Client // TODO: this will go away when GET is in go-fastly
-		token   string       // TODO: this will go away when GET is in go-fastly
+	// mergeBuffer holds the logs of several streams until every stream
+	// has progressed past them, so that they're output in RequestStart
+	// order rather than in the order they were received.
+	mergeBuffer struct {
+		marks map[string]int64
+		logs  []Log
 	}
 
 	// cfg holds the configuration parameters passed in through
 	// command line arguments.
 	cfg struct {
-		// path is the full path to fetch
-		path string
-
 		// from is how far in the past to start showing logs.
 		from int64
 
@@ -67,6 +117,21 @@ type (
 		// customer wants to consume.
 		// Undefined == both stderr and stdout.
 		stream string
+		// format is how each log is printed (text, json, ndjson or raw).
+		format string
+		// search is an optional regular expression which log messages
+		// must match to be printed.
+		search *regexp.Regexp
+		// requestID is an optional RequestID (or prefix of one) which
+		// logs must match to be printed.
+		requestID string
+		// outputFile is an optional file which logs are also written to.
+		outputFile string
+		// outputFileSize is the size, in MB, at which the outputFile is
+		// rotated. Zero disables rotation.
+		outputFileSize int64
+		// outputFileBackups is how many rotated output files to keep.
+		outputFileBackups int
 	}
 
 	// Log defines the message envelope that compute@edge (C@E) wraps the
@@ -85,6 +150,9 @@ type (
 		RequestID string `json:"id"`
 		// Message is the actual message body the user wants printed.
 		Message string `json:"message"`
+		// ServiceID is the service which emitted the log. It's only set
+		// when more than one service is tailed.
+		ServiceID string `json:"service_id,omitempty"`
 	}
 
 	// Batch encompasses a batch ID and the logs for this batch.
@@ -95,13 +163,21 @@ type (
 )
 
 // NewTailCommand returns a usable command registered under the parent.
-func NewTailCommand(parent cmd.Registerer, globals *config.Data) *TailCommand {
+// Diagnostics are written to stderr in the machine readable formats, and
+// discarded if it's nil.
+func NewTailCommand(parent cmd.Registerer, client api.HTTPClient, stderr io.Writer, globals *config.Data) *TailCommand {
 	var c TailCommand
 	c.Globals = globals
+	c.hClient = client
+	c.stderr = stderr
+	if c.stderr == nil {
+		c.stderr = io.Discard
+	}
 	c.manifest.File.SetOutput(c.Globals.Output)
 	c.manifest.File.Read(manifest.Filename)
 	c.CmdClause = parent.Command("tail", "Tail Compute@Edge logs")
-	c.RegisterServiceIDFlag(&c.manifest.Flag.ServiceID)
+	c.CmdClause.Flag("service-id", "Service ID (falls back to FASTLY_SERVICE_ID, then fastly.toml), repeat to tail several services").Short('s').StringsVar(&c.serviceIDs)
+	c.CmdClause.Flag("all-compute-services", "Tail every Compute@Edge service").BoolVar(&c.allComputeServices)
 	c.CmdClause.Flag("from", "From time, in unix seconds").Int64Var(&c.cfg.from)
 	c.CmdClause.Flag("to", "To time, in unix seconds").Int64Var(&c.cfg.to)
 	c.CmdClause.Flag("sort-buffer",
@@ -109,36 +185,58 @@ func NewTailCommand(parent cmd.Registerer, globals *config.Data) *TailCommand {
 	c.CmdClause.Flag("search-padding",
 		"Search padding is how much of a window on either side of From and To to use for searching, defaults to 2s (seconds)").Default("2s").DurationVar(&c.cfg.searchPadding)
 	c.CmdClause.Flag("stream", "Stream specifies which of 'stdout' or 'stderr' to output, defaults to undefined (all streams)").StringVar(&c.cfg.stream)
+	c.CmdClause.Flag("format", "Output format (text, json, ndjson, raw)").Default(formatText).HintOptions(formats...).EnumVar(&c.cfg.format, formats...)
+	c.CmdClause.Flag("search", "Only output logs whose message matches the regular expression").RegexpVar(&c.cfg.search)
+	c.CmdClause.Flag("request-id", "Only output logs for the request ID (or request ID prefix)").StringVar(&c.cfg.requestID)
+	c.CmdClause.Flag("output-file", "Also write logs to the file").StringVar(&c.cfg.outputFile)
+	c.CmdClause.Flag("output-file-size", "Size, in MB, at which the output file is rotated (0 disables rotation)").Default("100").Int64Var(&c.cfg.outputFileSize)
+	c.CmdClause.Flag("output-file-backups", "Number of rotated output files to keep").Default("3").IntVar(&c.cfg.outputFileBackups)
 
 	return &c
 }
 
 // Exec invokes the application logic for the command.
 func (c *TailCommand) Exec(in io.Reader, out io.Writer) error {
-	serviceID, source := c.manifest.ServiceID()
-	if source == manifest.SourceUndefined {
-		return errors.ErrNoServiceID
+	services, err := c.services()
+	if err != nil {
+		return err
 	}
-	c.Input.ServiceID = serviceID
 
-	c.Input.Kind = fastly.ManagedLoggingInstanceOutput
-	c.cfg.path = fmt.Sprintf("%s/service/%s/log_stream/managed/instance_output", config.DefaultEndpoint, c.Input.ServiceID)
+	endpoint, _ := c.Globals.Endpoint()
+	c.streams = newStreams(strings.TrimSuffix(endpoint, "/"), services)
 
 	c.dieCh = make(chan struct{})
 	c.batchCh = make(chan Batch)
+	c.progressCh = make(chan progress)
 	c.doneCh = make(chan struct{})
 
-	c.hClient = http.DefaultClient
 	c.token, _ = c.Globals.Token()
 
 	// Adjust the from/to times if they are
 	// defined. We adjust the times based on searchPadding.
 	c.adjustTimes()
 
+	c.diagOut = c.diagnostics(out)
+
 	// Enable managed logging if not already enabled.
-	if err := c.enableManagedLogging(out); err != nil {
-		c.Globals.ErrLog.Add(err)
-		return err
+	for _, s := range c.streams {
+		if err := c.enableManagedLogging(c.diagOut, s.serviceID); err != nil {
+			c.Globals.ErrLog.Add(err)
+			return err
+		}
+	}
+
+	// Logs are written to the output file as well as to stdout.
+	if c.cfg.outputFile != "" {
+		f, err := newRotatingFile(c.cfg.outputFile, c.cfg.outputFileSize<<20, c.cfg.outputFileBackups)
+		if err != nil {
+			c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+				"Output file": c.cfg.outputFile,
+			})
+			return fmt.Errorf("error opening output file: %w", err)
+		}
+		defer f.Close()
+		c.logFile = f
 	}
 
 	sigs := make(chan os.Signal, 2)
@@ -147,8 +245,22 @@ func (c *TailCommand) Exec(in io.Reader, out io.Writer) error {
 	// Start the output loop.
 	go c.outputLoop(out)
 
-	// Start tailing the logs.
-	go c.tail(out)
+	// Start tailing the logs of each service. Once every tail has reached
+	// the requested 'to' time the output loop is told to finish.
+	var wg sync.WaitGroup
+	for _, s := range c.streams {
+		wg.Add(1)
+		go func(s *stream) {
+			defer wg.Done()
+			c.tail(c.diagOut, s)
+		}(s)
+	}
+	go func() {
+		wg.Wait()
+		if !c.dying() {
+			close(c.doneCh)
+		}
+	}()
 
 	<-sigs
 	close(c.dieCh)
@@ -156,20 +268,31 @@ func (c *TailCommand) Exec(in io.Reader, out io.Writer) error {
 	return nil
 }
 
+// diagnostics returns the writer for messages about the tail itself, such as
+// retries. They'd corrupt the logs in the machine readable formats, so they're
+// written to stderr instead of out.
+func (c *TailCommand) diagnostics(out io.Writer) io.Writer {
+	if c.cfg.format == formatText {
+		return out
+	}
+	return c.stderr
+}
+
 //
 // Client
 //
 
-// Tail starts the virtual tail process. Tail fetches data from the eventbuffer
-// API. It hands off the requested logs to the outputloop for the actual
-// printing.
-func (c *TailCommand) tail(out io.Writer) {
+// Tail starts the virtual tail process for a stream. Tail fetches data from the
+// eventbuffer API. It hands off the requested logs to the outputloop for the
+// actual printing, and returns once the requested 'to' time is reached.
+// Diagnostics are written to out.
+func (c *TailCommand) tail(out io.Writer, s *stream) {
 	// Start this with --from and --to if set.
 	curWindow := c.cfg.from
 	toWindow := c.cfg.to
 
 	// Start the loop with an initial address to query.
-	path := makeNewPath(out, c.cfg.path, curWindow, "")
+	path := makeNewPath(out, s.path, curWindow, "")
 
 	// lastBatchID keeps the last successfully read Batch.ID in case we need
 	// re-request on failure.
@@ -179,9 +302,10 @@ func (c *TailCommand) tail(out io.Writer) {
 		// Check to see if we already passed the "to" requirement.
 		if toWindow != 0 && curWindow > toWindow {
 			text.Info(out, "Reached window: %v which is newer than the requested 'to': %v", curWindow, toWindow)
-			// We are done, but we still want printing to finish.
-			close(c.doneCh)
-			break
+			// We are done, but we still want printing to finish,
+			// without waiting for this stream.
+			c.progress(s, math.MaxInt64)
+			return
 		}
 
 		req, err := http.NewRequest("GET", path, nil)
@@ -196,9 +320,19 @@ func (c *TailCommand) tail(out io.Writer) {
 
 		resp, err := c.doReq(req)
 		if err != nil {
+			// The request was cancelled because we're exiting.
+			if c.dying() {
+				return
+			}
+
+			// Network errors are usually transient, so reconnect
+			// after waiting for some time.
 			c.Globals.ErrLog.Add(err)
-			text.Error(out, "unable to execute request: %v", err)
-			os.Exit(1)
+			text.Warning(out, "unable to execute request, retrying: %v", err)
+			if !c.wait(&s.backoff) {
+				return
+			}
+			continue
 		}
 
 		// Check that our request was successful. If the server is
@@ -223,10 +357,12 @@ func (c *TailCommand) tail(out io.Writer) {
 			io.Copy(io.Discard, resp.Body)
 			resp.Body.Close()
 
-			// Try the response again after a 1 second wait.
+			// Try the request again after a backoff.
 			if resp.StatusCode/100 == 5 && resp.StatusCode != 501 ||
 				resp.StatusCode == 429 {
-				time.Sleep(1 * time.Second)
+				if !c.wait(&s.backoff) {
+					return
+				}
 				continue
 			}
 
@@ -235,6 +371,10 @@ func (c *TailCommand) tail(out io.Writer) {
 			os.Exit(1)
 		}
 
+		// The server is healthy again, so the next failure starts with
+		// the shortest delay.
+		s.backoff.reset()
+
 		// Read and parse response, send batches to the output loop.
 		scanner := bufio.NewScanner(resp.Body)
 
@@ -264,6 +404,13 @@ func (c *TailCommand) tail(out io.Writer) {
 				// anything fails along the way, we
 				// can re-request.
 				lastBatchID = batch.ID
+				// Label the logs when merging several
+				// services into one output.
+				if len(c.streams) > 1 {
+					for i := range batch.Logs {
+						batch.Logs[i].ServiceID = s.serviceID
+					}
+				}
 				// Send batch down batchCh to the output loop.
 				c.batchCh <- batch
 			}
@@ -293,6 +440,8 @@ func (c *TailCommand) tail(out io.Writer) {
 				"Next link": next,
 			})
 			text.Error(out, "error generating window from next link")
+		} else {
+			c.progress(s, curWindow*int64(time.Second/time.Microsecond))
 		}
 
 		// We do NOT want to specify a batchID, as this
@@ -302,6 +451,65 @@ func (c *TailCommand) tail(out io.Writer) {
 	}
 }
 
+// progress tells the output loop that the stream has sent every log which
+// arrived before until, in microseconds, when several streams are merged.
+func (c *TailCommand) progress(s *stream, until int64) {
+	if len(c.streams) < 2 {
+		return
+	}
+	select {
+	case c.progressCh <- progress{serviceID: s.serviceID, until: until}:
+	case <-c.dieCh:
+	}
+}
+
+// wait sleeps for the next backoff delay, returning false if the tail was
+// stopped while waiting.
+func (c *TailCommand) wait(b *backoff) bool {
+	select {
+	case <-time.After(b.next()):
+		return true
+	case <-c.dieCh:
+		return false
+	}
+}
+
+// dying reports whether the tail has been stopped.
+func (c *TailCommand) dying() bool {
+	select {
+	case <-c.dieCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff computes jittered, exponentially increasing delays between retries.
+type backoff struct {
+	min, max time.Duration
+	attempt  int
+}
+
+// next returns the delay before the next retry, which is a random duration
+// between half and all of min doubled for each consecutive retry (up to max).
+func (b *backoff) next() time.Duration {
+	d := b.max
+	if b.attempt < 32 {
+		if e := b.min << b.attempt; e > 0 && e < b.max {
+			d = e
+		}
+	}
+	b.attempt++
+
+	half := int64(d / 2)
+	return time.Duration(half + rand.Int63n(half+1)) // #nosec G404
+}
+
+// reset restores the backoff to its initial delay.
+func (b *backoff) reset() {
+	b.attempt = 0
+}
+
 // adjustTimes adjusts the passed in from and to flags based on the
 // specified padding.
 func (c *TailCommand) adjustTimes() {
@@ -317,15 +525,107 @@ func (c *TailCommand) adjustTimes() {
 	}
 }
 
+// services returns the services to tail, mapped to their names where known.
+func (c *TailCommand) services() (map[string]string, error) {
+	if c.allComputeServices {
+		if len(c.serviceIDs) > 0 {
+			return nil, errors.RemediationError{
+				Inner:       fmt.Errorf("--service-id and --all-compute-services can't be used together"),
+				Remediation: "Either list the services to tail with --service-id, or tail every Compute@Edge service with --all-compute-services.",
+			}
+		}
+
+		all, err := c.Globals.Client.ListServices(&fastly.ListServicesInput{})
+		if err != nil {
+			c.Globals.ErrLog.Add(err)
+			return nil, err
+		}
+		services := make(map[string]string)
+		for _, s := range all {
+			if s.Type == "wasm" {
+				services[s.ID] = s.Name
+			}
+		}
+		if len(services) == 0 {
+			return nil, fmt.Errorf("no Compute@Edge services found")
+		}
+		return services, nil
+	}
+
+	if len(c.serviceIDs) > 1 {
+		services := make(map[string]string)
+		for _, id := range c.serviceIDs {
+			services[id] = ""
+		}
+		return services, nil
+	}
+
+	// A single service falls back to the environment and manifest.
+	if len(c.serviceIDs) == 1 {
+		c.manifest.Flag.ServiceID = c.serviceIDs[0]
+	}
+	serviceID, source := c.manifest.ServiceID()
+	if source == manifest.SourceUndefined {
+		return nil, errors.ErrNoServiceID
+	}
+	return map[string]string{serviceID: ""}, nil
+}
+
+// newStreams returns a stream, ordered by label, for each service. When there
+// is more than one service, each is given a distinctly coloured prefix of its
+// name (or ID if it has no name), padded to align the output.
+func newStreams(endpoint string, services map[string]string) []*stream {
+	labels := make(map[string]string, len(services))
+	var width int
+	for id, name := range services {
+		label := name
+		if label == "" {
+			label = id
+		}
+		labels[id] = label
+		if len(label) > width {
+			width = len(label)
+		}
+	}
+
+	streams := make([]*stream, 0, len(services))
+	for id := range services {
+		streams = append(streams, &stream{
+			serviceID: id,
+			path:      fmt.Sprintf("%s/service/%s/log_stream/managed/instance_output", endpoint, id),
+			backoff:   backoff{min: 500 * time.Millisecond, max: 30 * time.Second},
+		})
+	}
+	sort.Slice(streams, func(i, j int) bool {
+		a, b := streams[i], streams[j]
+		if labels[a.serviceID] != labels[b.serviceID] {
+			return labels[a.serviceID] < labels[b.serviceID]
+		}
+		return a.serviceID < b.serviceID
+	})
+
+	if len(streams) > 1 {
+		for i, s := range streams {
+			colour := text.Palette[i%len(text.Palette)]
+			s.label = fmt.Sprintf("%-*s", width, labels[s.serviceID])
+			s.prefix = colour(s.label)
+		}
+	}
+	return streams
+}
+
 // enableManagedLogging enables managed logging in our API.
-func (c *TailCommand) enableManagedLogging(out io.Writer) error {
-	_, err := c.Globals.Client.CreateManagedLogging(&c.Input)
+func (c *TailCommand) enableManagedLogging(out io.Writer, serviceID string) error {
+	_, err := c.Globals.Client.CreateManagedLogging(&fastly.CreateManagedLoggingInput{
+		ServiceID: serviceID,
+		Kind:      fastly.ManagedLoggingInstanceOutput,
+	})
 	if err != nil && err != fastly.ErrManagedLoggingEnabled {
 		c.Globals.ErrLog.Add(err)
 		return err
 	}
 
-	text.Info(out, "Managed logging enabled on service %s", c.Input.ServiceID)
+	text.Info(out, "Managed logging enabled on service %s", serviceID)
 	return nil
 }
 
@@ -355,6 +655,57 @@ func (c *TailCommand) outputLoop(out io.Writer) {
 	// well recording when logs were received.
 	logmap := make(map[string]logrecv)
 
+	// Logs from several services are held until every service has
+	// progressed past them.
+	merge := newMergeBuffer(c.streams)
+
+	// expire removes the logs whose buffer period has ended, when a timer
+	// expires for a particular request, and returns them for printing.
+	expire := func(bufdLogs bufferedLog) []Log {
+		reqID, seq := bufdLogs.reqID, bufdLogs.seq
+
+		// Get the logs for this RequestID and
+		// find the index of the sequence in our current logs.
+		reqLogs := logmap[reqID]
+		idx := findIdxBySeq(reqLogs.logs, seq)
+
+		// Split off the source of this timer, leave
+		// remaining logs to be printed later.
+		toPrint, remainingLogs := reqLogs.logs[:idx], reqLogs.logs[idx:]
+		reqLogs.logs = remainingLogs
+
+		// Special case if we just expired the entire set of
+		// logs, we remove the keys from the maps and finish.
+		if len(remainingLogs) == 0 {
+			delete(logmap, reqID)
+			return toPrint
+		}
+
+		// Drop the front of the batchReqReceives map and start
+		// another timer for any remaining recorded sequences.
+		recv := reqLogs.receives[1:]
+		reqLogs.receives = recv
+
+		// If anything is left...
+		if len(recv) > 0 {
+			// We create a new timer, we subtract
+			// off time already served from the
+			// user defined sortBuffer.
+			time.AfterFunc(c.cfg.sortBuffer-time.Since(recv[0].when), func() {
+				tdCh <- bufferedLog{
+					reqID: reqID,
+					seq:   recv[0].highSeq,
+				}
+			})
+		}
+
+		// Set the new log and receive info back to the
+		// logmap for this RequestID.
+		logmap[reqID] = reqLogs
+
+		return toPrint
+	}
+
 	for {
 		select {
 		case <-c.dieCh:
@@ -417,47 +768,12 @@ func (c *TailCommand) outputLoop(out io.Writer) {
 			}
 
 		case bufdLogs := <-tdCh: // A timer expired for a particular request.
-			reqID, seq := bufdLogs.reqID, bufdLogs.seq
-
-			// Get the logs for this RequestID and
-			// find the index of the sequence in our current logs.
-			reqLogs := logmap[reqID]
-			idx := findIdxBySeq(reqLogs.logs, seq)
-
-			// Split off the source of this timer, leave
-			// remaining logs to be printed later.
-			toPrint, remainingLogs := reqLogs.logs[:idx], reqLogs.logs[idx:]
-			reqLogs.logs = remainingLogs
-			c.printLogs(out, toPrint)
-
-			// Special case if we just printed the entire set of
-			// logs, we remove the keys from the maps and finish.
-			if len(remainingLogs) == 0 {
-				delete(logmap, reqID)
-				break
-			}
+			merge.add(expire(bufdLogs))
+			c.printLogs(out, merge.ready())
 
-			// Drop the front of the batchReqReceives map and start
-			// another timer for any remaining recorded sequences.
-			recv := reqLogs.receives[1:]
-			reqLogs.receives = recv
-
-			// If anything is left...
-			if len(recv) > 0 {
-				// We create a new timer, we subtract
-				// off time already served from the
-				// user defined sortBuffer.
-				time.AfterFunc(c.cfg.sortBuffer-time.Since(recv[0].when), func() {
-					tdCh <- bufferedLog{
-						reqID: reqID,
-						seq:   recv[0].highSeq,
-					}
-				})
-			}
-
-			// Set the new log and receive info back to the
-			// logmap for this RequestID.
-			logmap[reqID] = reqLogs
+		case p := <-c.progressCh: // A stream has progressed.
+			merge.advance(p.serviceID, p.until)
+			c.printLogs(out, merge.ready())
 
 		case <-c.doneCh:
 			os.Exit(0)
@@ -465,16 +781,88 @@ func (c *TailCommand) outputLoop(out io.Writer) {
 	}
 }
 
+// newMergeBuffer returns a buffer which merges the logs of the streams. A
+// single stream's logs are never held.
+func newMergeBuffer(streams []*stream) *mergeBuffer {
+	m := &mergeBuffer{marks: make(map[string]int64)}
+	if len(streams) > 1 {
+		for _, s := range streams {
+			m.marks[s.serviceID] = 0
+		}
+	}
+	return m
+}
+
+// add buffers logs whose sort buffer period has ended. The sort is stable, so
+// each request's logs stay in sequence order.
+func (m *mergeBuffer) add(logs []Log) {
+	m.logs = append(m.logs, logs...)
+	sort.SliceStable(m.logs, func(i, j int) bool {
+		return m.logs[i].RequestStart < m.logs[j].RequestStart
+	})
+}
+
+// advance records that a stream has sent every log which arrived before
+// until.
+func (m *mergeBuffer) advance(serviceID string, until int64) {
+	if until > m.marks[serviceID] {
+		m.marks[serviceID] = until
+	}
+}
+
+// ready removes and returns the buffered logs which started before the
+// watermark, the time every stream has progressed past.
+func (m *mergeBuffer) ready() []Log {
+	watermark := int64(math.MaxInt64)
+	for _, until := range m.marks {
+		if until < watermark {
+			watermark = until
+		}
+	}
+
+	i := sort.Search(len(m.logs), func(i int) bool {
+		return m.logs[i].RequestStart >= watermark
+	})
+	if watermark == math.MaxInt64 {
+		i = len(m.logs)
+	}
+	ready := m.logs[:i:i]
+	m.logs = m.logs[i:]
+	return ready
+}
+
 // printLogs is a simple printer for Log slices, only printing requested
-// streams.
+// streams and logs which match the search and request ID filters. The logs are
+// also written to the output file, if any, without colour.
 func (c *TailCommand) printLogs(out io.Writer, logs []Log) {
 	if len(logs) > 0 {
 		filtered := filterStream(c.cfg.stream, logs)
+		filtered = filterRequestID(c.cfg.requestID, filtered)
+		filtered = filterSearch(c.cfg.search, filtered)
 
 		for _, l := range filtered {
-			fmt.Fprintln(out, l.String())
+			prefix, label := c.prefix(l.ServiceID)
+			err := writeLog(out, c.cfg.format, prefix, l)
+			if err == nil && c.logFile != nil {
+				err = writeLog(c.logFile, c.cfg.format, label, l)
+			}
+			if err != nil {
+				c.Globals.ErrLog.Add(err)
+				text.Warning(c.diagOut, "error writing log: %v", err)
+			}
+		}
+	}
+}
+
+// prefix returns the coloured and uncoloured labels of the service's logs, if
+// any.
+func (c *TailCommand) prefix(serviceID string) (prefix, label string) {
+	for _, s := range c.streams {
+		if s.serviceID == serviceID {
+			return s.prefix, s.label
 		}
 	}
+	return "", ""
 }
 
 // doReq runs the http.Request, returning a http.Response or error.
@@ -506,6 +894,35 @@ func (l *Log) RequestStartFromRaw() time.Time {
 	return time.Unix(0, nano)
 }
 
+// writeLog writes a log in the given output format. A prefix, if any, labels
+// the text and raw formats.
+func writeLog(out io.Writer, format, prefix string, l Log) error {
+	if prefix != "" {
+		prefix += " | "
+	}
+
+	var err error
+	switch format {
+	case formatJSON:
+		var bs []byte
+		bs, err = json.MarshalIndent(l, "", "  ")
+		if err == nil {
+			_, err = fmt.Fprintf(out, "%s\n", bs)
+		}
+	case formatNDJSON:
+		var bs []byte
+		bs, err = json.Marshal(l)
+		if err == nil {
+			_, err = fmt.Fprintf(out, "%s\n", bs)
+		}
+	case formatRaw:
+		_, err = fmt.Fprintln(out, prefix+l.Message)
+	default:
+		_, err = fmt.Fprintln(out, prefix+l.String())
+	}
+	return err
+}
+
 // String is used to print a log for the tail output.
 func (l *Log) String() string {
 	// Trim the RequestID for nicer output, it might be a long UUID.
@@ -585,6 +1002,37 @@ func filterStream(stream string, logs []Log) []Log {
 	return out
 }
 
+// filterRequestID returns only logs whose RequestID starts with the requested
+// ID, so the truncated IDs printed by the text format can be used.
+func filterRequestID(id string, logs []Log) []Log {
+	if id == "" {
+		return logs
+	}
+
+	var out []Log
+	for _, l := range logs {
+		if strings.HasPrefix(l.RequestID, id) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// filterSearch returns only logs whose Message matches the search.
+func filterSearch(search *regexp.Regexp, logs []Log) []Log {
+	if search == nil {
+		return logs
+	}
+
+	var out []Log
+	for _, l := range logs {
+		if search.MatchString(l.Message) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
 // getTimeFromLink splits a link header format, returning
 // the time.
 func getTimeFromLink(link string) (int64, error) {
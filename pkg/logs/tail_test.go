@@ -1,12 +1,25 @@
 package logs
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
 	"net/http"
 	"os"
 	"reflect"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/fastly/cli/pkg/config"
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/mock"
+	"github.com/fastly/go-fastly/v3/fastly"
+	"github.com/fatih/color"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -340,3 +353,443 @@ func TestHighSequence(t *testing.T) {
 		}
 	}
 }
+
+// TestFilterRequestID tests that logs are filtered by a full or partial
+// RequestID.
+func TestFilterRequestID(t *testing.T) {
+	logs := []Log{
+		{RequestID: "41f82900-5831-49fe-b094-7435908ba1fb"},
+		{RequestID: "2bef4613-5831-49fe-b094-7435908ba1fb"},
+		{RequestID: "41f82900-5831-49fe-b094-7435908ba1fb"},
+	}
+	for i, test := range []struct {
+		id     string
+		explen int
+	}{
+		{id: "", explen: 3},
+		{id: "41f82900", explen: 2},
+		{id: "2bef4613-5831-49fe-b094-7435908ba1fb", explen: 1},
+		{id: "00000000", explen: 0},
+	} {
+		out := filterRequestID(test.id, logs)
+		if len(out) != test.explen {
+			t.Errorf("#%d: exp: %d != got: %d", i, test.explen, len(out))
+		}
+	}
+}
+
+// TestFilterSearch tests that logs are filtered by a regular expression
+// matching their Message.
+func TestFilterSearch(t *testing.T) {
+	logs := []Log{
+		{Message: "GET /foo 200"},
+		{Message: "GET /bar 503"},
+		{Message: "POST /foo 500"},
+	}
+	for i, test := range []struct {
+		search *regexp.Regexp
+		explen int
+	}{
+		{explen: 3},
+		{search: regexp.MustCompile(`5\d\d$`), explen: 2},
+		{search: regexp.MustCompile(`^GET /foo`), explen: 1},
+		{search: regexp.MustCompile(`DELETE`), explen: 0},
+	} {
+		out := filterSearch(test.search, logs)
+		if len(out) != test.explen {
+			t.Errorf("#%d: exp: %d != got: %d", i, test.explen, len(out))
+		}
+	}
+}
+
+// TestWriteLog tests each of the log output formats.
+func TestWriteLog(t *testing.T) {
+	l := Log{SequenceNum: 1, RequestStart: 1601645172164667, Stream: "stdout", RequestID: "44a1eedd-5831-49fe-b094-7435908ba1fb", Message: "hello"}
+	for _, test := range []struct {
+		format string
+		prefix string
+		want   string
+	}{
+		{format: formatText, want: "stdout | 44a1eedd | hello\n"},
+		{format: formatText, prefix: "api", want: "api | stdout | 44a1eedd | hello\n"},
+		{format: formatRaw, want: "hello\n"},
+		{format: formatRaw, prefix: "api", want: "api | hello\n"},
+		{format: formatNDJSON, prefix: "api", want: `{"sequence_number":1,"request_start_us":1601645172164667,"stream":"stdout","id":"44a1eedd-5831-49fe-b094-7435908ba1fb","message":"hello"}` + "\n"},
+		{format: formatNDJSON, want: `{"sequence_number":1,"request_start_us":1601645172164667,"stream":"stdout","id":"44a1eedd-5831-49fe-b094-7435908ba1fb","message":"hello"}` + "\n"},
+		{format: formatJSON, want: "{\n  \"sequence_number\": 1,\n  \"request_start_us\": 1601645172164667,\n  \"stream\": \"stdout\",\n  \"id\": \"44a1eedd-5831-49fe-b094-7435908ba1fb\",\n  \"message\": \"hello\"\n}\n"},
+	} {
+		var buf bytes.Buffer
+		if err := writeLog(&buf, test.format, test.prefix, l); err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.format, err)
+		}
+		if diff := cmp.Diff(test.want, buf.String()); diff != "" {
+			t.Errorf("%s: writeLog mismatch (-want +got):\n%s", test.format, diff)
+		}
+	}
+}
+
+// TestBackoff tests that retry delays grow exponentially, within the jitter
+// bounds, up to the maximum.
+func TestBackoff(t *testing.T) {
+	b := backoff{min: 100 * time.Millisecond, max: time.Second}
+	for i, max := range []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	} {
+		if d := b.next(); d < max/2 || d > max {
+			t.Errorf("#%d: exp: %v-%v got: %v", i, max/2, max, d)
+		}
+	}
+
+	b.reset()
+	if d := b.next(); d > 100*time.Millisecond {
+		t.Errorf("exp: delay to be reset, got: %v", d)
+	}
+}
+
+// tailClient is an api.HTTPClient which returns canned responses to the
+// tail requests it receives.
+type tailClient struct {
+	requests  []*http.Request
+	responses []func() (*http.Response, error)
+}
+
+func (c *tailClient) Do(req *http.Request) (*http.Response, error) {
+	c.requests = append(c.requests, req)
+	resp := c.responses[0]
+	c.responses = c.responses[1:]
+	return resp()
+}
+
+// TestTailRetries tests that the tail loop uses the configured endpoint and
+// client, and reconnects after network errors and 5xx responses.
+func TestTailRetries(t *testing.T) {
+	data, err := os.ReadFile(responseFile)
+	if err != nil {
+		t.Fatalf("cannot read from file: %v", err)
+	}
+	// The stream contains one batch per line.
+	var body bytes.Buffer
+	if err := json.Compact(&body, data); err != nil {
+		t.Fatal(err)
+	}
+
+	client := &tailClient{
+		responses: []func() (*http.Response, error){
+			func() (*http.Response, error) {
+				return nil, errors.New("connection reset by peer")
+			},
+			func() (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(""))}, nil
+			},
+			func() (*http.Response, error) {
+				header := make(http.Header)
+				header.Set("Link", `</service/123/log_stream/managed/instance_output%3Ffrom=1601412641>; rel="next"`)
+				return &http.Response{StatusCode: http.StatusOK, Header: header, Body: io.NopCloser(&body)}, nil
+			},
+		},
+	}
+
+	s := &stream{
+		serviceID: "123",
+		path:      "https://proxy.example.com/service/123/log_stream/managed/instance_output",
+		backoff:   backoff{min: time.Millisecond, max: 2 * time.Millisecond},
+	}
+	c := TailCommand{
+		cfg:     cfg{to: 1601412640},
+		dieCh:   make(chan struct{}),
+		batchCh: make(chan Batch),
+		hClient: client,
+		token:   "123",
+	}
+	c.Globals = &config.Data{ErrLog: fsterr.Log}
+
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		c.tail(&out, s)
+		close(done)
+	}()
+
+	select {
+	case batch := <-c.batchCh:
+		if batch.ID != "MC0x" {
+			t.Errorf("exp: batch MC0x, got: %s", batch.ID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a batch")
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the tail to finish")
+	}
+
+	if len(client.requests) != 3 {
+		t.Fatalf("exp: 3 requests, got: %d", len(client.requests))
+	}
+	for i, req := range client.requests {
+		if req.URL.Host != "proxy.example.com" {
+			t.Errorf("#%d: exp: host proxy.example.com, got: %s", i, req.URL.Host)
+		}
+		if key := req.Header.Get("Fastly-Key"); key != "123" {
+			t.Errorf("#%d: exp: Fastly-Key 123, got: %s", i, key)
+		}
+	}
+}
+
+// syncBuffer is a bytes.Buffer which is safe to use from several goroutines.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// TestTailMachineFormat tests that diagnostics, such as retries, don't corrupt
+// the logs in the machine readable formats.
+func TestTailMachineFormat(t *testing.T) {
+	data, err := os.ReadFile(responseFile)
+	if err != nil {
+		t.Fatalf("cannot read from file: %v", err)
+	}
+	var body bytes.Buffer
+	if err := json.Compact(&body, data); err != nil {
+		t.Fatal(err)
+	}
+
+	client := &tailClient{
+		responses: []func() (*http.Response, error){
+			func() (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusInternalServerError, Body: io.NopCloser(strings.NewReader(""))}, nil
+			},
+			func() (*http.Response, error) {
+				header := make(http.Header)
+				header.Set("Link", `</service/123/log_stream/managed/instance_output%3Ffrom=1601412641>; rel="next"`)
+				return &http.Response{StatusCode: http.StatusOK, Header: header, Body: io.NopCloser(&body)}, nil
+			},
+		},
+	}
+
+	s := &stream{
+		serviceID: "123",
+		path:      "https://api.example.com/service/123/log_stream/managed/instance_output",
+		backoff:   backoff{min: time.Millisecond, max: 2 * time.Millisecond},
+	}
+	var stdout, stderr syncBuffer
+	c := TailCommand{
+		cfg:     cfg{format: formatNDJSON, to: 1601412640, sortBuffer: time.Millisecond},
+		streams: []*stream{s},
+		stderr:  &stderr,
+		dieCh:   make(chan struct{}),
+		batchCh: make(chan Batch),
+		hClient: client,
+		token:   "123",
+	}
+	c.Globals = &config.Data{
+		ErrLog: fsterr.Log,
+		Client: mock.API{
+			CreateManagedLoggingFn: func(*fastly.CreateManagedLoggingInput) (*fastly.ManagedLogging, error) {
+				return nil, fastly.ErrManagedLoggingEnabled
+			},
+		},
+	}
+
+	c.diagOut = c.diagnostics(&stdout)
+	if err := c.enableManagedLogging(c.diagOut, s.serviceID); err != nil {
+		t.Fatal(err)
+	}
+	go c.outputLoop(&stdout)
+	c.tail(c.diagOut, s)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for stdout.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(c.dieCh)
+
+	out := stdout.String()
+	if out == "" {
+		t.Fatal("timed out waiting for the logs")
+	}
+	for i, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		var l Log
+		if err := json.Unmarshal([]byte(line), &l); err != nil {
+			t.Errorf("line %d: exp: a JSON log, got: %q (%v)", i+1, line, err)
+		}
+	}
+	for _, exp := range []string{"Managed logging enabled on service 123", "non-200 resp 500"} {
+		if !strings.Contains(stderr.String(), exp) {
+			t.Errorf("exp: %q on stderr, got: %q", exp, stderr.String())
+		}
+	}
+}
+
+// TestPrintLogsOutputFile tests that the labels of the logs written to the
+// output file aren't coloured.
+func TestPrintLogsOutputFile(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = false
+
+	streams := newStreams("https://api.example.com", map[string]string{"123": "api", "456": "www"})
+	var stdout, file bytes.Buffer
+	c := TailCommand{
+		cfg:     cfg{format: formatText},
+		streams: streams,
+		logFile: &file,
+	}
+	c.Globals = &config.Data{ErrLog: fsterr.Log}
+	c.diagOut = c.diagnostics(&stdout)
+
+	c.printLogs(&stdout, []Log{{SequenceNum: 1, Stream: "stdout", RequestID: "44a1eedd", Message: "hello", ServiceID: "123"}})
+
+	if !strings.Contains(stdout.String(), "\x1b[") {
+		t.Errorf("exp: a coloured label on stdout, got: %q", stdout.String())
+	}
+	if exp := "api | stdout | 44a1eedd | hello\n"; file.String() != exp {
+		t.Errorf("exp: %q in the output file, got: %q", exp, file.String())
+	}
+}
+
+// TestNewStreams tests that each service gets a stream, and that only
+// multiple streams are labelled.
+func TestNewStreams(t *testing.T) {
+	color.NoColor = true
+
+	single := newStreams("https://api.example.com", map[string]string{"123": ""})
+	if len(single) != 1 || single[0].prefix != "" {
+		t.Fatalf("exp: one unlabelled stream, got: %#v", single)
+	}
+	if exp := "https://api.example.com/service/123/log_stream/managed/instance_output"; single[0].path != exp {
+		t.Errorf("exp: path %s, got: %s", exp, single[0].path)
+	}
+
+	streams := newStreams("https://api.example.com", map[string]string{
+		"456": "checkout",
+		"123": "",
+		"789": "api",
+	})
+	var got []string
+	for _, s := range streams {
+		got = append(got, s.serviceID+"="+s.prefix)
+	}
+	exp := []string{"123=123     ", "789=api     ", "456=checkout"}
+	if diff := cmp.Diff(exp, got); diff != "" {
+		t.Errorf("newStreams mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestMergeBuffer tests that the logs of several streams are held until every
+// stream has progressed past them, and are then output in RequestStart order.
+func TestMergeBuffer(t *testing.T) {
+	m := newMergeBuffer([]*stream{{serviceID: "123"}, {serviceID: "456"}})
+	ids := func(logs []Log) []string {
+		got := []string{}
+		for _, l := range logs {
+			got = append(got, l.RequestID)
+		}
+		return got
+	}
+
+	m.add([]Log{{RequestID: "a2", RequestStart: 20, ServiceID: "123"}, {RequestID: "a3", RequestStart: 30, ServiceID: "123"}})
+	m.advance("123", 40)
+	if got := ids(m.ready()); len(got) != 0 {
+		t.Fatalf("exp: no logs before 456 progresses, got: %v", got)
+	}
+
+	// 456's earlier request is received later, but is output first.
+	m.add([]Log{{RequestID: "b1", RequestStart: 10, ServiceID: "456"}, {RequestID: "b1", RequestStart: 10, ServiceID: "456"}})
+	m.advance("456", 25)
+	if diff := cmp.Diff([]string{"b1", "b1", "a2"}, ids(m.ready())); diff != "" {
+		t.Errorf("ready mismatch (-want +got):\n%s", diff)
+	}
+
+	// A stream which has finished no longer holds the others back.
+	m.advance("456", math.MaxInt64)
+	if diff := cmp.Diff([]string{"a3"}, ids(m.ready())); diff != "" {
+		t.Errorf("ready mismatch (-want +got):\n%s", diff)
+	}
+
+	// A single stream's logs are never held.
+	single := newMergeBuffer([]*stream{{serviceID: "123"}})
+	single.add([]Log{{RequestID: "a1", RequestStart: 10}})
+	if diff := cmp.Diff([]string{"a1"}, ids(single.ready())); diff != "" {
+		t.Errorf("ready mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestServices tests how the services to tail are chosen.
+func TestServices(t *testing.T) {
+	listServices := func(i *fastly.ListServicesInput) ([]*fastly.Service, error) {
+		return []*fastly.Service{
+			{ID: "123", Name: "api", Type: "wasm"},
+			{ID: "456", Name: "www", Type: "vcl"},
+			{ID: "789", Name: "checkout", Type: "wasm"},
+		}, nil
+	}
+	for _, test := range []struct {
+		name       string
+		serviceIDs []string
+		all        bool
+		exp        map[string]string
+		wantError  string
+	}{
+		{
+			name:       "single",
+			serviceIDs: []string{"123"},
+			exp:        map[string]string{"123": ""},
+		},
+		{
+			name:       "multiple",
+			serviceIDs: []string{"123", "456", "123"},
+			exp:        map[string]string{"123": "", "456": ""},
+		},
+		{
+			name: "all compute services",
+			all:  true,
+			exp:  map[string]string{"123": "api", "789": "checkout"},
+		},
+		{
+			name:       "all compute services and service ID",
+			serviceIDs: []string{"123"},
+			all:        true,
+			wantError:  "can't be used together",
+		},
+		{
+			name:      "no service",
+			wantError: fsterr.ErrNoServiceID.Error(),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c := TailCommand{serviceIDs: test.serviceIDs, allComputeServices: test.all}
+			c.Globals = &config.Data{
+				Client: mock.API{ListServicesFn: listServices},
+				ErrLog: fsterr.Log,
+			}
+			services, err := c.services()
+			if test.wantError == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if test.wantError != "" && (err == nil || !strings.Contains(err.Error(), test.wantError)) {
+				t.Fatalf("exp: error containing %q, got: %v", test.wantError, err)
+			}
+			if diff := cmp.Diff(test.exp, services); diff != "" {
+				t.Errorf("services mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package mock
+
+import (
+	"github.com/fastly/go-fastly/v3/fastly"
+)
+
+// RealtimeStatsAPI is a mock implementation of api.RealtimeStatsInterface
+// that's used for testing. The zero value is useful, but will panic on all
+// methods. Provide function implementations for the method(s) your test will
+// call.
+type RealtimeStatsAPI struct {
+	GetRealtimeStatsJSONFn func(i *fastly.GetRealtimeStatsInput, dst interface{}) error
+}
+
+// GetRealtimeStatsJSON implements api.RealtimeStatsInterface.
+func (m RealtimeStatsAPI) GetRealtimeStatsJSON(i *fastly.GetRealtimeStatsInput, dst interface{}) error {
+	return m.GetRealtimeStatsJSONFn(i, dst)
+}
@@ -11,8 +11,7 @@ import (
 )
 
 func TestAllDatacenters(t *testing.T) {
-	var stdout bytes.Buffer
-	args := testutil.Args("pops --token 123")
+	args := testutil.Args
 	api := mock.API{
 		AllDatacentersFn: func() ([]fastly.Datacenter, error) {
 			return []fastly.Datacenter{
@@ -31,9 +30,43 @@ func TestAllDatacenters(t *testing.T) {
 			}, nil
 		},
 	}
-	opts := testutil.NewRunOpts(args, &stdout)
-	opts.APIClient = mock.APIClient(api)
-	err := app.Run(opts)
-	testutil.AssertNoError(t, err)
-	testutil.AssertString(t, "\nNAME    CODE  GROUP  SHIELD  COORDINATES\nFoobar  FBR   Bar    Baz     {Latitude:1 Longtitude:2 X:3 Y:4}\n", stdout.String())
+
+	for _, testcase := range []struct {
+		args       []string
+		wantOutput string
+	}{
+		{
+			args:       args("pops --token 123"),
+			wantOutput: "\nNAME    CODE  GROUP  SHIELD  COORDINATES\nFoobar  FBR   Bar    Baz     {Latitude:1 Longtitude:2 X:3 Y:4}\n",
+		},
+		{
+			args: args("pops --token 123 --output json"),
+			wantOutput: `[
+  {
+    "code": "FBR",
+    "coordinates": {
+      "latitude": 1,
+      "longitude": 2,
+      "x": 3,
+      "y": 4
+    },
+    "group": "Bar",
+    "name": "Foobar",
+    "shield": "Baz"
+  }
+]
+`,
+		},
+		{
+			args:       args("pops --token 123 --output csv"),
+			wantOutput: "code,coordinates.latitude,coordinates.longitude,coordinates.x,coordinates.y,group,name,shield\nFBR,1,2,3,4,Bar,Foobar,Baz\n",
+		},
+	} {
+		var stdout bytes.Buffer
+		opts := testutil.NewRunOpts(testcase.args, &stdout)
+		opts.APIClient = mock.APIClient(api)
+		err := app.Run(opts)
+		testutil.AssertNoError(t, err)
+		testutil.AssertString(t, testcase.wantOutput, stdout.String())
+	}
 }
@@ -38,6 +38,10 @@ func (c *RootCommand) Exec(in io.Reader, out io.Writer) error {
 		return err
 	}
 
+	if text.IsMachineFormat(c.Globals.Flag.Format) {
+		return text.Render(out, c.Globals.Flag.Format, dcs)
+	}
+
 	text.Break(out)
 	t := text.NewTable(out)
 	t.AddHeader("NAME", "CODE", "GROUP", "SHIELD", "COORDINATES")
@@ -0,0 +1,136 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/fastly/cli/pkg/cmd"
+	"github.com/fastly/cli/pkg/config"
+	"github.com/fastly/cli/pkg/env"
+	fsterr "github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/text"
+	"github.com/fastly/go-fastly/v3/fastly"
+)
+
+// CreateCommand represents a Kingpin command.
+type CreateCommand struct {
+	cmd.Base
+
+	clientFactory  APIClientFactory
+	configFilePath string
+	makeDefault    bool
+	name           string
+}
+
+// NewCreateCommand returns a usable command registered under the parent.
+func NewCreateCommand(parent cmd.Registerer, configFilePath string, cf APIClientFactory, globals *config.Data) *CreateCommand {
+	var c CreateCommand
+	c.Globals = globals
+	c.configFilePath = configFilePath
+	c.clientFactory = cf
+	c.CmdClause = parent.Command("create", "Create a user profile").Alias("add")
+	c.CmdClause.Flag("name", "Profile name").Short('n').Required().StringVar(&c.name)
+	c.CmdClause.Flag("default", "Make the profile the default").BoolVar(&c.makeDefault)
+	return &c
+}
+
+// Exec invokes the application logic for the command.
+func (c *CreateCommand) Exec(in io.Reader, out io.Writer) (err error) {
+	if _, ok := c.Globals.File.Profiles[c.name]; ok {
+		return fsterr.RemediationError{
+			Inner:       fmt.Errorf("profile '%s' already exists", c.name),
+			Remediation: "Delete the existing profile with `fastly profile delete`, or choose a different name.",
+		}
+	}
+
+	// A token given via flag or env var is used as-is, otherwise we always
+	// prompt, as any token in the config file belongs to another profile.
+	token, source := c.Globals.Token()
+	switch source {
+	case config.SourceFlag:
+		text.Output(out, "Fastly API token provided via --token")
+	case config.SourceEnvironment:
+		text.Output(out, "Fastly API token provided via %s", env.Token)
+	default:
+		text.Output(out, `
+			An API token is used to authenticate requests to the Fastly API.
+			To create a token, visit https://manage.fastly.com/account/personal/tokens
+		`)
+		text.Break(out)
+		token, err = text.InputSecure(out, "Fastly API token: ", in, validateTokenNotEmpty)
+		if err != nil {
+			c.Globals.ErrLog.Add(err)
+			return err
+		}
+		text.Break(out)
+	}
+
+	text.Break(out)
+
+	progress := text.NewQuietProgress(out)
+	defer func() {
+		if err != nil {
+			c.Globals.ErrLog.Add(err)
+			progress.Fail() // progress.Done is handled inline
+		}
+	}()
+
+	progress.Step("Validating token...")
+
+	// Only an explicitly provided endpoint is stored against the profile.
+	endpoint, endpointSource := c.Globals.Endpoint()
+
+	client, err := c.clientFactory(token, endpoint)
+	if err != nil {
+		return fmt.Errorf("error regenerating Fastly API client: %w", err)
+	}
+	t, err := client.GetTokenSelf()
+	if err != nil {
+		return fmt.Errorf("error validating token: %w", err)
+	}
+	user, err := client.GetUser(&fastly.GetUserInput{
+		ID: t.UserID,
+	})
+	if err != nil {
+		return fmt.Errorf("error fetching token user: %w", err)
+	}
+
+	progress.Step("Persisting configuration...")
+
+	p := &config.Profile{
+		Email: user.Login,
+		Token: token,
+	}
+	if endpointSource == config.SourceFlag || endpointSource == config.SourceEnvironment {
+		p.Endpoint = endpoint
+	}
+
+	if c.Globals.File.Profiles == nil {
+		c.Globals.File.Profiles = make(config.Profiles)
+	}
+	c.Globals.File.Profiles[c.name] = p
+
+	if name, _ := c.Globals.File.DefaultProfile(); name == "" || c.makeDefault {
+		c.Globals.File.SetDefaultProfile(c.name)
+	}
+
+	if err := write(&c.Globals.File, c.configFilePath); err != nil {
+		return err
+	}
+
+	progress.Done()
+	text.Success(out, "Created profile '%s'", c.name)
+	return nil
+}
+
+func validateTokenNotEmpty(s string) error {
+	if s == "" {
+		return ErrEmptyToken
+	}
+	return nil
+}
+
+// ErrEmptyToken is returned when a user tries to supply an empty string as a
+// token when creating a profile.
+var ErrEmptyToken = errors.New("token cannot be empty")
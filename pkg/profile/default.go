@@ -0,0 +1,39 @@
+package profile
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/fastly/cli/pkg/cmd"
+	"github.com/fastly/cli/pkg/config"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// DefaultCommand represents a Kingpin command.
+type DefaultCommand struct {
+	cmd.Base
+}
+
+// NewDefaultCommand returns a usable command registered under the parent.
+func NewDefaultCommand(parent cmd.Registerer, globals *config.Data) *DefaultCommand {
+	var c DefaultCommand
+	c.Globals = globals
+	c.CmdClause = parent.Command("default", "Show the default user profile")
+	return &c
+}
+
+// Exec invokes the application logic for the command.
+func (c *DefaultCommand) Exec(in io.Reader, out io.Writer) error {
+	name, p := c.Globals.File.DefaultProfile()
+	if p == nil {
+		text.Info(out, "No default profile set. To set one, run `fastly profile switch --name <name>`.")
+		return nil
+	}
+
+	fmt.Fprintf(out, "Name: %s\n", name)
+	fmt.Fprintf(out, "Email: %s\n", p.Email)
+	if p.Endpoint != "" {
+		fmt.Fprintf(out, "API endpoint: %s\n", p.Endpoint)
+	}
+	return nil
+}
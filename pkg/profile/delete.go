@@ -0,0 +1,70 @@
+package profile
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/fastly/cli/pkg/cmd"
+	"github.com/fastly/cli/pkg/config"
+	"github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// DeleteCommand represents a Kingpin command.
+type DeleteCommand struct {
+	cmd.Base
+
+	configFilePath string
+	name           string
+	force          bool
+}
+
+// NewDeleteCommand returns a usable command registered under the parent.
+func NewDeleteCommand(parent cmd.Registerer, configFilePath string, globals *config.Data) *DeleteCommand {
+	var c DeleteCommand
+	c.Globals = globals
+	c.configFilePath = configFilePath
+	c.CmdClause = parent.Command("delete", "Delete a user profile").Alias("remove")
+	c.CmdClause.Flag("name", "Profile name").Short('n').Required().StringVar(&c.name)
+	c.CmdClause.Flag("force", "Delete the default profile even if it leaves no profile as the default").BoolVar(&c.force)
+	return &c
+}
+
+// Exec invokes the application logic for the command.
+func (c *DeleteCommand) Exec(in io.Reader, out io.Writer) error {
+	p, ok := c.Globals.File.Profiles[c.name]
+	if !ok {
+		return errProfileNotFound(c.name)
+	}
+
+	// Deleting the default profile of several would leave commands without a
+	// token, so the user has to pick the new default first.
+	if p.Default && len(c.Globals.File.Profiles) > 2 && !c.force {
+		return errors.RemediationError{
+			Inner:       fmt.Errorf("profile '%s' is the default", c.name),
+			Remediation: "Switch the default profile with 'fastly profile switch --name <name>' before deleting it, or use --force to delete it anyway.",
+		}
+	}
+	delete(c.Globals.File.Profiles, c.name)
+
+	// The only profile left becomes the default.
+	var newDefault string
+	if p.Default && len(c.Globals.File.Profiles) == 1 {
+		newDefault = c.Globals.File.ProfileNames()[0]
+		c.Globals.File.SetDefaultProfile(newDefault)
+	}
+
+	if err := write(&c.Globals.File, c.configFilePath); err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+
+	text.Success(out, "Deleted profile '%s'", c.name)
+	switch {
+	case newDefault != "":
+		text.Info(out, "Profile '%s' is now the default.", newDefault)
+	case p.Default && len(c.Globals.File.Profiles) > 0:
+		text.Warning(out, "The default profile was deleted. Set a new default with `fastly profile switch --name <name>`.")
+	}
+	return nil
+}
@@ -0,0 +1,3 @@
+// Package profile contains commands to inspect and manipulate the named
+// configuration profiles stored in the CLI config file.
+package profile
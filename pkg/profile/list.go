@@ -0,0 +1,85 @@
+package profile
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/fastly/cli/pkg/cmd"
+	"github.com/fastly/cli/pkg/config"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// ListCommand represents a Kingpin command.
+type ListCommand struct {
+	cmd.Base
+}
+
+// NewListCommand returns a usable command registered under the parent.
+func NewListCommand(parent cmd.Registerer, globals *config.Data) *ListCommand {
+	var c ListCommand
+	c.Globals = globals
+	c.CmdClause = parent.Command("list", "List user profiles")
+	return &c
+}
+
+// listEntry is the machine readable representation of a profile, which
+// deliberately omits the token.
+type listEntry struct {
+	Name     string `json:"name"`
+	Default  bool   `json:"default"`
+	Active   bool   `json:"active"`
+	Email    string `json:"email"`
+	Endpoint string `json:"api_endpoint"`
+}
+
+// Exec invokes the application logic for the command.
+func (c *ListCommand) Exec(in io.Reader, out io.Writer) error {
+	names := c.Globals.File.ProfileNames()
+	active, _ := c.Globals.Profile()
+
+	if text.IsMachineFormat(c.Globals.Flag.Format) {
+		entries := make([]listEntry, 0, len(names))
+		for _, name := range names {
+			p := c.Globals.File.Profiles[name]
+			entries = append(entries, listEntry{
+				Name:     name,
+				Default:  p.Default,
+				Active:   name == active,
+				Email:    p.Email,
+				Endpoint: p.Endpoint,
+			})
+		}
+		return text.Render(out, c.Globals.Flag.Format, entries)
+	}
+
+	if len(names) == 0 {
+		text.Info(out, "No profiles defined. To create a profile, run `fastly profile create --name <name>`.")
+		return nil
+	}
+
+	if !c.Globals.Verbose() {
+		tw := text.NewTable(out)
+		tw.AddHeader("NAME", "DEFAULT", "ACTIVE", "EMAIL")
+		for _, name := range names {
+			p := c.Globals.File.Profiles[name]
+			tw.AddLine(name, p.Default, name == active, p.Email)
+		}
+		tw.Print()
+		return nil
+	}
+
+	for i, name := range names {
+		p := c.Globals.File.Profiles[name]
+		fmt.Fprintf(out, "Profile %d/%d\n", i+1, len(names))
+		fmt.Fprintf(out, "\tName: %s\n", name)
+		fmt.Fprintf(out, "\tDefault: %t\n", p.Default)
+		fmt.Fprintf(out, "\tActive: %t\n", name == active)
+		fmt.Fprintf(out, "\tEmail: %s\n", p.Email)
+		if p.Endpoint != "" {
+			fmt.Fprintf(out, "\tAPI endpoint: %s\n", p.Endpoint)
+		}
+	}
+	fmt.Fprintln(out)
+
+	return nil
+}
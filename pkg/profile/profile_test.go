@@ -0,0 +1,241 @@
+package profile_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/fastly/cli/pkg/app"
+	"github.com/fastly/cli/pkg/config"
+	"github.com/fastly/cli/pkg/mock"
+	"github.com/fastly/cli/pkg/testutil"
+	"github.com/fastly/go-fastly/v3/fastly"
+	toml "github.com/pelletier/go-toml"
+)
+
+func TestProfile(t *testing.T) {
+	var (
+		goodToken = func() (*fastly.Token, error) { return &fastly.Token{}, nil }
+		badToken  = func() (*fastly.Token, error) { return nil, errors.New("bad token") }
+		goodUser  = func(*fastly.GetUserInput) (*fastly.User, error) {
+			return &fastly.User{
+				Login: "test@example.com",
+			}, nil
+		}
+		args = testutil.Args
+	)
+
+	existing := config.File{
+		Profiles: config.Profiles{
+			"prod": {Default: true, Email: "prod@example.com", Token: "123"},
+			"staging": {
+				Email:    "staging@example.com",
+				Endpoint: "https://staging.example.com",
+				Token:    "456",
+			},
+		},
+	}
+
+	several := config.File{
+		Profiles: config.Profiles{
+			"dev":     {Email: "dev@example.com", Token: "789"},
+			"prod":    existing.Profiles["prod"],
+			"staging": existing.Profiles["staging"],
+		},
+	}
+
+	for _, testcase := range []struct {
+		name         string
+		args         []string
+		env          config.Environment
+		file         config.File
+		api          mock.API
+		stdin        string
+		wantError    string
+		wantOutput   []string
+		wantProfiles config.Profiles
+	}{
+		{
+			name: "create first profile becomes the default",
+			args: args("profile create --name prod --token abcdef"),
+			api: mock.API{
+				GetTokenSelfFn: goodToken,
+				GetUserFn:      goodUser,
+			},
+			wantOutput: []string{
+				"Fastly API token provided via --token",
+				"Validating token...",
+				"Persisting configuration...",
+				"Created profile 'prod'",
+			},
+			wantProfiles: config.Profiles{
+				"prod": {Default: true, Email: "test@example.com", Token: "abcdef"},
+			},
+		},
+		{
+			name:  "create profile with interactive token",
+			args:  args("profile create --name dev"),
+			file:  existing,
+			stdin: "789\n",
+			api: mock.API{
+				GetTokenSelfFn: goodToken,
+				GetUserFn:      goodUser,
+			},
+			wantOutput: []string{
+				"Fastly API token: ",
+				"Created profile 'dev'",
+			},
+			wantProfiles: config.Profiles{
+				"dev":     {Email: "test@example.com", Token: "789"},
+				"prod":    existing.Profiles["prod"],
+				"staging": existing.Profiles["staging"],
+			},
+		},
+		{
+			name:      "create existing profile",
+			args:      args("profile create --name prod --token abcdef"),
+			file:      existing,
+			wantError: "profile 'prod' already exists",
+		},
+		{
+			name: "create with invalid token",
+			args: args("profile create --name prod --token abcdef"),
+			api: mock.API{
+				GetTokenSelfFn: badToken,
+			},
+			wantError: "error validating token: bad token",
+		},
+		{
+			name: "list",
+			args: args("profile list"),
+			file: existing,
+			wantOutput: []string{
+				"NAME     DEFAULT  ACTIVE  EMAIL",
+				"prod     true     true    prod@example.com",
+				"staging  false    false   staging@example.com",
+			},
+		},
+		{
+			name: "list with profile from env",
+			args: args("profile list --verbose"),
+			env:  config.Environment{Profile: "staging"},
+			file: existing,
+			wantOutput: []string{
+				"Name: staging",
+				"Active: true",
+				"API endpoint: https://staging.example.com",
+			},
+		},
+		{
+			name: "switch",
+			args: args("profile switch --name staging"),
+			file: existing,
+			wantOutput: []string{
+				"Profile switched to 'staging'",
+			},
+			wantProfiles: config.Profiles{
+				"prod":    {Email: "prod@example.com", Token: "123"},
+				"staging": {Default: true, Email: "staging@example.com", Endpoint: "https://staging.example.com", Token: "456"},
+			},
+		},
+		{
+			name:      "switch to unknown profile",
+			args:      args("profile switch --name unknown"),
+			file:      existing,
+			wantError: "profile 'unknown' not found",
+		},
+		{
+			name: "delete",
+			args: args("profile delete --name prod"),
+			file: existing,
+			wantOutput: []string{
+				"Deleted profile 'prod'",
+				"Profile 'staging' is now the default",
+			},
+			wantProfiles: config.Profiles{
+				"staging": {Default: true, Email: "staging@example.com", Endpoint: "https://staging.example.com", Token: "456"},
+			},
+		},
+		{
+			name: "delete non-default profile",
+			args: args("profile delete --name staging"),
+			file: existing,
+			wantOutput: []string{
+				"Deleted profile 'staging'",
+			},
+			wantProfiles: config.Profiles{
+				"prod": existing.Profiles["prod"],
+			},
+		},
+		{
+			name:      "delete default of several profiles",
+			args:      args("profile delete --name prod"),
+			file:      several,
+			wantError: "profile 'prod' is the default",
+		},
+		{
+			name: "delete default of several profiles with force",
+			args: args("profile delete --name prod --force"),
+			file: several,
+			wantOutput: []string{
+				"Deleted profile 'prod'",
+				"The default profile was deleted",
+			},
+			wantProfiles: config.Profiles{
+				"dev":     several.Profiles["dev"],
+				"staging": several.Profiles["staging"],
+			},
+		},
+		{
+			name: "default",
+			args: args("profile default"),
+			file: existing,
+			wantOutput: []string{
+				"Name: prod",
+				"Email: prod@example.com",
+			},
+		},
+		{
+			name:      "unknown profile flag",
+			args:      args("profile list --profile unknown"),
+			file:      existing,
+			wantError: "profile 'unknown' not found",
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			configFilePath := testutil.MakeTempFile(t, "")
+			defer os.RemoveAll(configFilePath)
+
+			// The commands mutate the profiles in place, so each test gets a copy.
+			file := testcase.file
+			file.Profiles = make(config.Profiles)
+			for name, p := range testcase.file.Profiles {
+				cp := *p
+				file.Profiles[name] = &cp
+			}
+
+			var stdout bytes.Buffer
+			opts := testutil.NewRunOpts(testcase.args, &stdout)
+			opts.APIClient = mock.APIClient(testcase.api)
+			opts.ConfigFile = file
+			opts.ConfigPath = configFilePath
+			opts.Env = testcase.env
+			opts.Stdin = strings.NewReader(testcase.stdin)
+			err := app.Run(opts)
+
+			testutil.AssertErrorContains(t, err, testcase.wantError)
+			for _, s := range testcase.wantOutput {
+				testutil.AssertStringContains(t, stdout.String(), s)
+			}
+			if testcase.wantProfiles != nil {
+				var f config.File
+				bs, err := os.ReadFile(configFilePath)
+				testutil.AssertNoError(t, err)
+				testutil.AssertNoError(t, toml.Unmarshal(bs, &f))
+				testutil.AssertEqual(t, testcase.wantProfiles, f.Profiles)
+			}
+		})
+	}
+}
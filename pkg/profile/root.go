@@ -0,0 +1,57 @@
+package profile
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/fastly/cli/pkg/api"
+	"github.com/fastly/cli/pkg/cmd"
+	"github.com/fastly/cli/pkg/config"
+	"github.com/fastly/cli/pkg/errors"
+)
+
+// APIClientFactory allows the profile commands to construct a Fastly API
+// client for a newly provided token, in order to validate that token.
+// It's a redeclaration of the app.APIClientFactory to avoid an import loop.
+type APIClientFactory func(token, endpoint string) (api.Interface, error)
+
+// RootCommand is the parent command for all subcommands in this package.
+// It should be installed under the primary root command.
+type RootCommand struct {
+	cmd.Base
+	// no flags
+}
+
+// NewRootCommand returns a new command registered in the parent.
+func NewRootCommand(parent cmd.Registerer, globals *config.Data) *RootCommand {
+	var c RootCommand
+	c.Globals = globals
+	c.CmdClause = parent.Command("profile", "Manage user profiles")
+	return &c
+}
+
+// Exec implements the command interface.
+func (c *RootCommand) Exec(in io.Reader, out io.Writer) error {
+	panic("unreachable")
+}
+
+// errProfileNotFound returns an error for a profile missing from the config.
+func errProfileNotFound(name string) error {
+	return errors.RemediationError{
+		Inner:       fmt.Errorf("profile '%s' not found", name),
+		Remediation: errors.ProfileRemediation,
+	}
+}
+
+// write persists the config file, creating its directory if necessary.
+func write(f *config.File, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), config.DirectoryPermissions); err != nil {
+		return fmt.Errorf("error creating config file directory: %w", err)
+	}
+	if err := f.Write(path); err != nil {
+		return fmt.Errorf("error saving config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package profile
+
+import (
+	"io"
+
+	"github.com/fastly/cli/pkg/cmd"
+	"github.com/fastly/cli/pkg/config"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// SwitchCommand represents a Kingpin command.
+type SwitchCommand struct {
+	cmd.Base
+
+	configFilePath string
+	name           string
+}
+
+// NewSwitchCommand returns a usable command registered under the parent.
+func NewSwitchCommand(parent cmd.Registerer, configFilePath string, globals *config.Data) *SwitchCommand {
+	var c SwitchCommand
+	c.Globals = globals
+	c.configFilePath = configFilePath
+	c.CmdClause = parent.Command("switch", "Switch the default user profile")
+	c.CmdClause.Flag("name", "Profile name").Short('n').Required().StringVar(&c.name)
+	return &c
+}
+
+// Exec invokes the application logic for the command.
+func (c *SwitchCommand) Exec(in io.Reader, out io.Writer) error {
+	if !c.Globals.File.SetDefaultProfile(c.name) {
+		return errProfileNotFound(c.name)
+	}
+
+	if err := write(&c.Globals.File, c.configFilePath); err != nil {
+		c.Globals.ErrLog.Add(err)
+		return err
+	}
+
+	text.Success(out, "Profile switched to '%s'", c.name)
+	return nil
+}
@@ -0,0 +1,210 @@
+package purge
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/text"
+	"github.com/fastly/go-fastly/v3/fastly"
+)
+
+// maxKeysPerPurge is the number of surrogate keys the API accepts in a single
+// PurgeKeys call.
+const maxKeysPerPurge = 256
+
+// result records the outcome of purging a URL or surrogate key.
+type result struct {
+	Type   string `json:"type"`
+	Target string `json:"target"`
+	Soft   bool   `json:"soft"`
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error"`
+
+	err error
+}
+
+// purgeURLs purges each URL using a pool of workers, limiting the rate of
+// requests when a rate is given. The results are in the same order as urls.
+func (c *RootCommand) purgeURLs(urls []string) []result {
+	results := make([]result, len(urls))
+
+	var limit <-chan time.Time
+	if c.rate > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(c.rate))
+		defer ticker.Stop()
+		limit = ticker.C
+	}
+
+	workers := c.concurrency
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if limit != nil {
+					<-limit
+				}
+				r := result{Type: "url", Target: urls[i], Soft: c.soft}
+				p, err := c.Globals.Client.Purge(&fastly.PurgeInput{
+					URL:  urls[i],
+					Soft: c.soft,
+				})
+				if err != nil {
+					c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+						"URL":  urls[i],
+						"Soft": c.soft,
+					})
+					r.Error, r.err = err.Error(), err
+				} else {
+					r.ID, r.Status = p.ID, p.Status
+				}
+				results[i] = r
+			}
+		}()
+	}
+
+	for i := range urls {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// purgeKeyBatches purges the surrogate keys in batches of the most the API
+// accepts per call, returning a result for each key ordered by key.
+func (c *RootCommand) purgeKeyBatches(serviceID string, keys []string) []result {
+	var results []result
+	for start := 0; start < len(keys); start += maxKeysPerPurge {
+		end := start + maxKeysPerPurge
+		if end > len(keys) {
+			end = len(keys)
+		}
+		batch := keys[start:end]
+
+		ids, err := c.Globals.Client.PurgeKeys(&fastly.PurgeKeysInput{
+			ServiceID: serviceID,
+			Keys:      batch,
+			Soft:      c.soft,
+		})
+		if err != nil {
+			c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+				"Service ID": serviceID,
+				"Keys":       batch,
+				"Soft":       c.soft,
+			})
+		}
+		for _, key := range batch {
+			r := result{Type: "key", Target: key, Soft: c.soft}
+			if err != nil {
+				r.Error, r.err = err.Error(), err
+			} else {
+				r.ID = ids[key]
+			}
+			results = append(results, r)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Target < results[j].Target
+	})
+	return results
+}
+
+// report writes the results of a bulk purge, either as tables of the
+// successful and failed purges or in the requested machine readable format.
+// An error is returned if any purge failed.
+func (c *RootCommand) report(out io.Writer, header string, results []result) error {
+	var failed []result
+	for _, r := range results {
+		if r.Error != "" {
+			failed = append(failed, r)
+		}
+	}
+
+	switch {
+	case text.IsMachineFormat(c.Globals.Flag.Format):
+		if err := text.Render(out, c.Globals.Flag.Format, results); err != nil {
+			return err
+		}
+	case c.format != "":
+		if err := text.Render(out, c.format, results); err != nil {
+			return err
+		}
+	default:
+		if len(failed) < len(results) {
+			t := text.NewTable(out)
+			t.AddHeader(header, "ID")
+			for _, r := range results {
+				if r.Error == "" {
+					t.AddLine(r.Target, r.ID)
+				}
+			}
+			t.Print()
+		}
+		if len(failed) > 0 {
+			text.Break(out)
+			t := text.NewTable(out)
+			t.AddHeader(header, "ERROR")
+			for _, r := range failed {
+				t.AddLine(r.Target, r.Error)
+			}
+			t.Print()
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to purge %d of %d %ss: %w", len(failed), len(results), strings.ToLower(header), failed[0].err)
+	}
+	return nil
+}
+
+// readList reads a newline delimited list from the file at path, or from in
+// if the path is "-". Blank lines and lines starting with # are ignored.
+func readList(path string, in io.Reader, errLog errors.LogInterface) ([]string, error) {
+	r := in
+	if path != "-" {
+		// gosec flagged this:
+		// G304 (CWE-22): Potential file inclusion via variable
+		// Disabling as we trust the source of the path variable.
+		/* #nosec */
+		f, err := os.Open(filepath.Clean(path))
+		if err != nil {
+			errLog.Add(err)
+			return nil, err
+		}
+		defer f.Close() // #nosec G307
+		r = f
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		errLog.Add(err)
+		return nil, err
+	}
+	return lines, nil
+}
@@ -2,7 +2,14 @@ package purge_test
 
 import (
 	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/fastly/cli/pkg/app"
@@ -258,3 +265,196 @@ func TestPurgeURL(t *testing.T) {
 		})
 	}
 }
+
+func TestPurgeURLFile(t *testing.T) {
+	urls := "https://example.com/a\n\n# comment\nhttps://example.com/b\nhttps://example.com/c\n"
+	purge := func(i *fastly.PurgeInput) (*fastly.Purge, error) {
+		if i.URL == "https://example.com/b" {
+			return nil, testutil.Err
+		}
+		return &fastly.Purge{Status: "ok", ID: "id-" + i.URL[len(i.URL)-1:]}, nil
+	}
+
+	args := testutil.Args
+	scenarios := []testutil.TestScenario{
+		{
+			Name:      "validate missing token",
+			Args:      args("purge --url-file=-"),
+			WantError: "no token provided",
+		},
+		{
+			Name: "validate Purge API success",
+			API: mock.API{
+				PurgeFn: func(i *fastly.PurgeInput) (*fastly.Purge, error) {
+					return &fastly.Purge{Status: "ok", ID: "id-" + i.URL[len(i.URL)-1:]}, nil
+				},
+			},
+			Args:       args("purge --url-file=- --concurrency 2 --rate 1000 --token 456"),
+			WantOutput: "URL                    ID\nhttps://example.com/a  id-a\nhttps://example.com/b  id-b\nhttps://example.com/c  id-c\n",
+		},
+		{
+			Name:      "validate Purge API partial failure",
+			API:       mock.API{PurgeFn: purge},
+			Args:      args("purge --url-file=- --token 456"),
+			WantError: "failed to purge 1 of 3 urls: " + testutil.Err.Error(),
+			WantOutputs: []string{
+				"URL                    ID\nhttps://example.com/a  id-a\nhttps://example.com/c  id-c\n",
+				"URL                    ERROR\nhttps://example.com/b  " + testutil.Err.Error(),
+			},
+		},
+		{
+			Name:       "validate json output",
+			API:        mock.API{PurgeFn: purge},
+			Args:       args("purge --url-file=- --soft --format json --token 456"),
+			WantError:  "failed to purge 1 of 3 urls",
+			WantOutput: `"error": "` + testutil.Err.Error() + `",` + "\n" + `    "id": "",` + "\n" + `    "soft": true,` + "\n" + `    "status": "",` + "\n" + `    "target": "https://example.com/b",` + "\n" + `    "type": "url"`,
+		},
+	}
+
+	for _, testcase := range scenarios {
+		t.Run(testcase.Name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			opts := testutil.NewRunOpts(testcase.Args, &stdout)
+			opts.APIClient = mock.APIClient(testcase.API)
+			opts.Stdin = strings.NewReader(urls)
+			err := app.Run(opts)
+			testutil.AssertErrorContains(t, err, testcase.WantError)
+			testutil.AssertStringContains(t, stdout.String(), testcase.WantOutput)
+			for _, want := range testcase.WantOutputs {
+				testutil.AssertStringContains(t, stdout.String(), want)
+			}
+		})
+	}
+}
+
+func TestPurgeKeysBatches(t *testing.T) {
+	var keys []string
+	for i := 0; i < 600; i++ {
+		keys = append(keys, fmt.Sprintf("key-%03d", i))
+	}
+
+	var (
+		mu      sync.Mutex
+		batches []int
+	)
+	api := mock.API{
+		PurgeKeysFn: func(i *fastly.PurgeKeysInput) (map[string]string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			batches = append(batches, len(i.Keys))
+			if len(batches) == 2 {
+				return nil, testutil.Err
+			}
+			ids := make(map[string]string)
+			for _, k := range i.Keys {
+				ids[k] = "id-" + k
+			}
+			return ids, nil
+		},
+	}
+
+	var stdout bytes.Buffer
+	opts := testutil.NewRunOpts(testutil.Args("purge --file=- --service-id 123 --token 456"), &stdout)
+	opts.APIClient = mock.APIClient(api)
+	opts.Stdin = strings.NewReader(strings.Join(keys, "\n"))
+	err := app.Run(opts)
+
+	testutil.AssertErrorContains(t, err, "failed to purge 256 of 600 keys")
+	testutil.AssertEqual(t, []int{256, 256, 88}, batches)
+	testutil.AssertStringContains(t, stdout.String(), "key-000  id-key-000")
+	testutil.AssertStringContains(t, stdout.String(), "key-256  "+testutil.Err.Error())
+	testutil.AssertStringContains(t, stdout.String(), "key-599  id-key-599")
+}
+
+func TestPurgeSitemap(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>http://%[1]s/posts.xml</loc></sitemap>
+  <sitemap><loc>http://%[1]s/pages.xml.gz</loc></sitemap>
+</sitemapindex>`, r.Host)
+	})
+	mux.HandleFunc("/posts.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/blog/2020/12/recap</loc></url>
+  <url><loc>https://example.com/blog/2021/01/hello</loc></url>
+  <url><loc>https://example.com/blog/2021/02/world</loc></url>
+</urlset>`)
+	})
+	mux.HandleFunc("/pages.xml.gz", func(w http.ResponseWriter, r *http.Request) {
+		zw := gzip.NewWriter(w)
+		fmt.Fprint(zw, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/about</loc></url>
+  <url><loc>https://example.com/blog/2021/</loc></url>
+</urlset>`)
+		zw.Close()
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	for _, testcase := range []struct {
+		name      string
+		args      []string
+		stdin     string
+		wantError string
+		wantURLs  []string
+	}{
+		{
+			name:     "sitemap index",
+			args:     testutil.Args("purge --sitemap " + server.URL + "/sitemap.xml --token 456"),
+			wantURLs: []string{"https://example.com/about", "https://example.com/blog/2020/12/recap", "https://example.com/blog/2021/", "https://example.com/blog/2021/01/hello", "https://example.com/blog/2021/02/world"},
+		},
+		{
+			name:     "sitemap match path",
+			args:     testutil.Args("purge --sitemap " + server.URL + "/sitemap.xml --match /blog/2021/* --token 456"),
+			wantURLs: []string{"https://example.com/blog/2021/", "https://example.com/blog/2021/01/hello", "https://example.com/blog/2021/02/world"},
+		},
+		{
+			name:     "sitemap match several",
+			args:     testutil.Args("purge --sitemap " + server.URL + "/posts.xml --match https://example.com/*/recap --match /blog/????/02/* --token 456"),
+			wantURLs: []string{"https://example.com/blog/2020/12/recap", "https://example.com/blog/2021/02/world"},
+		},
+		{
+			name:      "sitemap no matches",
+			args:      testutil.Args("purge --sitemap " + server.URL + "/sitemap.xml --match /news/* --token 456"),
+			wantError: "no URLs to purge",
+		},
+		{
+			name:      "sitemap not found",
+			args:      testutil.Args("purge --sitemap " + server.URL + "/missing.xml --token 456"),
+			wantError: "error fetching sitemap " + server.URL + "/missing.xml: 404 Not Found",
+		},
+		{
+			name:     "url file match",
+			args:     testutil.Args("purge --url-file=- --match /a* --token 456"),
+			stdin:    "https://example.com/a\nhttps://example.com/b\nhttps://example.com/a\nhttps://example.com/ab?x=1\n",
+			wantURLs: []string{"https://example.com/a", "https://example.com/ab?x=1"},
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			var (
+				mu   sync.Mutex
+				urls []string
+			)
+			api := mock.API{
+				PurgeFn: func(i *fastly.PurgeInput) (*fastly.Purge, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					urls = append(urls, i.URL)
+					return &fastly.Purge{Status: "ok", ID: "123"}, nil
+				},
+			}
+
+			var stdout bytes.Buffer
+			opts := testutil.NewRunOpts(testcase.args, &stdout)
+			opts.APIClient = mock.APIClient(api)
+			opts.Stdin = strings.NewReader(testcase.stdin)
+			err := app.Run(opts)
+			testutil.AssertErrorContains(t, err, testcase.wantError)
+
+			sort.Strings(urls)
+			testutil.AssertEqual(t, testcase.wantURLs, urls)
+		})
+	}
+}
@@ -1,13 +1,10 @@
 package purge
 
 import (
-	"bufio"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
-	"sort"
 
+	"github.com/fastly/cli/pkg/api"
 	"github.com/fastly/cli/pkg/cmd"
 	"github.com/fastly/cli/pkg/compute/manifest"
 	"github.com/fastly/cli/pkg/config"
@@ -17,20 +14,27 @@ import (
 )
 
 // NewRootCommand returns a new command registered in the parent.
-func NewRootCommand(parent cmd.Registerer, globals *config.Data) *RootCommand {
+func NewRootCommand(parent cmd.Registerer, client api.HTTPClient, globals *config.Data) *RootCommand {
 	var c RootCommand
 	c.CmdClause = parent.Command("purge", "Invalidate objects in the Fastly cache")
 	c.Globals = globals
+	c.client = client
 	c.manifest.File.SetOutput(c.Globals.Output)
 	c.manifest.File.Read(manifest.Filename)
 
 	// Optional flags
 	c.CmdClause.Flag("all", "Purge everything from a service").BoolVar(&c.all)
-	c.CmdClause.Flag("file", "Purge a service of a newline delimited list of Surrogate Keys").StringVar(&c.file)
+	c.CmdClause.Flag("concurrency", "Number of URLs to purge at once when using --url-file or --sitemap").Default("10").IntVar(&c.concurrency)
+	c.CmdClause.Flag("file", "Purge a service of a newline delimited list of Surrogate Keys (--file=- reads from stdin)").StringVar(&c.file)
+	c.CmdClause.Flag("format", "Output format for bulk purges (json)").EnumVar(&c.format, "json")
 	c.CmdClause.Flag("key", "Purge a service of objects tagged with a Surrogate Key").StringVar(&c.key)
+	c.CmdClause.Flag("match", "Only purge URLs from --url-file or --sitemap matching a glob, e.g. '/blog/2021/*' (repeatable)").StringsVar(&c.match)
+	c.CmdClause.Flag("rate", "Maximum number of purge requests per second when using --url-file or --sitemap (0 is unlimited)").Default("0").IntVar(&c.rate)
 	c.RegisterServiceIDFlag(&c.manifest.Flag.ServiceID)
+	c.CmdClause.Flag("sitemap", "Purge the URLs listed in a sitemap or sitemap index").StringVar(&c.sitemap)
 	c.CmdClause.Flag("soft", "A 'soft' purge marks affected objects as stale rather than making them inaccessible").BoolVar(&c.soft)
 	c.CmdClause.Flag("url", "Purge an individual URL").StringVar(&c.url)
+	c.CmdClause.Flag("url-file", "Purge a newline delimited list of URLs (--url-file=- reads from stdin)").StringVar(&c.urlFile)
 
 	return &c
 }
@@ -40,12 +44,19 @@ func NewRootCommand(parent cmd.Registerer, globals *config.Data) *RootCommand {
 type RootCommand struct {
 	cmd.Base
 
-	all      bool
-	file     string
-	key      string
-	manifest manifest.Data
-	soft     bool
-	url      string
+	all         bool
+	client      api.HTTPClient
+	concurrency int
+	file        string
+	format      string
+	key         string
+	manifest    manifest.Data
+	match       []string
+	rate        int
+	sitemap     string
+	soft        bool
+	url         string
+	urlFile     string
 }
 
 // Exec implements the command interface.
@@ -59,7 +70,7 @@ func (c *RootCommand) Exec(in io.Reader, out io.Writer) error {
 	// The URL purge API call doesn't require a Service ID.
 	var serviceID string
 	var source manifest.Source
-	if c.url == "" {
+	if c.url == "" && c.urlFile == "" && c.sitemap == "" {
 		serviceID, source = c.manifest.ServiceID()
 		if source == manifest.SourceUndefined {
 			return errors.ErrNoServiceID
@@ -85,7 +96,7 @@ func (c *RootCommand) Exec(in io.Reader, out io.Writer) error {
 	}
 
 	if c.file != "" {
-		err := c.purgeKeys(serviceID, out)
+		err := c.purgeKeys(serviceID, in, out)
 		if err != nil {
 			c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
 				"Service ID": serviceID,
@@ -108,6 +119,30 @@ func (c *RootCommand) Exec(in io.Reader, out io.Writer) error {
 		return nil
 	}
 
+	if c.sitemap != "" {
+		err := c.purgeSitemap(out)
+		if err != nil {
+			c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+				"Sitemap": c.sitemap,
+				"Match":   c.match,
+			})
+			return err
+		}
+		return nil
+	}
+
+	if c.urlFile != "" {
+		err := c.purgeURLFile(in, out)
+		if err != nil {
+			c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+				"URL file": c.urlFile,
+				"Match":    c.match,
+			})
+			return err
+		}
+		return nil
+	}
+
 	if c.url != "" {
 		err := c.purgeURL(out)
 		if err != nil {
@@ -136,8 +171,8 @@ func (c *RootCommand) purgeAll(serviceID string, out io.Writer) error {
 	return nil
 }
 
-func (c *RootCommand) purgeKeys(serviceID string, out io.Writer) error {
-	keys, err := populateKeys(c.file, c.Globals.ErrLog)
+func (c *RootCommand) purgeKeys(serviceID string, in io.Reader, out io.Writer) error {
+	keys, err := readList(c.file, in, c.Globals.ErrLog)
 	if err != nil {
 		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
 			"Service ID": serviceID,
@@ -145,34 +180,41 @@ func (c *RootCommand) purgeKeys(serviceID string, out io.Writer) error {
 		return err
 	}
 
-	m, err := c.Globals.Client.PurgeKeys(&fastly.PurgeKeysInput{
-		ServiceID: serviceID,
-		Keys:      keys,
-		Soft:      c.soft,
-	})
+	return c.report(out, "KEY", c.purgeKeyBatches(serviceID, keys))
+}
+
+func (c *RootCommand) purgeURLFile(in io.Reader, out io.Writer) error {
+	urls, err := readList(c.urlFile, in, c.Globals.ErrLog)
 	if err != nil {
-		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
-			"Service ID": serviceID,
-			"Keys":       keys,
-			"Soft":       c.soft,
-		})
 		return err
 	}
 
-	sortedKeys := make([]string, 0, len(m))
-	for k := range m {
-		sortedKeys = append(sortedKeys, k)
+	return c.purgeMatching(out, urls)
+}
+
+func (c *RootCommand) purgeSitemap(out io.Writer) error {
+	urls, err := fetchSitemap(c.client, c.sitemap)
+	if err != nil {
+		return err
+	}
+	if c.Globals.Verbose() {
+		text.Info(out, "Found %d URLs in %s", len(urls), c.sitemap)
 	}
-	sort.Strings(sortedKeys)
 
-	t := text.NewTable(out)
-	t.AddHeader("KEY", "ID")
-	for _, k := range sortedKeys {
-		t.AddLine(k, m[k])
+	return c.purgeMatching(out, urls)
+}
+
+// purgeMatching purges the URLs matching the --match patterns.
+func (c *RootCommand) purgeMatching(out io.Writer, urls []string) error {
+	urls = newMatcher(c.match).filter(urls)
+	if len(urls) == 0 {
+		return errors.RemediationError{
+			Inner:       fmt.Errorf("no URLs to purge"),
+			Remediation: "Check the --match patterns. A '*' matches any characters, and patterns without a scheme match the URL path, e.g. '/blog/2021/*'.",
+		}
 	}
-	t.Print()
 
-	return nil
+	return c.report(out, "URL", c.purgeURLs(urls))
 }
 
 func (c *RootCommand) purgeKey(serviceID string, out io.Writer) error {
@@ -208,34 +250,3 @@ func (c *RootCommand) purgeURL(out io.Writer) error {
 	text.Success(out, "Purged URL: %s (soft: %t). Status: %s, ID: %s", c.url, c.soft, p.Status, p.ID)
 	return nil
 }
-
-// populateKeys opens the given file path, initializes a scanner, and appends
-// each line of the file (expected to be a surrogate key) to a slice.
-func populateKeys(fpath string, errLog errors.LogInterface) (keys []string, err error) {
-	var (
-		file io.Reader
-		path string
-	)
-
-	if path, err = filepath.Abs(fpath); err == nil {
-		if _, err = os.Stat(path); err == nil {
-			// gosec flagged this:
-			// G304 (CWE-22): Potential file inclusion via variable
-			// Disabling as we trust the source of the fpath variable.
-			/* #nosec */
-			if file, err = os.Open(path); err == nil {
-				scanner := bufio.NewScanner(file)
-				for scanner.Scan() {
-					keys = append(keys, scanner.Text())
-				}
-				err = scanner.Err()
-			}
-		}
-	}
-
-	if err != nil {
-		errLog.Add(err)
-		return nil, err
-	}
-	return keys, nil
-}
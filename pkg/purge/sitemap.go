@@ -0,0 +1,198 @@
+package purge
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/fastly/cli/pkg/api"
+	"github.com/fastly/cli/pkg/useragent"
+)
+
+// maxSitemapDepth is how deeply sitemap indexes may nest.
+const maxSitemapDepth = 3
+
+// sitemap is a sitemap or sitemap index, as described by
+// https://www.sitemaps.org/protocol.html.
+type sitemap struct {
+	XMLName  xml.Name
+	URLs     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// fetchSitemap returns every URL listed in the sitemap at location, following
+// sitemap indexes to the sitemaps they list.
+func fetchSitemap(client api.HTTPClient, location string) ([]string, error) {
+	var (
+		urls    []string
+		visited = map[string]bool{}
+	)
+
+	var fetch func(location string, depth int) error
+	fetch = func(location string, depth int) error {
+		if visited[location] {
+			return nil
+		}
+		visited[location] = true
+
+		s, err := getSitemap(client, location)
+		if err != nil {
+			return err
+		}
+
+		for _, u := range s.URLs {
+			if loc := strings.TrimSpace(u.Loc); loc != "" {
+				urls = append(urls, loc)
+			}
+		}
+		for _, child := range s.Sitemaps {
+			loc := strings.TrimSpace(child.Loc)
+			if loc == "" {
+				continue
+			}
+			if depth >= maxSitemapDepth {
+				return fmt.Errorf("error fetching sitemap %s: sitemap indexes are nested more than %d deep", loc, maxSitemapDepth)
+			}
+			if err := fetch(loc, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := fetch(location, 0); err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
+
+// getSitemap fetches and decodes a single sitemap, which may be gzipped.
+func getSitemap(client api.HTTPClient, location string) (*sitemap, error) {
+	req, err := http.NewRequest("GET", location, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error constructing sitemap request: %w", err)
+	}
+	req.Header.Set("User-Agent", useragent.Name)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching sitemap %s: %w", location, err)
+	}
+	defer resp.Body.Close() // #nosec G307
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching sitemap %s: %s", location, resp.Status)
+	}
+
+	br := bufio.NewReader(resp.Body)
+	var body io.Reader = br
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing sitemap %s: %w", location, err)
+		}
+		defer zr.Close()
+		body = zr
+	}
+
+	var s sitemap
+	if err := xml.NewDecoder(body).Decode(&s); err != nil {
+		return nil, fmt.Errorf("error decoding sitemap %s: %w", location, err)
+	}
+	if name := s.XMLName.Local; name != "urlset" && name != "sitemapindex" {
+		return nil, fmt.Errorf("error decoding sitemap %s: unexpected <%s> element", location, name)
+	}
+	return &s, nil
+}
+
+// matcher selects URLs by glob patterns. A '*' matches any run of characters,
+// including '/', and a '?' matches any single character. Patterns with a
+// scheme are matched against the whole URL, and others against its path and
+// query, so '/blog/2021/*' matches everything under /blog/2021/ on any host.
+type matcher []matchPattern
+
+type matchPattern struct {
+	full bool
+	re   *regexp.Regexp
+}
+
+// newMatcher compiles the glob patterns.
+func newMatcher(patterns []string) matcher {
+	var m matcher
+	for _, p := range patterns {
+		var b strings.Builder
+		b.WriteString("^")
+		for _, r := range p {
+			switch r {
+			case '*':
+				b.WriteString(".*")
+			case '?':
+				b.WriteString(".")
+			default:
+				b.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+		b.WriteString("$")
+		m = append(m, matchPattern{
+			full: strings.Contains(p, "://"),
+			re:   regexp.MustCompile(b.String()),
+		})
+	}
+	return m
+}
+
+// match reports whether the URL matches any pattern. Every URL matches when
+// there are no patterns.
+func (m matcher) match(rawurl string) bool {
+	if len(m) == 0 {
+		return true
+	}
+
+	path := rawurl
+	if u, err := url.Parse(rawurl); err == nil {
+		path = u.EscapedPath()
+		if path == "" {
+			path = "/"
+		}
+		if u.RawQuery != "" {
+			path += "?" + u.RawQuery
+		}
+	}
+
+	for _, p := range m {
+		s := path
+		if p.full {
+			s = rawurl
+		}
+		if p.re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns the URLs which match, without duplicates.
+func (m matcher) filter(urls []string) []string {
+	var (
+		matched []string
+		seen    = map[string]bool{}
+	)
+	for _, u := range urls {
+		if seen[u] || !m.match(u) {
+			continue
+		}
+		seen[u] = true
+		matched = append(matched, u)
+	}
+	return matched
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/fastly/cli/pkg/cmd"
+	"github.com/fastly/cli/pkg/compute/manifest"
+	"github.com/fastly/cli/pkg/config"
+	"github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/service/spec"
+	"github.com/fastly/cli/pkg/text"
+	"github.com/fastly/cli/pkg/undo"
+	"github.com/fastly/go-fastly/v3/fastly"
+)
+
+// ApplyCommand reconciles the resources of a Fastly service version with a
+// service spec file.
+type ApplyCommand struct {
+	cmd.Base
+	manifest       manifest.Data
+	serviceVersion cmd.OptionalServiceVersion
+
+	file     string
+	activate bool
+	dryRun   bool
+	items    bool
+}
+
+// NewApplyCommand returns a usable command registered under the parent.
+func NewApplyCommand(parent cmd.Registerer, globals *config.Data) *ApplyCommand {
+	var c ApplyCommand
+	c.Globals = globals
+	c.manifest.File.SetOutput(c.Globals.Output)
+	c.manifest.File.Read(manifest.Filename)
+	c.CmdClause = parent.Command("apply", "Apply a service spec file to a clone of a Fastly service version")
+	c.RegisterServiceIDFlag(&c.manifest.Flag.ServiceID)
+	c.RegisterServiceVersionFlag(cmd.ServiceVersionFlagOpts{
+		Action:   c.serviceVersion.Set,
+		Dst:      &c.serviceVersion.Value,
+		Optional: true,
+	})
+	c.CmdClause.Flag("file", "Path of the spec file to apply").Short('f').Required().StringVar(&c.file)
+	c.CmdClause.Flag("activate", "Activate the service version once the spec has been applied").BoolVar(&c.activate)
+	c.CmdClause.Flag("dry-run", "Print the changes required to apply the spec without making them").BoolVar(&c.dryRun)
+	c.CmdClause.Flag("items", "Change the items of existing dictionaries, which aren't versioned and so take effect immediately").BoolVar(&c.items)
+	return &c
+}
+
+// Exec invokes the application logic for the command.
+func (c *ApplyCommand) Exec(in io.Reader, out io.Writer) (err error) {
+	desired, err := spec.Read(c.file)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"File": c.file,
+		})
+		return fmt.Errorf("error reading service spec: %w", err)
+	}
+
+	// Changes are applied to a clone of the active version unless the user
+	// has selected a specific version.
+	if !c.serviceVersion.WasSet {
+		c.serviceVersion.Value = "active"
+	}
+
+	serviceID, current, err := cmd.ServiceDetails(cmd.ServiceDetailsOpts{
+		AllowActiveLocked:  true,
+		Client:             c.Globals.Client,
+		Manifest:           c.manifest,
+		Out:                out,
+		ServiceVersionFlag: c.serviceVersion,
+		VerboseMode:        c.Globals.Flag.Verbose,
+	})
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID":      serviceID,
+			"Service Version": errors.ServiceVersion(current),
+		})
+		return err
+	}
+
+	live, err := spec.Export(c.Globals.Client, serviceID, current.Number)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID":      serviceID,
+			"Service Version": current.Number,
+		})
+		return err
+	}
+
+	ops := spec.Plan(live, desired)
+	if len(ops) == 0 {
+		text.Info(out, "Service %s version %d already matches %s", serviceID, current.Number, c.file)
+		return nil
+	}
+
+	// The items of existing dictionaries are changed on the live service
+	// rather than the version, so they're only changed when asked to, once
+	// the version's changes have been made.
+	var versioned, itemOps []spec.Operation
+	for _, op := range ops {
+		if op.Live() {
+			itemOps = append(itemOps, op)
+		} else {
+			versioned = append(versioned, op)
+		}
+	}
+
+	if len(versioned) > 0 {
+		text.Output(out, "Changes required to apply %s to service %s version %d:", c.file, serviceID, current.Number)
+		text.Break(out)
+		for _, op := range versioned {
+			fmt.Fprintf(out, "\t%s\n", op)
+		}
+		text.Break(out)
+	}
+	if len(itemOps) > 0 {
+		text.Output(out, "Dictionary item changes required to apply %s to service %s:", c.file, serviceID)
+		text.Break(out)
+		for _, op := range itemOps {
+			fmt.Fprintf(out, "\t%s\n", op)
+		}
+		text.Break(out)
+		text.Warning(out, "Dictionary items aren't versioned, so these changes take effect immediately on every version using the dictionaries, including the active version.")
+		if !c.items {
+			text.Info(out, "The dictionary item changes won't be applied, use --items to apply them.")
+			itemOps = nil
+		}
+	}
+
+	if c.dryRun {
+		text.Info(out, "Dry run: %d change(s) not applied", len(ops))
+		return nil
+	}
+	if len(versioned) == 0 && len(itemOps) == 0 {
+		return nil
+	}
+
+	// Like compute deploy, apply is a composite of behaviours and so we clone
+	// an uneditable version without requiring the --autoclone flag. There's
+	// nothing to clone or activate when only dictionary items change.
+	version := current
+	activate := c.activate && len(versioned) > 0
+	if len(versioned) > 0 {
+		var autoClone cmd.OptionalAutoClone
+		autoClone.Value = true
+		version, err = autoClone.Parse(current, serviceID, c.Globals.Flag.Verbose, out, c.Globals.Client)
+		if err != nil {
+			c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+				"Service ID":      serviceID,
+				"Service Version": current.Number,
+			})
+			return err
+		}
+	}
+
+	var progress text.Progress
+	if c.Globals.Verbose() {
+		progress = text.NewVerboseProgress(out)
+	} else {
+		progress = text.NewQuietProgress(out)
+	}
+
+	undoStack := undo.NewStack()
+	defer func() {
+		if err != nil {
+			progress.Fail() // progress.Done is handled inline
+		}
+		undoStack.RunIfError(out, err)
+	}()
+
+	for _, op := range versioned {
+		progress.Step(fmt.Sprintf("Applying %s...", op))
+		if err = op.Apply(c.Globals.Client, serviceID, version.Number); err != nil {
+			c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+				"Service ID":      serviceID,
+				"Service Version": version.Number,
+				"Operation":       op.String(),
+			})
+			return err
+		}
+		inverse := op.Inverse()
+		undoStack.Push(func() error {
+			return inverse.Apply(c.Globals.Client, serviceID, version.Number)
+		})
+	}
+
+	if activate {
+		progress.Step("Activating version...")
+		_, err = c.Globals.Client.ActivateVersion(&fastly.ActivateVersionInput{
+			ServiceID:      serviceID,
+			ServiceVersion: version.Number,
+		})
+		if err != nil {
+			c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+				"Service ID":      serviceID,
+				"Service Version": version.Number,
+			})
+			return fmt.Errorf("error activating version: %w", err)
+		}
+	}
+
+	// The version's changes are complete, so only the item changes are
+	// undone if one of them fails.
+	undoStack = undo.NewStack()
+	for _, op := range itemOps {
+		progress.Step(fmt.Sprintf("Applying %s...", op))
+		if err = op.Apply(c.Globals.Client, serviceID, version.Number); err != nil {
+			c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+				"Service ID":      serviceID,
+				"Service Version": version.Number,
+				"Operation":       op.String(),
+			})
+			return err
+		}
+		inverse := op.Inverse()
+		undoStack.Push(func() error {
+			return inverse.Apply(c.Globals.Client, serviceID, version.Number)
+		})
+	}
+
+	progress.Done()
+
+	applied := len(versioned) + len(itemOps)
+
+	if activate {
+		text.Success(out, "Applied %d change(s) and activated service %s version %d", applied, serviceID, version.Number)
+		return nil
+	}
+	text.Success(out, "Applied %d change(s) to service %s version %d", applied, serviceID, version.Number)
+	return nil
+}
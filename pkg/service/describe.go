@@ -45,6 +45,10 @@ func (c *DescribeCommand) Exec(in io.Reader, out io.Writer) error {
 		return err
 	}
 
+	if text.IsMachineFormat(c.Globals.Flag.Format) {
+		return text.Render(out, c.Globals.Flag.Format, service)
+	}
+
 	text.PrintServiceDetail(out, "", service)
 	return nil
 }
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fastly/cli/pkg/cmd"
+	"github.com/fastly/cli/pkg/compute/manifest"
+	"github.com/fastly/cli/pkg/config"
+	"github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/service/spec"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// ExportCommand calls the Fastly API to export every resource on a service
+// version to a spec file.
+type ExportCommand struct {
+	cmd.Base
+	manifest       manifest.Data
+	serviceVersion cmd.OptionalServiceVersion
+
+	file           string
+	format         string
+	includeSecrets bool
+}
+
+// NewExportCommand returns a usable command registered under the parent.
+func NewExportCommand(parent cmd.Registerer, globals *config.Data) *ExportCommand {
+	var c ExportCommand
+	c.Globals = globals
+	c.manifest.File.SetOutput(c.Globals.Output)
+	c.manifest.File.Read(manifest.Filename)
+	c.CmdClause = parent.Command("export", "Export the resources of a Fastly service version to a service spec file")
+	c.RegisterServiceIDFlag(&c.manifest.Flag.ServiceID)
+	c.RegisterServiceVersionFlag(cmd.ServiceVersionFlagOpts{
+		Dst: &c.serviceVersion.Value,
+	})
+	c.CmdClause.Flag("file", "Path of the spec file to write (defaults to stdout)").Short('f').StringVar(&c.file)
+	c.CmdClause.Flag("format", "Spec file format, inferred from the --file extension if not set (toml, json)").HintOptions(spec.FormatTOML, spec.FormatJSON).EnumVar(&c.format, spec.FormatTOML, spec.FormatJSON)
+	c.CmdClause.Flag("include-secrets", "Include credentials (e.g. logging endpoint tokens) rather than redacting them").BoolVar(&c.includeSecrets)
+	return &c
+}
+
+// Exec invokes the application logic for the command.
+func (c *ExportCommand) Exec(in io.Reader, out io.Writer) error {
+	serviceID, serviceVersion, err := cmd.ServiceDetails(cmd.ServiceDetailsOpts{
+		AllowActiveLocked:  true,
+		Client:             c.Globals.Client,
+		Manifest:           c.manifest,
+		Out:                out,
+		ServiceVersionFlag: c.serviceVersion,
+		VerboseMode:        c.Globals.Flag.Verbose,
+	})
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID":      serviceID,
+			"Service Version": errors.ServiceVersion(serviceVersion),
+		})
+		return err
+	}
+
+	s, err := spec.Export(c.Globals.Client, serviceID, serviceVersion.Number)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID":      serviceID,
+			"Service Version": serviceVersion.Number,
+		})
+		return err
+	}
+	if !c.includeSecrets {
+		s.Redact()
+	}
+
+	format := c.format
+	if format == "" {
+		format = spec.FormatFromPath(c.file)
+	}
+	bs, err := s.Marshal(format)
+	if err != nil {
+		c.Globals.ErrLog.Add(err)
+		return fmt.Errorf("error encoding service spec: %w", err)
+	}
+
+	// Only the spec is written to stdout so it can be redirected to a file.
+	if c.file == "" {
+		_, err = out.Write(bs)
+		return err
+	}
+
+	if err := os.WriteFile(c.file, bs, spec.FilePermissions); err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"File": c.file,
+		})
+		return fmt.Errorf("error writing service spec: %w", err)
+	}
+
+	text.Success(out, "Exported service %s version %d to %s", serviceID, serviceVersion.Number, c.file)
+	return nil
+}
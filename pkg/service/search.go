@@ -38,6 +38,10 @@ func (c *SearchCommand) Exec(in io.Reader, out io.Writer) error {
 		return err
 	}
 
+	if text.IsMachineFormat(c.Globals.Flag.Format) {
+		return text.Render(out, c.Globals.Flag.Format, service)
+	}
+
 	text.PrintService(out, "", service)
 	return nil
 }
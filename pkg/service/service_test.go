@@ -3,6 +3,7 @@ package service_test
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -194,6 +195,11 @@ func TestServiceSearch(t *testing.T) {
 			api:        mock.API{SearchServiceFn: searchServiceOK},
 			wantOutput: searchServiceVerboseOutput,
 		},
+		{
+			args:       args("service search --name Foo --output json"),
+			api:        mock.API{SearchServiceFn: searchServiceOK},
+			wantOutput: searchServiceJSONOutput,
+		},
 		{
 			args:      args("service search --name"),
 			api:       mock.API{SearchServiceFn: searchServiceOK},
@@ -381,6 +387,420 @@ func TestServiceDelete(t *testing.T) {
 
 var errTest = errors.New("fixture error")
 
+func TestServiceExport(t *testing.T) {
+	args := testutil.Args
+	exportAPI := testutil.ListEmpty(mock.API{
+		ListVersionsFn:        testutil.ListVersions,
+		GetServiceFn:          getServiceOK,
+		ListBackendsFn:        listBackendsOK,
+		ListSyslogsFn:         listSyslogsOK,
+		ListDictionariesFn:    listDictionariesOK,
+		ListDictionaryItemsFn: listDictionaryItemsOK,
+	})
+
+	for _, testcase := range []struct {
+		name       string
+		args       []string
+		api        mock.API
+		wantError  string
+		wantOutput []string
+		dontWant   []string
+	}{
+		{
+			name:      "validate missing --version flag",
+			args:      args("service export --service-id 123"),
+			api:       exportAPI,
+			wantError: "error parsing arguments: required flag --version not provided",
+		},
+		{
+			name: "validate toml output with secrets redacted",
+			args: args("service export --service-id 123 --version 1"),
+			api:  exportAPI,
+			wantOutput: []string{
+				"spec_version = 1",
+				`name = "Foo"`,
+				"[[resources.backend]]",
+				`address = "127.0.0.1"`,
+				"[[logging.syslog]]",
+				`token = "<redacted>"`,
+				`foo = "bar"`,
+			},
+			dontWant: []string{"s3cr3t", "created_at"},
+		},
+		{
+			name: "validate json output with secrets included",
+			args: args("service export --service-id 123 --version 1 --format json --include-secrets"),
+			api:  exportAPI,
+			wantOutput: []string{
+				`"spec_version": 1`,
+				`"token": "s3cr3t"`,
+			},
+		},
+		{
+			name: "validate list error",
+			args: args("service export --service-id 123 --version 1"),
+			api: testutil.ListEmpty(mock.API{
+				ListVersionsFn: testutil.ListVersions,
+				GetServiceFn:   getServiceOK,
+				ListBackendsFn: func(*fastly.ListBackendsInput) ([]*fastly.Backend, error) {
+					return nil, testutil.Err
+				},
+			}),
+			wantError: "error listing backend resources: test error",
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			opts := testutil.NewRunOpts(testcase.args, &stdout)
+			opts.APIClient = mock.APIClient(testcase.api)
+			err := app.Run(opts)
+			testutil.AssertErrorContains(t, err, testcase.wantError)
+			for _, s := range testcase.wantOutput {
+				testutil.AssertStringContains(t, stdout.String(), s)
+			}
+			for _, s := range testcase.dontWant {
+				if strings.Contains(stdout.String(), s) {
+					t.Errorf("unexpected %q in output:\n%s", s, stdout.String())
+				}
+			}
+		})
+	}
+}
+
+func TestServiceExportFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.json")
+	args := testutil.Args("service export --service-id 123 --version 1 --file " + path)
+	api := testutil.ListEmpty(mock.API{
+		ListVersionsFn: testutil.ListVersions,
+		GetServiceFn:   getServiceOK,
+		ListBackendsFn: listBackendsOK,
+	})
+
+	var stdout bytes.Buffer
+	opts := testutil.NewRunOpts(args, &stdout)
+	opts.APIClient = mock.APIClient(api)
+	err := app.Run(opts)
+	testutil.AssertNoError(t, err)
+	testutil.AssertStringContains(t, stdout.String(), "Exported service 123 version 1 to "+path)
+
+	bs, err := os.ReadFile(path)
+	testutil.AssertNoError(t, err)
+	testutil.AssertStringContains(t, string(bs), `"backend": [`)
+}
+
+func TestServiceApply(t *testing.T) {
+	specFile := filepath.Join(t.TempDir(), "service.toml")
+	err := os.WriteFile(specFile, []byte(`spec_version = 1
+
+[[resources.domain]]
+  name = "example.com"
+
+[[resources.backend]]
+  name = "origin"
+  address = "127.0.0.2"
+  port = 443
+`), 0644)
+	testutil.AssertNoError(t, err)
+
+	args := testutil.Args
+	for _, testcase := range []struct {
+		name       string
+		args       []string
+		api        mock.API
+		wantError  string
+		wantOutput []string
+		wantCalls  []string
+	}{
+		{
+			name:      "validate missing --file flag",
+			args:      args("service apply --service-id 123"),
+			wantError: "error parsing arguments: required flag --file not provided",
+		},
+		{
+			name: "validate dry run",
+			args: args("service apply --service-id 123 --dry-run --file " + specFile),
+			wantOutput: []string{
+				`+ domain "example.com"`,
+				`~ backend "origin" (address)`,
+				`- syslog "logs"`,
+				"Dry run: 3 change(s) not applied",
+			},
+		},
+		{
+			name: "validate apply clones the active version",
+			args: args("service apply --service-id 123 --activate --file " + specFile),
+			wantOutput: []string{
+				"Applied 3 change(s) and activated service 123 version 4",
+			},
+			wantCalls: []string{
+				"clone 1",
+				"create domain example.com 4",
+				"update backend origin 4",
+				"delete syslog logs 4",
+				"activate 4",
+			},
+		},
+		{
+			name: "validate failure unwinds applied changes",
+			args: args("service apply --service-id 123 --file " + specFile),
+			api: mock.API{
+				DeleteSyslogFn: func(*fastly.DeleteSyslogInput) error {
+					return testutil.Err
+				},
+			},
+			wantError: "error deleting syslog logs: test error",
+			wantCalls: []string{
+				"clone 1",
+				"create domain example.com 4",
+				"update backend origin 4",
+				"update backend origin 4",
+				"delete domain example.com 4",
+			},
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			var calls []string
+			api := testcase.api
+			api.ListVersionsFn = testutil.ListVersions
+			api.GetServiceFn = getServiceOK
+			api.ListBackendsFn = listBackendsOK
+			api.ListSyslogsFn = listSyslogsOK
+			api.CloneVersionFn = func(i *fastly.CloneVersionInput) (*fastly.Version, error) {
+				calls = append(calls, fmt.Sprintf("clone %d", i.ServiceVersion))
+				return &fastly.Version{ServiceID: i.ServiceID, Number: 4}, nil
+			}
+			api.CreateDomainFn = func(i *fastly.CreateDomainInput) (*fastly.Domain, error) {
+				calls = append(calls, fmt.Sprintf("create domain %s %d", i.Name, i.ServiceVersion))
+				return &fastly.Domain{}, nil
+			}
+			api.DeleteDomainFn = func(i *fastly.DeleteDomainInput) error {
+				calls = append(calls, fmt.Sprintf("delete domain %s %d", i.Name, i.ServiceVersion))
+				return nil
+			}
+			api.UpdateBackendFn = func(i *fastly.UpdateBackendInput) (*fastly.Backend, error) {
+				calls = append(calls, fmt.Sprintf("update backend %s %d", i.Name, i.ServiceVersion))
+				return &fastly.Backend{}, nil
+			}
+			if api.DeleteSyslogFn == nil {
+				api.DeleteSyslogFn = func(i *fastly.DeleteSyslogInput) error {
+					calls = append(calls, fmt.Sprintf("delete syslog %s %d", i.Name, i.ServiceVersion))
+					return nil
+				}
+			}
+			api.ActivateVersionFn = func(i *fastly.ActivateVersionInput) (*fastly.Version, error) {
+				calls = append(calls, fmt.Sprintf("activate %d", i.ServiceVersion))
+				return &fastly.Version{}, nil
+			}
+
+			var stdout bytes.Buffer
+			opts := testutil.NewRunOpts(testcase.args, &stdout)
+			opts.APIClient = mock.APIClient(testutil.ListEmpty(api))
+			err := app.Run(opts)
+			testutil.AssertErrorContains(t, err, testcase.wantError)
+			for _, s := range testcase.wantOutput {
+				testutil.AssertStringContains(t, stdout.String(), s)
+			}
+			testutil.AssertEqual(t, testcase.wantCalls, calls)
+		})
+	}
+}
+
+func TestServiceApplyDictionaryItems(t *testing.T) {
+	specFile := filepath.Join(t.TempDir(), "service.toml")
+	err := os.WriteFile(specFile, []byte(`spec_version = 1
+
+[[resources.dictionary]]
+  name = "existing"
+  [resources.dictionary.items]
+    a = "1"
+    b = "changed"
+
+[[resources.dictionary]]
+  name = "new"
+  [resources.dictionary.items]
+    x = "1"
+`), 0644)
+	testutil.AssertNoError(t, err)
+
+	args := testutil.Args
+	for _, testcase := range []struct {
+		name       string
+		args       []string
+		failItems  string
+		wantError  string
+		wantOutput []string
+		wantCalls  []string
+	}{
+		{
+			name: "validate dry run",
+			args: args("service apply --service-id 123 --dry-run --file " + specFile),
+			wantOutput: []string{
+				`+ dictionary "new"`,
+				`~ dictionary "new" items (1 created, 0 updated, 0 deleted)`,
+				"Dictionary item changes required to apply",
+				`~ dictionary "existing" items (0 created, 1 updated, 1 deleted)`,
+				"these changes take effect immediately",
+				"Dry run: 3 change(s) not applied",
+			},
+		},
+		{
+			name: "validate items of existing dictionaries are skipped",
+			args: args("service apply --service-id 123 --file " + specFile),
+			wantOutput: []string{
+				"The dictionary item changes won't be applied, use --items to apply them.",
+				"Applied 2 change(s) to service 123 version 4",
+			},
+			wantCalls: []string{
+				"clone 1",
+				"create dictionary new 4",
+				"batch new create:x",
+			},
+		},
+		{
+			name: "validate apply modifies items",
+			args: args("service apply --service-id 123 --items --file " + specFile),
+			wantOutput: []string{
+				"Applied 3 change(s) to service 123 version 4",
+			},
+			wantCalls: []string{
+				"clone 1",
+				"create dictionary new 4",
+				"batch new create:x",
+				"batch existing update:b delete:c",
+			},
+		},
+		{
+			name: "validate items are modified once the version is activated",
+			args: args("service apply --service-id 123 --items --activate --file " + specFile),
+			wantCalls: []string{
+				"clone 1",
+				"create dictionary new 4",
+				"batch new create:x",
+				"activate 4",
+				"batch existing update:b delete:c",
+			},
+		},
+		{
+			name:      "validate item failure stops the apply",
+			args:      args("service apply --service-id 123 --items --activate --file " + specFile),
+			failItems: "existing",
+			wantError: "error modifying items for dictionary existing: test error",
+			wantCalls: []string{
+				"clone 1",
+				"create dictionary new 4",
+				"batch new create:x",
+				"activate 4",
+				"batch existing update:b delete:c",
+			},
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			var calls []string
+			api := mock.API{
+				ListVersionsFn: testutil.ListVersions,
+				GetServiceFn:   getServiceOK,
+				ListDictionariesFn: func(i *fastly.ListDictionariesInput) ([]*fastly.Dictionary, error) {
+					return []*fastly.Dictionary{{ID: "d1", Name: "existing"}}, nil
+				},
+				ListDictionaryItemsFn: func(i *fastly.ListDictionaryItemsInput) ([]*fastly.DictionaryItem, error) {
+					return []*fastly.DictionaryItem{
+						{ItemKey: "a", ItemValue: "1"},
+						{ItemKey: "b", ItemValue: "2"},
+						{ItemKey: "c", ItemValue: "3"},
+					}, nil
+				},
+				CloneVersionFn: func(i *fastly.CloneVersionInput) (*fastly.Version, error) {
+					calls = append(calls, fmt.Sprintf("clone %d", i.ServiceVersion))
+					return &fastly.Version{ServiceID: i.ServiceID, Number: 4}, nil
+				},
+				GetDictionaryFn: func(i *fastly.GetDictionaryInput) (*fastly.Dictionary, error) {
+					return &fastly.Dictionary{ID: i.Name, Name: i.Name}, nil
+				},
+				CreateDictionaryFn: func(i *fastly.CreateDictionaryInput) (*fastly.Dictionary, error) {
+					calls = append(calls, fmt.Sprintf("create dictionary %s %d", i.Name, i.ServiceVersion))
+					return &fastly.Dictionary{ID: i.Name, Name: i.Name}, nil
+				},
+				DeleteDictionaryFn: func(i *fastly.DeleteDictionaryInput) error {
+					calls = append(calls, fmt.Sprintf("delete dictionary %s %d", i.Name, i.ServiceVersion))
+					return nil
+				},
+				ActivateVersionFn: func(i *fastly.ActivateVersionInput) (*fastly.Version, error) {
+					calls = append(calls, fmt.Sprintf("activate %d", i.ServiceVersion))
+					return &fastly.Version{ServiceID: i.ServiceID, Number: i.ServiceVersion}, nil
+				},
+				BatchModifyDictionaryItemsFn: func(i *fastly.BatchModifyDictionaryItemsInput) error {
+					call := "batch " + i.DictionaryID
+					for _, item := range i.Items {
+						call += fmt.Sprintf(" %s:%s", item.Operation, item.ItemKey)
+					}
+					calls = append(calls, call)
+					if i.DictionaryID == testcase.failItems {
+						return testutil.Err
+					}
+					return nil
+				},
+			}
+
+			var stdout bytes.Buffer
+			opts := testutil.NewRunOpts(testcase.args, &stdout)
+			opts.APIClient = mock.APIClient(testutil.ListEmpty(api))
+			err := app.Run(opts)
+			testutil.AssertErrorContains(t, err, testcase.wantError)
+			for _, s := range testcase.wantOutput {
+				testutil.AssertStringContains(t, stdout.String(), s)
+			}
+			testutil.AssertEqual(t, testcase.wantCalls, calls)
+		})
+	}
+}
+
+func TestServiceApplyNewDictionaryItemFailure(t *testing.T) {
+	specFile := filepath.Join(t.TempDir(), "service.toml")
+	err := os.WriteFile(specFile, []byte(`spec_version = 1
+
+[[resources.dictionary]]
+  name = "new"
+  [resources.dictionary.items]
+    x = "1"
+`), 0644)
+	testutil.AssertNoError(t, err)
+
+	var calls []string
+	api := mock.API{
+		ListVersionsFn: testutil.ListVersions,
+		GetServiceFn:   getServiceOK,
+		CloneVersionFn: func(i *fastly.CloneVersionInput) (*fastly.Version, error) {
+			return &fastly.Version{ServiceID: i.ServiceID, Number: 4}, nil
+		},
+		GetDictionaryFn: func(i *fastly.GetDictionaryInput) (*fastly.Dictionary, error) {
+			return &fastly.Dictionary{ID: i.Name, Name: i.Name}, nil
+		},
+		CreateDictionaryFn: func(i *fastly.CreateDictionaryInput) (*fastly.Dictionary, error) {
+			calls = append(calls, fmt.Sprintf("create dictionary %s", i.Name))
+			return &fastly.Dictionary{ID: i.Name, Name: i.Name}, nil
+		},
+		DeleteDictionaryFn: func(i *fastly.DeleteDictionaryInput) error {
+			calls = append(calls, fmt.Sprintf("delete dictionary %s", i.Name))
+			return nil
+		},
+		BatchModifyDictionaryItemsFn: func(i *fastly.BatchModifyDictionaryItemsInput) error {
+			calls = append(calls, "batch "+i.DictionaryID)
+			return testutil.Err
+		},
+	}
+
+	var stdout bytes.Buffer
+	opts := testutil.NewRunOpts(testutil.Args("service apply --service-id 123 --file "+specFile), &stdout)
+	opts.APIClient = mock.APIClient(testutil.ListEmpty(api))
+	err = app.Run(opts)
+	testutil.AssertErrorContains(t, err, "error modifying items for dictionary new: test error")
+	testutil.AssertEqual(t, []string{
+		"create dictionary new",
+		"batch new",
+		"delete dictionary new",
+	}, calls)
+}
+
 func createServiceOK(i *fastly.CreateServiceInput) (*fastly.Service, error) {
 	return &fastly.Service{
 		ID:      "12345",
@@ -741,6 +1161,48 @@ Versions: 2
 		Last edited (UTC): 2001-03-04 04:05
 `) + "\n"
 
+var searchServiceJSONOutput = strings.TrimSpace(`
+{
+  "comment": "",
+  "created_at": null,
+  "customer_id": "mycustomerid",
+  "deleted_at": null,
+  "id": "123",
+  "name": "Foo",
+  "type": "wasm",
+  "updated_at": "2010-11-15T19:01:02Z",
+  "version": 0,
+  "versions": [
+    {
+      "active": false,
+      "comment": "a",
+      "created_at": "2001-02-03T04:05:06Z",
+      "deleted_at": "2001-02-05T04:05:06Z",
+      "deployed": false,
+      "locked": false,
+      "number": 1,
+      "service_id": "b",
+      "staging": false,
+      "testing": false,
+      "updated_at": "2001-02-04T04:05:06Z"
+    },
+    {
+      "active": true,
+      "comment": "c",
+      "created_at": "2001-03-03T04:05:06Z",
+      "deleted_at": null,
+      "deployed": true,
+      "locked": false,
+      "number": 2,
+      "service_id": "d",
+      "staging": false,
+      "testing": false,
+      "updated_at": "2001-03-04T04:05:06Z"
+    }
+  ]
+}
+`) + "\n"
+
 func updateServiceOK(i *fastly.UpdateServiceInput) (*fastly.Service, error) {
 	return &fastly.Service{
 		ID: "12345",
@@ -758,3 +1220,50 @@ func deleteServiceOK(*fastly.DeleteServiceInput) error {
 func deleteServiceError(*fastly.DeleteServiceInput) error {
 	return errTest
 }
+
+func listBackendsOK(i *fastly.ListBackendsInput) ([]*fastly.Backend, error) {
+	return []*fastly.Backend{
+		{
+			ServiceID:      i.ServiceID,
+			ServiceVersion: i.ServiceVersion,
+			Name:           "origin",
+			Address:        "127.0.0.1",
+			Port:           443,
+			CreatedAt:      testutil.MustParseTimeRFC3339("2000-01-01T01:00:00Z"),
+		},
+	}, nil
+}
+
+func listSyslogsOK(i *fastly.ListSyslogsInput) ([]*fastly.Syslog, error) {
+	return []*fastly.Syslog{
+		{
+			ServiceID:      i.ServiceID,
+			ServiceVersion: i.ServiceVersion,
+			Name:           "logs",
+			Address:        "example.com",
+			Token:          "s3cr3t",
+		},
+	}, nil
+}
+
+func listDictionariesOK(i *fastly.ListDictionariesInput) ([]*fastly.Dictionary, error) {
+	return []*fastly.Dictionary{
+		{
+			ServiceID:      i.ServiceID,
+			ServiceVersion: i.ServiceVersion,
+			ID:             "456",
+			Name:           "settings",
+		},
+	}, nil
+}
+
+func listDictionaryItemsOK(i *fastly.ListDictionaryItemsInput) ([]*fastly.DictionaryItem, error) {
+	return []*fastly.DictionaryItem{
+		{
+			ServiceID:    i.ServiceID,
+			DictionaryID: i.DictionaryID,
+			ItemKey:      "foo",
+			ItemValue:    "bar",
+		},
+	}, nil
+}
@@ -0,0 +1,230 @@
+package spec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DiffContext is the number of unchanged lines shown around each change to a
+// multi-line value (e.g. VCL content).
+const DiffContext = 3
+
+// Difference describes how a single resource differs between two specs.
+type Difference struct {
+	Kind   string        `json:"kind"`
+	Name   string        `json:"name"`
+	Action Action        `json:"action"`
+	Fields []FieldChange `json:"fields"`
+}
+
+// FieldChange describes how a single resource field differs between two
+// specs. From is nil when the field was added and To is nil when it was
+// removed.
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// Diff returns the differences between the resources of two specs, ordered by
+// Kinds and then by resource name.
+//
+// Dictionary items aren't versioned and so they're not compared.
+func Diff(from, to *Spec) []Difference {
+	var ds []Difference
+	for _, k := range Kinds {
+		a := byName(from.Get(k))
+		b := byName(to.Get(k))
+
+		names := make([]string, 0, len(a)+len(b))
+		for name := range a {
+			names = append(names, name)
+		}
+		for name := range b {
+			if _, ok := a[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			d := Difference{Kind: k.Name, Name: name, Fields: fieldChanges(a[name], b[name])}
+			switch {
+			case a[name] == nil:
+				d.Action = ActionCreate
+			case b[name] == nil:
+				d.Action = ActionDelete
+			case len(d.Fields) > 0:
+				d.Action = ActionUpdate
+			default:
+				continue
+			}
+			ds = append(ds, d)
+		}
+	}
+	return ds
+}
+
+// fieldChanges returns the fields whose values differ between two versions of
+// a resource, either of which may be nil.
+func fieldChanges(a, b Resource) []FieldChange {
+	keys := make(map[string]bool)
+	for key := range a {
+		keys[key] = true
+	}
+	for key := range b {
+		keys[key] = true
+	}
+	delete(keys, ItemsKey)
+
+	var fs []FieldChange
+	for key := range keys {
+		if av, bv := a[key], b[key]; !reflect.DeepEqual(av, bv) {
+			fs = append(fs, FieldChange{Field: key, From: av, To: bv})
+		}
+	}
+	sort.Slice(fs, func(i, j int) bool {
+		return fs[i].Field < fs[j].Field
+	})
+	return fs
+}
+
+// Redact replaces the values of secret fields with a placeholder, so a change
+// to a secret is reported without revealing it.
+func (d Difference) Redact() {
+	for i, f := range d.Fields {
+		if !secretKeys[f.Field] {
+			continue
+		}
+		if f.From != nil {
+			d.Fields[i].From = Redacted
+		}
+		if f.To != nil {
+			d.Fields[i].To = Redacted
+		}
+	}
+}
+
+// Format returns the difference as unified diff style lines, e.g.
+//
+//	~ backend "origin"
+//	    - address = "127.0.0.1"
+//	    + address = "127.0.0.2"
+//
+// Changes to multi-line string values are shown as a line diff.
+func (d Difference) Format() []string {
+	sign := map[Action]string{ActionCreate: "+", ActionUpdate: "~", ActionDelete: "-"}[d.Action]
+	lines := []string{fmt.Sprintf("%s %s %q", sign, d.Kind, d.Name)}
+
+	for _, f := range d.Fields {
+		fs, fok := f.From.(string)
+		ts, tok := f.To.(string)
+		if (fok || f.From == nil) && (tok || f.To == nil) && (strings.Contains(fs, "\n") || strings.Contains(ts, "\n")) {
+			lines = append(lines, fmt.Sprintf("    %s:", f.Field))
+			for _, l := range LineDiff(fs, ts, DiffContext) {
+				lines = append(lines, "      "+l)
+			}
+			continue
+		}
+		if f.From != nil {
+			lines = append(lines, fmt.Sprintf("    - %s = %s", f.Field, formatValue(f.From)))
+		}
+		if f.To != nil {
+			lines = append(lines, fmt.Sprintf("    + %s = %s", f.Field, formatValue(f.To)))
+		}
+	}
+	return lines
+}
+
+// formatValue renders a value as it would appear in a spec file.
+func formatValue(v interface{}) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// LineDiff returns a line diff of two strings, with each line prefixed by
+// "-", "+" or " " (unchanged). Only context unchanged lines are kept either
+// side of a change, with omitted lines replaced by a "@@" separator.
+func LineDiff(a, b string, context int) []string {
+	al := splitLines(a)
+	bl := splitLines(b)
+
+	// lcs[i][j] is the length of the longest common subsequence of al[i:] and
+	// bl[j:].
+	lcs := make([][]int, len(al)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bl)+1)
+	}
+	for i := len(al) - 1; i >= 0; i-- {
+		for j := len(bl) - 1; j >= 0; j-- {
+			if al[i] == bl[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var all []string
+	i, j := 0, 0
+	for i < len(al) || j < len(bl) {
+		switch {
+		case i < len(al) && j < len(bl) && al[i] == bl[j]:
+			all = append(all, " "+al[i])
+			i++
+			j++
+		case i < len(al) && (j == len(bl) || lcs[i+1][j] >= lcs[i][j+1]):
+			all = append(all, "-"+al[i])
+			i++
+		default:
+			all = append(all, "+"+bl[j])
+			j++
+		}
+	}
+
+	// Keep only the unchanged lines within context of a change.
+	keep := make([]bool, len(all))
+	for n, l := range all {
+		if l[0] == ' ' {
+			continue
+		}
+		for m := n - context; m <= n+context; m++ {
+			if m >= 0 && m < len(all) {
+				keep[m] = true
+			}
+		}
+	}
+	var lines []string
+	skipped := false
+	for n, l := range all {
+		if !keep[n] {
+			skipped = true
+			continue
+		}
+		if skipped && len(lines) > 0 {
+			lines = append(lines, "@@")
+		}
+		skipped = false
+		lines = append(lines, l)
+	}
+	return lines
+}
+
+// splitLines splits s into lines, ignoring a trailing newline.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,3 @@
+// Package spec defines the service spec file format, a declarative, diff
+// friendly description of every resource on a Fastly service version.
+package spec
@@ -0,0 +1,88 @@
+package spec
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/fastly/cli/pkg/api"
+	"github.com/fastly/go-fastly/v3/fastly"
+)
+
+// Export fetches every resource on the service version and returns them as a
+// spec. Secrets are included, so callers should Redact the spec as required.
+func Export(c api.Interface, serviceID string, serviceVersion int) (*Spec, error) {
+	return export(c, serviceID, serviceVersion, true)
+}
+
+// ExportVersioned is Export without the items of dictionaries, which aren't
+// versioned and so are the same for every version.
+func ExportVersioned(c api.Interface, serviceID string, serviceVersion int) (*Spec, error) {
+	return export(c, serviceID, serviceVersion, false)
+}
+
+func export(c api.Interface, serviceID string, serviceVersion int, items bool) (*Spec, error) {
+	service, err := c.GetService(&fastly.GetServiceInput{
+		ID: serviceID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching service: %w", err)
+	}
+
+	s := New()
+	s.Service = Service{
+		ID:      service.ID,
+		Name:    service.Name,
+		Type:    service.Type,
+		Comment: service.Comment,
+		Version: serviceVersion,
+	}
+
+	for _, k := range Kinds {
+		rs, err := listResources(c, k, serviceID, serviceVersion, items)
+		if err != nil {
+			return nil, err
+		}
+		s.Set(k, rs)
+	}
+
+	return s, nil
+}
+
+// ListResources fetches every resource of the given kind on the service
+// version. Dictionaries include their items, unless they're write-only.
+func ListResources(c api.Interface, k Kind, serviceID string, serviceVersion int) ([]Resource, error) {
+	return listResources(c, k, serviceID, serviceVersion, true)
+}
+
+func listResources(c api.Interface, k Kind, serviceID string, serviceVersion int, items bool) ([]Resource, error) {
+	v, err := k.list(c, serviceID, serviceVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error listing %s resources: %w", k.Name, err)
+	}
+
+	rv := reflect.ValueOf(v)
+	rs := make([]Resource, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		elem := rv.Index(i).Interface()
+		r, err := NewResource(elem)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s resource: %w", k.Name, err)
+		}
+		if d, ok := elem.(*fastly.Dictionary); ok && items && !d.WriteOnly {
+			items, err := c.ListDictionaryItems(&fastly.ListDictionaryItemsInput{
+				ServiceID:    serviceID,
+				DictionaryID: d.ID,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error listing items for dictionary %s: %w", d.Name, err)
+			}
+			m := make(map[string]interface{}, len(items))
+			for _, item := range items {
+				m[item.ItemKey] = item.ItemValue
+			}
+			r[ItemsKey] = m
+		}
+		rs = append(rs, r)
+	}
+	return rs, nil
+}
@@ -0,0 +1,358 @@
+package spec
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/fastly/cli/pkg/api"
+	"github.com/mitchellh/mapstructure"
+)
+
+// Kind describes a type of resource that belongs to a service version.
+//
+// The List, Create, Update and Delete fields are api.Interface method
+// expressions (e.g. api.Interface.ListBackends). Every input type accepted by
+// these methods identifies the resource using the ServiceID, ServiceVersion
+// and Name fields, which lets us drive them all with the same logic.
+type Kind struct {
+	// Name is the key under which resources of this kind are stored.
+	Name string
+
+	// Logging indicates the kind is a logging endpoint, which are grouped
+	// together in the spec.
+	Logging bool
+
+	List   interface{}
+	Create interface{}
+	Update interface{}
+	Delete interface{}
+}
+
+// Kinds is every kind of resource supported by the spec, in the order they
+// should be created on a service version (e.g. healthchecks are created before
+// the backends that reference them).
+var Kinds = []Kind{
+	{
+		Name:   "domain",
+		List:   api.Interface.ListDomains,
+		Create: api.Interface.CreateDomain,
+		Update: api.Interface.UpdateDomain,
+		Delete: api.Interface.DeleteDomain,
+	},
+	{
+		Name:   "healthcheck",
+		List:   api.Interface.ListHealthChecks,
+		Create: api.Interface.CreateHealthCheck,
+		Update: api.Interface.UpdateHealthCheck,
+		Delete: api.Interface.DeleteHealthCheck,
+	},
+	{
+		Name:   "backend",
+		List:   api.Interface.ListBackends,
+		Create: api.Interface.CreateBackend,
+		Update: api.Interface.UpdateBackend,
+		Delete: api.Interface.DeleteBackend,
+	},
+	{
+		Name:   "dictionary",
+		List:   api.Interface.ListDictionaries,
+		Create: api.Interface.CreateDictionary,
+		Update: api.Interface.UpdateDictionary,
+		Delete: api.Interface.DeleteDictionary,
+	},
+	{
+		Name:   "vcl",
+		List:   api.Interface.ListVCLs,
+		Create: api.Interface.CreateVCL,
+		Update: api.Interface.UpdateVCL,
+		Delete: api.Interface.DeleteVCL,
+	},
+	{
+		Name:   "snippet",
+		List:   api.Interface.ListSnippets,
+		Create: api.Interface.CreateSnippet,
+		Update: api.Interface.UpdateSnippet,
+		Delete: api.Interface.DeleteSnippet,
+	},
+	{
+		Name:    "azureblob",
+		Logging: true,
+		List:    api.Interface.ListBlobStorages,
+		Create:  api.Interface.CreateBlobStorage,
+		Update:  api.Interface.UpdateBlobStorage,
+		Delete:  api.Interface.DeleteBlobStorage,
+	},
+	{
+		Name:    "bigquery",
+		Logging: true,
+		List:    api.Interface.ListBigQueries,
+		Create:  api.Interface.CreateBigQuery,
+		Update:  api.Interface.UpdateBigQuery,
+		Delete:  api.Interface.DeleteBigQuery,
+	},
+	{
+		Name:    "cloudfiles",
+		Logging: true,
+		List:    api.Interface.ListCloudfiles,
+		Create:  api.Interface.CreateCloudfiles,
+		Update:  api.Interface.UpdateCloudfiles,
+		Delete:  api.Interface.DeleteCloudfiles,
+	},
+	{
+		Name:    "datadog",
+		Logging: true,
+		List:    api.Interface.ListDatadog,
+		Create:  api.Interface.CreateDatadog,
+		Update:  api.Interface.UpdateDatadog,
+		Delete:  api.Interface.DeleteDatadog,
+	},
+	{
+		Name:    "digitalocean",
+		Logging: true,
+		List:    api.Interface.ListDigitalOceans,
+		Create:  api.Interface.CreateDigitalOcean,
+		Update:  api.Interface.UpdateDigitalOcean,
+		Delete:  api.Interface.DeleteDigitalOcean,
+	},
+	{
+		Name:    "elasticsearch",
+		Logging: true,
+		List:    api.Interface.ListElasticsearch,
+		Create:  api.Interface.CreateElasticsearch,
+		Update:  api.Interface.UpdateElasticsearch,
+		Delete:  api.Interface.DeleteElasticsearch,
+	},
+	{
+		Name:    "ftp",
+		Logging: true,
+		List:    api.Interface.ListFTPs,
+		Create:  api.Interface.CreateFTP,
+		Update:  api.Interface.UpdateFTP,
+		Delete:  api.Interface.DeleteFTP,
+	},
+	{
+		Name:    "gcs",
+		Logging: true,
+		List:    api.Interface.ListGCSs,
+		Create:  api.Interface.CreateGCS,
+		Update:  api.Interface.UpdateGCS,
+		Delete:  api.Interface.DeleteGCS,
+	},
+	{
+		Name:    "googlepubsub",
+		Logging: true,
+		List:    api.Interface.ListPubsubs,
+		Create:  api.Interface.CreatePubsub,
+		Update:  api.Interface.UpdatePubsub,
+		Delete:  api.Interface.DeletePubsub,
+	},
+	{
+		Name:    "heroku",
+		Logging: true,
+		List:    api.Interface.ListHerokus,
+		Create:  api.Interface.CreateHeroku,
+		Update:  api.Interface.UpdateHeroku,
+		Delete:  api.Interface.DeleteHeroku,
+	},
+	{
+		Name:    "honeycomb",
+		Logging: true,
+		List:    api.Interface.ListHoneycombs,
+		Create:  api.Interface.CreateHoneycomb,
+		Update:  api.Interface.UpdateHoneycomb,
+		Delete:  api.Interface.DeleteHoneycomb,
+	},
+	{
+		Name:    "https",
+		Logging: true,
+		List:    api.Interface.ListHTTPS,
+		Create:  api.Interface.CreateHTTPS,
+		Update:  api.Interface.UpdateHTTPS,
+		Delete:  api.Interface.DeleteHTTPS,
+	},
+	{
+		Name:    "kafka",
+		Logging: true,
+		List:    api.Interface.ListKafkas,
+		Create:  api.Interface.CreateKafka,
+		Update:  api.Interface.UpdateKafka,
+		Delete:  api.Interface.DeleteKafka,
+	},
+	{
+		Name:    "kinesis",
+		Logging: true,
+		List:    api.Interface.ListKinesis,
+		Create:  api.Interface.CreateKinesis,
+		Update:  api.Interface.UpdateKinesis,
+		Delete:  api.Interface.DeleteKinesis,
+	},
+	{
+		Name:    "logentries",
+		Logging: true,
+		List:    api.Interface.ListLogentries,
+		Create:  api.Interface.CreateLogentries,
+		Update:  api.Interface.UpdateLogentries,
+		Delete:  api.Interface.DeleteLogentries,
+	},
+	{
+		Name:    "loggly",
+		Logging: true,
+		List:    api.Interface.ListLoggly,
+		Create:  api.Interface.CreateLoggly,
+		Update:  api.Interface.UpdateLoggly,
+		Delete:  api.Interface.DeleteLoggly,
+	},
+	{
+		Name:    "logshuttle",
+		Logging: true,
+		List:    api.Interface.ListLogshuttles,
+		Create:  api.Interface.CreateLogshuttle,
+		Update:  api.Interface.UpdateLogshuttle,
+		Delete:  api.Interface.DeleteLogshuttle,
+	},
+	{
+		Name:    "openstack",
+		Logging: true,
+		List:    api.Interface.ListOpenstack,
+		Create:  api.Interface.CreateOpenstack,
+		Update:  api.Interface.UpdateOpenstack,
+		Delete:  api.Interface.DeleteOpenstack,
+	},
+	{
+		Name:    "papertrail",
+		Logging: true,
+		List:    api.Interface.ListPapertrails,
+		Create:  api.Interface.CreatePapertrail,
+		Update:  api.Interface.UpdatePapertrail,
+		Delete:  api.Interface.DeletePapertrail,
+	},
+	{
+		Name:    "s3",
+		Logging: true,
+		List:    api.Interface.ListS3s,
+		Create:  api.Interface.CreateS3,
+		Update:  api.Interface.UpdateS3,
+		Delete:  api.Interface.DeleteS3,
+	},
+	{
+		Name:    "scalyr",
+		Logging: true,
+		List:    api.Interface.ListScalyrs,
+		Create:  api.Interface.CreateScalyr,
+		Update:  api.Interface.UpdateScalyr,
+		Delete:  api.Interface.DeleteScalyr,
+	},
+	{
+		Name:    "sftp",
+		Logging: true,
+		List:    api.Interface.ListSFTPs,
+		Create:  api.Interface.CreateSFTP,
+		Update:  api.Interface.UpdateSFTP,
+		Delete:  api.Interface.DeleteSFTP,
+	},
+	{
+		Name:    "splunk",
+		Logging: true,
+		List:    api.Interface.ListSplunks,
+		Create:  api.Interface.CreateSplunk,
+		Update:  api.Interface.UpdateSplunk,
+		Delete:  api.Interface.DeleteSplunk,
+	},
+	{
+		Name:    "sumologic",
+		Logging: true,
+		List:    api.Interface.ListSumologics,
+		Create:  api.Interface.CreateSumologic,
+		Update:  api.Interface.UpdateSumologic,
+		Delete:  api.Interface.DeleteSumologic,
+	},
+	{
+		Name:    "syslog",
+		Logging: true,
+		List:    api.Interface.ListSyslogs,
+		Create:  api.Interface.CreateSyslog,
+		Update:  api.Interface.UpdateSyslog,
+		Delete:  api.Interface.DeleteSyslog,
+	},
+}
+
+// KindByName returns the kind with the given name.
+func KindByName(name string) (Kind, bool) {
+	for _, k := range Kinds {
+		if k.Name == name {
+			return k, true
+		}
+	}
+	return Kind{}, false
+}
+
+// list returns every resource of this kind on the service version,
+// as the API response type (e.g. []*fastly.Backend).
+func (k Kind) list(c api.Interface, serviceID string, serviceVersion int) (interface{}, error) {
+	return call(k.List, c, serviceID, serviceVersion, "", nil)
+}
+
+// CreateResource creates the resource on the service version, returning the
+// API response type (e.g. *fastly.Backend).
+func (k Kind) CreateResource(c api.Interface, serviceID string, serviceVersion int, r Resource) (interface{}, error) {
+	return call(k.Create, c, serviceID, serviceVersion, "", r)
+}
+
+// UpdateResource updates the named resource on the service version. The
+// resource may include a different name, in which case it's renamed.
+func (k Kind) UpdateResource(c api.Interface, serviceID string, serviceVersion int, name string, r Resource) error {
+	_, err := call(k.Update, c, serviceID, serviceVersion, name, r)
+	return err
+}
+
+// DeleteResource deletes the named resource from the service version.
+func (k Kind) DeleteResource(c api.Interface, serviceID string, serviceVersion int, name string) error {
+	_, err := call(k.Delete, c, serviceID, serviceVersion, name, nil)
+	return err
+}
+
+// call invokes the api.Interface method expression fn, with an input
+// populated from the resource fields (using the input's form tags) and the
+// given identifiers.
+func call(fn interface{}, c api.Interface, serviceID string, serviceVersion int, name string, r Resource) (interface{}, error) {
+	fv := reflect.ValueOf(fn)
+	input := reflect.New(fv.Type().In(1).Elem())
+
+	if r != nil {
+		dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			TagName:          "form",
+			WeaklyTypedInput: true,
+			Result:           input.Interface(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := dec.Decode(r.fields()); err != nil {
+			return nil, fmt.Errorf("error reading resource %s: %w", r.Name(), err)
+		}
+	}
+
+	elem := input.Elem()
+	setField(elem, "ServiceID", serviceID)
+	setField(elem, "ServiceVersion", serviceVersion)
+	if name != "" {
+		setField(elem, "Name", name)
+	}
+
+	out := fv.Call([]reflect.Value{reflect.ValueOf(&c).Elem(), input})
+	if err, _ := out[len(out)-1].Interface().(error); err != nil {
+		return nil, err
+	}
+	if len(out) == 1 {
+		return nil, nil
+	}
+	return out[0].Interface(), nil
+}
+
+// setField assigns v to the named struct field, if it exists.
+func setField(s reflect.Value, name string, v interface{}) {
+	f := s.FieldByName(name)
+	if f.IsValid() && f.CanSet() {
+		f.Set(reflect.ValueOf(v))
+	}
+}
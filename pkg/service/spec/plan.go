@@ -0,0 +1,263 @@
+package spec
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/fastly/cli/pkg/api"
+	"github.com/fastly/go-fastly/v3/fastly"
+)
+
+// Action is the type of change an Operation makes to a resource.
+type Action string
+
+// Actions an Operation can perform.
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+
+	// ActionItems creates, updates and deletes the items of a dictionary,
+	// which aren't versioned, to match the desired resource.
+	ActionItems Action = "items"
+)
+
+// Operation is a single change required to reconcile a service version with a
+// spec.
+type Operation struct {
+	Action Action
+	Kind   Kind
+	Name   string
+
+	// Current is the resource as it exists on the service version, and is nil
+	// when creating a resource.
+	Current Resource
+
+	// Desired is the resource as it's defined in the spec, and is nil when
+	// deleting a resource.
+	Desired Resource
+}
+
+// Plan returns the operations required to reconcile the current spec with the
+// desired spec.
+//
+// Creates and updates are ordered by Kinds, followed by deletes in the reverse
+// order, so a resource is never referenced before it's created or after it's
+// deleted (e.g. a backend's healthcheck).
+func Plan(current, desired *Spec) []Operation {
+	var ops, deletes []Operation
+
+	for _, k := range Kinds {
+		have := byName(current.Get(k))
+		want := byName(desired.Get(k))
+
+		for _, r := range desired.Get(k) {
+			c, ok := have[r.Name()]
+			if !ok {
+				// The items are created by a separate operation, so the
+				// dictionary's creation is undone if they fail.
+				ops = append(ops, Operation{Action: ActionCreate, Kind: k, Name: r.Name(), Desired: r.withoutItems()})
+				if items, _ := r[ItemsKey].(map[string]interface{}); len(items) > 0 {
+					ops = append(ops, Operation{Action: ActionItems, Kind: k, Name: r.Name(), Desired: r})
+				}
+				continue
+			}
+			if len(Changes(c, r)) > 0 {
+				ops = append(ops, Operation{Action: ActionUpdate, Kind: k, Name: r.Name(), Current: c, Desired: r})
+			}
+			if itemsChanged(c, r) {
+				ops = append(ops, Operation{Action: ActionItems, Kind: k, Name: r.Name(), Current: c, Desired: r})
+			}
+		}
+
+		var kindDeletes []Operation
+		for _, r := range current.Get(k) {
+			if _, ok := want[r.Name()]; !ok {
+				kindDeletes = append(kindDeletes, Operation{Action: ActionDelete, Kind: k, Name: r.Name(), Current: r})
+			}
+		}
+		deletes = append(kindDeletes, deletes...)
+	}
+
+	return append(ops, deletes...)
+}
+
+// Changes returns the sorted names of the fields defined by the desired
+// resource whose values differ from the current resource. Redacted values are
+// never considered changed, and dictionary items are compared separately by
+// ItemOperations.
+func Changes(current, desired Resource) []string {
+	var keys []string
+	for key, v := range desired {
+		if key == ItemsKey || v == Redacted {
+			continue
+		}
+		if !reflect.DeepEqual(current[key], v) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// String returns a one line summary of the operation, e.g.
+// `~ backend "origin" (address, port)`.
+func (o Operation) String() string {
+	switch o.Action {
+	case ActionCreate:
+		return fmt.Sprintf("+ %s %q", o.Kind.Name, o.Name)
+	case ActionUpdate:
+		return fmt.Sprintf("~ %s %q (%s)", o.Kind.Name, o.Name, strings.Join(Changes(o.Current, o.Desired), ", "))
+	case ActionDelete:
+		return fmt.Sprintf("- %s %q", o.Kind.Name, o.Name)
+	case ActionItems:
+		counts := make(map[fastly.BatchOperation]int)
+		for _, item := range ItemOperations(o.Current, o.Desired) {
+			counts[item.Operation]++
+		}
+		return fmt.Sprintf("~ %s %q items (%d created, %d updated, %d deleted)", o.Kind.Name, o.Name,
+			counts[fastly.CreateBatchOperation], counts[fastly.UpdateBatchOperation], counts[fastly.DeleteBatchOperation])
+	}
+	return fmt.Sprintf("? %s %q", o.Kind.Name, o.Name)
+}
+
+// Live reports whether the operation changes the service as soon as it's
+// applied, rather than once the service version is activated. That's the case
+// for the items of an existing dictionary, which aren't versioned and so are
+// shared with the active version.
+func (o Operation) Live() bool {
+	return o.Action == ActionItems && o.Current != nil
+}
+
+// Inverse returns the operation which reverts this operation.
+func (o Operation) Inverse() Operation {
+	inverse := Operation{Kind: o.Kind, Name: o.Name, Current: o.Desired, Desired: o.Current}
+	switch o.Action {
+	case ActionCreate:
+		inverse.Action = ActionDelete
+	case ActionUpdate:
+		inverse.Action = ActionUpdate
+	case ActionDelete:
+		inverse.Action = ActionCreate
+	case ActionItems:
+		inverse.Action = ActionItems
+	}
+	return inverse
+}
+
+// Apply performs the operation against the service version.
+//
+// NOTE: dictionary items aren't versioned, so a dictionary created with items
+// (i.e. when undoing its deletion) is populated straight away, otherwise
+// they're changed by an ActionItems operation.
+func (o Operation) Apply(c api.Interface, serviceID string, serviceVersion int) error {
+	switch o.Action {
+	case ActionCreate:
+		v, err := o.Kind.CreateResource(c, serviceID, serviceVersion, o.Desired)
+		if err != nil {
+			return fmt.Errorf("error creating %s %s: %w", o.Kind.Name, o.Name, err)
+		}
+		if d, ok := v.(*fastly.Dictionary); ok {
+			if err := modifyItems(c, serviceID, d.ID, ItemOperations(nil, o.Desired)); err != nil {
+				return fmt.Errorf("error creating items for dictionary %s: %w", o.Name, err)
+			}
+		}
+	case ActionUpdate:
+		if err := o.Kind.UpdateResource(c, serviceID, serviceVersion, o.Name, o.Desired); err != nil {
+			return fmt.Errorf("error updating %s %s: %w", o.Kind.Name, o.Name, err)
+		}
+	case ActionDelete:
+		if err := o.Kind.DeleteResource(c, serviceID, serviceVersion, o.Name); err != nil {
+			return fmt.Errorf("error deleting %s %s: %w", o.Kind.Name, o.Name, err)
+		}
+	case ActionItems:
+		d, err := c.GetDictionary(&fastly.GetDictionaryInput{
+			ServiceID:      serviceID,
+			ServiceVersion: serviceVersion,
+			Name:           o.Name,
+		})
+		if err != nil {
+			return fmt.Errorf("error fetching dictionary %s: %w", o.Name, err)
+		}
+		if err := modifyItems(c, serviceID, d.ID, ItemOperations(o.Current, o.Desired)); err != nil {
+			return fmt.Errorf("error modifying items for dictionary %s: %w", o.Name, err)
+		}
+	default:
+		return fmt.Errorf("unrecognised operation: %s", o.Action)
+	}
+	return nil
+}
+
+// ItemOperations returns the batch operations, sorted by key, which change the
+// items of the current dictionary resource to those of the desired one. Either
+// resource may be nil.
+func ItemOperations(current, desired Resource) []*fastly.BatchDictionaryItem {
+	have, _ := current[ItemsKey].(map[string]interface{})
+	want, _ := desired[ItemsKey].(map[string]interface{})
+
+	var ops []*fastly.BatchDictionaryItem
+	for key, v := range want {
+		value := fmt.Sprint(v)
+		cv, ok := have[key]
+		switch {
+		case !ok:
+			ops = append(ops, &fastly.BatchDictionaryItem{Operation: fastly.CreateBatchOperation, ItemKey: key, ItemValue: value})
+		case fmt.Sprint(cv) != value:
+			ops = append(ops, &fastly.BatchDictionaryItem{Operation: fastly.UpdateBatchOperation, ItemKey: key, ItemValue: value})
+		}
+	}
+	for key := range have {
+		if _, ok := want[key]; !ok {
+			ops = append(ops, &fastly.BatchDictionaryItem{Operation: fastly.DeleteBatchOperation, ItemKey: key})
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].ItemKey < ops[j].ItemKey
+	})
+	return ops
+}
+
+// itemsChanged reports whether the items of an existing dictionary differ
+// from the spec. The items are only compared if the spec defines them and
+// they could be listed, which isn't the case for a write-only dictionary.
+func itemsChanged(current, desired Resource) bool {
+	if _, ok := desired[ItemsKey]; !ok {
+		return false
+	}
+	if _, ok := current[ItemsKey]; !ok {
+		return false
+	}
+	return len(ItemOperations(current, desired)) > 0
+}
+
+// modifyItems sends the item operations to the dictionary, in batches of the
+// maximum size the API accepts.
+func modifyItems(c api.Interface, serviceID, dictionaryID string, items []*fastly.BatchDictionaryItem) error {
+	for len(items) > 0 {
+		n := len(items)
+		if n > fastly.BatchModifyMaximumOperations {
+			n = fastly.BatchModifyMaximumOperations
+		}
+		err := c.BatchModifyDictionaryItems(&fastly.BatchModifyDictionaryItemsInput{
+			ServiceID:    serviceID,
+			DictionaryID: dictionaryID,
+			Items:        items[:n],
+		})
+		if err != nil {
+			return err
+		}
+		items = items[n:]
+	}
+	return nil
+}
+
+// byName indexes the resources by name.
+func byName(rs []Resource) map[string]Resource {
+	m := make(map[string]Resource, len(rs))
+	for _, r := range rs {
+		m[r.Name()] = r
+	}
+	return m
+}
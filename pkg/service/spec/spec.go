@@ -0,0 +1,293 @@
+package spec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	toml "github.com/pelletier/go-toml"
+)
+
+const (
+	// LatestVersion represents the latest known spec schema version supported
+	// by the CLI.
+	LatestVersion = 1
+
+	// FilePermissions represents a read/write file mode.
+	FilePermissions = 0644
+
+	// FormatTOML is the TOML spec file format.
+	FormatTOML = "toml"
+
+	// FormatJSON is the JSON spec file format.
+	FormatJSON = "json"
+
+	// Redacted is the placeholder written in place of secret values.
+	Redacted = "<redacted>"
+
+	// ItemsKey is the dictionary resource key holding the dictionary items.
+	ItemsKey = "items"
+)
+
+// metadataKeys are resource fields assigned by the API, which are either
+// specific to a service version or change on every write, and so are never
+// included in a spec.
+var metadataKeys = []string{
+	"created_at",
+	"deleted_at",
+	"id",
+	"service_id",
+	"updated_at",
+	"version",
+}
+
+// secretKeys are resource fields containing credentials, which are redacted
+// unless explicitly requested.
+var secretKeys = map[string]bool{
+	"access_key":     true,
+	"access_token":   true,
+	"password":       true,
+	"sas_token":      true,
+	"secret_key":     true,
+	"ssl_client_key": true,
+	"tls_client_key": true,
+	"token":          true,
+}
+
+// Spec represents the resources of a single Fastly service version.
+type Spec struct {
+	SpecVersion int                   `json:"spec_version" toml:"spec_version"`
+	Service     Service               `json:"service" toml:"service"`
+	Resources   map[string][]Resource `json:"resources" toml:"resources"`
+	Logging     map[string][]Resource `json:"logging" toml:"logging"`
+}
+
+// Service represents the service the spec was exported from.
+type Service struct {
+	ID      string `json:"id" toml:"id"`
+	Name    string `json:"name" toml:"name"`
+	Type    string `json:"type" toml:"type"`
+	Comment string `json:"comment" toml:"comment"`
+	Version int    `json:"version" toml:"version"`
+}
+
+// Resource is a single service version resource, keyed by its API field
+// names. Every resource is uniquely identified within its kind by name.
+type Resource map[string]interface{}
+
+// Name returns the name identifying the resource.
+func (r Resource) Name() string {
+	name, _ := r["name"].(string)
+	return name
+}
+
+// fields returns the resource fields which can be sent to the API, omitting
+// the dictionary items and any redacted secrets.
+func (r Resource) fields() map[string]interface{} {
+	m := make(map[string]interface{}, len(r))
+	for key, v := range r {
+		if key == ItemsKey || v == Redacted {
+			continue
+		}
+		m[key] = v
+	}
+	return m
+}
+
+// withoutItems returns a copy of the resource without its dictionary items.
+func (r Resource) withoutItems() Resource {
+	m := make(Resource, len(r))
+	for key, v := range r {
+		if key != ItemsKey {
+			m[key] = v
+		}
+	}
+	return m
+}
+
+// New returns an empty spec.
+func New() *Spec {
+	return &Spec{
+		SpecVersion: LatestVersion,
+		Resources:   make(map[string][]Resource),
+		Logging:     make(map[string][]Resource),
+	}
+}
+
+// Get returns the resources of the given kind.
+func (s *Spec) Get(k Kind) []Resource {
+	if k.Logging {
+		return s.Logging[k.Name]
+	}
+	return s.Resources[k.Name]
+}
+
+// Set replaces the resources of the given kind, sorting them by name.
+func (s *Spec) Set(k Kind, rs []Resource) {
+	sort.SliceStable(rs, func(i, j int) bool {
+		return rs[i].Name() < rs[j].Name()
+	})
+	m := s.Resources
+	if k.Logging {
+		m = s.Logging
+	}
+	if len(rs) == 0 {
+		delete(m, k.Name)
+		return
+	}
+	m[k.Name] = rs
+}
+
+// Redact replaces the value of every secret field with a placeholder.
+func (s *Spec) Redact() {
+	for _, k := range Kinds {
+		for _, r := range s.Get(k) {
+			for key, v := range r {
+				if secretKeys[key] && v != "" {
+					r[key] = Redacted
+				}
+			}
+		}
+	}
+}
+
+// Marshal encodes the spec in the given format.
+func (s *Spec) Marshal(format string) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		bs, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(bs, '\n'), nil
+	case FormatTOML:
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(s); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unsupported spec format: %s", format)
+}
+
+// Unmarshal decodes a spec in the given format.
+func Unmarshal(bs []byte, format string) (*Spec, error) {
+	s := New()
+	var err error
+	switch format {
+	case FormatJSON:
+		err = json.Unmarshal(bs, s)
+	case FormatTOML:
+		err = toml.Unmarshal(bs, s)
+	default:
+		err = fmt.Errorf("unsupported spec format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.SpecVersion != LatestVersion {
+		return nil, fmt.Errorf("unrecognised spec_version: %d (expected %d)", s.SpecVersion, LatestVersion)
+	}
+
+	// Decoders produce different types for the same value (e.g. JSON numbers
+	// are always float64) so we normalise to make specs comparable.
+	for _, m := range []map[string][]Resource{s.Resources, s.Logging} {
+		for _, rs := range m {
+			for i, r := range rs {
+				rs[i] = Resource(normalise(r).(map[string]interface{}))
+			}
+		}
+	}
+	return s, nil
+}
+
+// Read decodes the spec file at the given path, with the format determined by
+// the file extension.
+func Read(path string) (*Spec, error) {
+	// G304 (CWE-22): Potential file inclusion via variable.
+	// Disabling as we need to read the spec file provided by the user.
+	/* #nosec */
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Unmarshal(bs, FormatFromPath(path))
+}
+
+// FormatFromPath returns the spec format implied by the file extension,
+// defaulting to TOML.
+func FormatFromPath(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return FormatJSON
+	}
+	return FormatTOML
+}
+
+// NewResource converts an API response type (e.g. *fastly.Backend) into a
+// Resource, omitting any API metadata fields.
+func NewResource(v interface{}) (Resource, error) {
+	m := make(map[string]interface{})
+	if err := mapstructure.Decode(v, &m); err != nil {
+		return nil, err
+	}
+	for _, key := range metadataKeys {
+		delete(m, key)
+	}
+	for key, v := range m {
+		if v == nil || reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil() {
+			delete(m, key)
+		}
+	}
+	return Resource(normalise(m).(map[string]interface{})), nil
+}
+
+// normalise converts a decoded value into one of a small set of types: string,
+// bool, int64, float64 (only for non-integral numbers), []interface{} and
+// map[string]interface{}.
+func normalise(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if f == float64(int64(f)) {
+			return int64(f)
+		}
+		return f
+	case reflect.Slice, reflect.Array:
+		s := make([]interface{}, rv.Len())
+		for i := range s {
+			s[i] = normalise(rv.Index(i).Interface())
+		}
+		return s
+	case reflect.Map:
+		m := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = normalise(iter.Value().Interface())
+		}
+		return m
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+		return normalise(rv.Elem().Interface())
+	}
+	return v
+}
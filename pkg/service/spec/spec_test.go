@@ -0,0 +1,324 @@
+package spec_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fastly/cli/pkg/mock"
+	"github.com/fastly/cli/pkg/service/spec"
+	"github.com/fastly/cli/pkg/testutil"
+	"github.com/fastly/go-fastly/v3/fastly"
+)
+
+func TestNewResource(t *testing.T) {
+	r, err := spec.NewResource(&fastly.Backend{
+		ServiceID:      "123",
+		ServiceVersion: 1,
+		Name:           "origin",
+		Address:        "127.0.0.1",
+		Port:           443,
+		UseSSL:         true,
+		CreatedAt:      testutil.MustParseTimeRFC3339("2000-01-01T01:00:00Z"),
+	})
+	testutil.AssertNoError(t, err)
+	testutil.AssertString(t, "origin", r.Name())
+	testutil.AssertEqual(t, int64(443), r["port"])
+	testutil.AssertEqual(t, true, r["use_ssl"])
+	for _, key := range []string{"service_id", "version", "created_at"} {
+		if _, ok := r[key]; ok {
+			t.Errorf("unexpected metadata field %q", key)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	backend, _ := spec.KindByName("backend")
+	syslog, _ := spec.KindByName("syslog")
+
+	for _, format := range []string{spec.FormatTOML, spec.FormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			s := spec.New()
+			s.Service = spec.Service{ID: "123", Name: "Foo", Type: "vcl", Version: 1}
+			s.Set(backend, []spec.Resource{
+				{"name": "b", "address": "127.0.0.2", "port": int64(80), "weight": 0.5},
+				{"name": "a", "address": "127.0.0.1", "port": int64(443)},
+			})
+			s.Set(syslog, []spec.Resource{
+				{"name": "logs", "token": "s3cr3t"},
+			})
+
+			bs, err := s.Marshal(format)
+			testutil.AssertNoError(t, err)
+			have, err := spec.Unmarshal(bs, format)
+			testutil.AssertNoError(t, err)
+			testutil.AssertEqual(t, s, have)
+			testutil.AssertString(t, "a", have.Get(backend)[0].Name())
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	syslog, _ := spec.KindByName("syslog")
+	s := spec.New()
+	s.Set(syslog, []spec.Resource{
+		{"name": "logs", "token": "s3cr3t", "address": "example.com"},
+		{"name": "other", "token": ""},
+	})
+	s.Redact()
+	testutil.AssertEqual(t, spec.Redacted, s.Get(syslog)[0]["token"])
+	testutil.AssertEqual(t, "example.com", s.Get(syslog)[0]["address"])
+	testutil.AssertEqual(t, "", s.Get(syslog)[1]["token"])
+}
+
+func TestUnmarshalSpecVersion(t *testing.T) {
+	_, err := spec.Unmarshal([]byte("spec_version = 2\n"), spec.FormatTOML)
+	testutil.AssertErrorContains(t, err, "unrecognised spec_version: 2")
+}
+
+func TestKinds(t *testing.T) {
+	for _, k := range spec.Kinds {
+		for name, fn := range map[string]interface{}{"List": k.List, "Create": k.Create, "Update": k.Update, "Delete": k.Delete} {
+			ft := reflect.TypeOf(fn)
+			if ft == nil || ft.Kind() != reflect.Func || ft.NumIn() != 2 {
+				t.Errorf("%s: %s is not an api.Interface method expression", k.Name, name)
+				continue
+			}
+			input := ft.In(1).Elem()
+			fields := []string{"ServiceID", "ServiceVersion"}
+			if name != "List" && name != "Create" {
+				fields = append(fields, "Name")
+			}
+			for _, f := range fields {
+				if _, ok := input.FieldByName(f); !ok {
+					t.Errorf("%s: %s input %s has no %s field", k.Name, name, input.Name(), f)
+				}
+			}
+			if ft.Out(ft.NumOut()-1) != reflect.TypeOf((*error)(nil)).Elem() {
+				t.Errorf("%s: %s doesn't return an error", k.Name, name)
+			}
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	healthcheck, _ := spec.KindByName("healthcheck")
+	backend, _ := spec.KindByName("backend")
+
+	current := spec.New()
+	current.Set(healthcheck, []spec.Resource{{"name": "old", "path": "/"}})
+	current.Set(backend, []spec.Resource{
+		{"name": "a", "address": "127.0.0.1", "port": int64(443), "healthcheck": "old"},
+		{"name": "b", "address": "127.0.0.2", "ssl_client_key": "s3cr3t"},
+	})
+
+	desired := spec.New()
+	desired.Set(healthcheck, []spec.Resource{{"name": "new", "path": "/status"}})
+	desired.Set(backend, []spec.Resource{
+		{"name": "a", "address": "127.0.0.1", "port": int64(80), "healthcheck": "new"},
+		{"name": "b", "address": "127.0.0.2", "ssl_client_key": spec.Redacted},
+	})
+
+	var have []string
+	for _, op := range spec.Plan(current, desired) {
+		have = append(have, op.String())
+	}
+	want := []string{
+		`+ healthcheck "new"`,
+		`~ backend "a" (healthcheck, port)`,
+		`- healthcheck "old"`,
+	}
+	testutil.AssertEqual(t, want, have)
+}
+
+func TestPlanDictionaryItems(t *testing.T) {
+	dictionary, _ := spec.KindByName("dictionary")
+
+	current := spec.New()
+	current.Set(dictionary, []spec.Resource{
+		{"name": "same", spec.ItemsKey: map[string]interface{}{"a": "1"}},
+		{"name": "changed", spec.ItemsKey: map[string]interface{}{"a": "1", "b": "2", "c": "3"}},
+		{"name": "writeonly", "write_only": true},
+	})
+
+	desired := spec.New()
+	desired.Set(dictionary, []spec.Resource{
+		{"name": "same", spec.ItemsKey: map[string]interface{}{"a": "1"}},
+		{"name": "changed", spec.ItemsKey: map[string]interface{}{"a": "1", "b": "two", "d": "4"}},
+		{"name": "writeonly", "write_only": true, spec.ItemsKey: map[string]interface{}{"a": "1"}},
+		{"name": "new", spec.ItemsKey: map[string]interface{}{"x": "1"}},
+	})
+
+	ops := spec.Plan(current, desired)
+	var have []string
+	for _, op := range ops {
+		have = append(have, op.String())
+	}
+	testutil.AssertEqual(t, []string{
+		`~ dictionary "changed" items (1 created, 1 updated, 1 deleted)`,
+		`+ dictionary "new"`,
+		`~ dictionary "new" items (1 created, 0 updated, 0 deleted)`,
+	}, have)
+
+	// The dictionary is created without its items, which are created next.
+	if _, ok := ops[1].Desired[spec.ItemsKey]; ok {
+		t.Error("want dictionary created without items")
+	}
+
+	// Only the items of the existing dictionary change the live service.
+	var live []bool
+	for _, op := range ops {
+		live = append(live, op.Live())
+	}
+	testutil.AssertEqual(t, []bool{true, false, false}, live)
+
+	testutil.AssertEqual(t, []*fastly.BatchDictionaryItem{
+		{Operation: fastly.UpdateBatchOperation, ItemKey: "b", ItemValue: "two"},
+		{Operation: fastly.DeleteBatchOperation, ItemKey: "c"},
+		{Operation: fastly.CreateBatchOperation, ItemKey: "d", ItemValue: "4"},
+	}, spec.ItemOperations(ops[0].Current, ops[0].Desired))
+
+	// Undoing the changes restores the items.
+	inverse := ops[0].Inverse()
+	testutil.AssertEqual(t, spec.ActionItems, inverse.Action)
+	testutil.AssertEqual(t, []*fastly.BatchDictionaryItem{
+		{Operation: fastly.UpdateBatchOperation, ItemKey: "b", ItemValue: "2"},
+		{Operation: fastly.CreateBatchOperation, ItemKey: "c", ItemValue: "3"},
+		{Operation: fastly.DeleteBatchOperation, ItemKey: "d"},
+	}, spec.ItemOperations(inverse.Current, inverse.Desired))
+}
+
+func TestOperationInverse(t *testing.T) {
+	backend, _ := spec.KindByName("backend")
+	current := spec.Resource{"name": "a", "port": int64(443)}
+	desired := spec.Resource{"name": "a", "port": int64(80)}
+
+	op := spec.Operation{Action: spec.ActionUpdate, Kind: backend, Name: "a", Current: current, Desired: desired}
+	inverse := op.Inverse()
+	testutil.AssertEqual(t, spec.ActionUpdate, inverse.Action)
+	testutil.AssertEqual(t, current, inverse.Desired)
+
+	op = spec.Operation{Action: spec.ActionCreate, Kind: backend, Name: "a", Desired: desired}
+	testutil.AssertEqual(t, spec.ActionDelete, op.Inverse().Action)
+	testutil.AssertEqual(t, spec.ActionCreate, op.Inverse().Inverse().Action)
+}
+
+func TestOperationApply(t *testing.T) {
+	backend, _ := spec.KindByName("backend")
+
+	var input *fastly.CreateBackendInput
+	api := mock.API{
+		CreateBackendFn: func(i *fastly.CreateBackendInput) (*fastly.Backend, error) {
+			input = i
+			return &fastly.Backend{}, nil
+		},
+		UpdateBackendFn: func(i *fastly.UpdateBackendInput) (*fastly.Backend, error) {
+			return nil, testutil.Err
+		},
+	}
+
+	op := spec.Operation{Action: spec.ActionCreate, Kind: backend, Name: "a", Desired: spec.Resource{
+		"name":           "a",
+		"address":        "127.0.0.1",
+		"port":           int64(443),
+		"use_ssl":        true,
+		"ssl_client_key": spec.Redacted,
+	}}
+	err := op.Apply(api, "123", 2)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, &fastly.CreateBackendInput{
+		ServiceID:      "123",
+		ServiceVersion: 2,
+		Name:           "a",
+		Address:        "127.0.0.1",
+		Port:           443,
+		UseSSL:         true,
+	}, input)
+
+	op.Action = spec.ActionUpdate
+	err = op.Apply(api, "123", 2)
+	testutil.AssertErrorContains(t, err, "error updating backend a: test error")
+}
+
+func TestOperationApplyItems(t *testing.T) {
+	dictionary, _ := spec.KindByName("dictionary")
+
+	var input *fastly.BatchModifyDictionaryItemsInput
+	api := mock.API{
+		GetDictionaryFn: func(i *fastly.GetDictionaryInput) (*fastly.Dictionary, error) {
+			return &fastly.Dictionary{ID: "456", Name: i.Name}, nil
+		},
+		BatchModifyDictionaryItemsFn: func(i *fastly.BatchModifyDictionaryItemsInput) error {
+			input = i
+			return nil
+		},
+	}
+
+	op := spec.Operation{
+		Action:  spec.ActionItems,
+		Kind:    dictionary,
+		Name:    "d",
+		Current: spec.Resource{"name": "d", spec.ItemsKey: map[string]interface{}{"a": "1"}},
+		Desired: spec.Resource{"name": "d", spec.ItemsKey: map[string]interface{}{"a": "2"}},
+	}
+	err := op.Apply(api, "123", 2)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, &fastly.BatchModifyDictionaryItemsInput{
+		ServiceID:    "123",
+		DictionaryID: "456",
+		Items: []*fastly.BatchDictionaryItem{
+			{Operation: fastly.UpdateBatchOperation, ItemKey: "a", ItemValue: "2"},
+		},
+	}, input)
+
+	api.BatchModifyDictionaryItemsFn = func(*fastly.BatchModifyDictionaryItemsInput) error {
+		return testutil.Err
+	}
+	err = op.Apply(api, "123", 2)
+	testutil.AssertErrorContains(t, err, "error modifying items for dictionary d: test error")
+}
+
+func TestDiff(t *testing.T) {
+	backend, _ := spec.KindByName("backend")
+	a := spec.New()
+	a.Set(backend, []spec.Resource{
+		{"name": "a", "port": int64(443)},
+		{"name": "b", "port": int64(80)},
+	})
+	b := spec.New()
+	b.Set(backend, []spec.Resource{
+		{"name": "a", "port": int64(8443), "comment": "tls"},
+		{"name": "b", "port": int64(80)},
+		{"name": "c", "port": int64(80)},
+	})
+
+	have := spec.Diff(a, b)
+	testutil.AssertEqual(t, []spec.Difference{
+		{Kind: "backend", Name: "a", Action: spec.ActionUpdate, Fields: []spec.FieldChange{
+			{Field: "comment", To: "tls"},
+			{Field: "port", From: int64(443), To: int64(8443)},
+		}},
+		{Kind: "backend", Name: "c", Action: spec.ActionCreate, Fields: []spec.FieldChange{
+			{Field: "name", To: "c"},
+			{Field: "port", To: int64(80)},
+		}},
+	}, have)
+}
+
+func TestLineDiff(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	b := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n"
+	testutil.AssertEqual(t, []string{
+		" 1",
+		" 2",
+		"-3",
+		"+three",
+		" 4",
+		" 5",
+		" 6",
+		"@@",
+		" 8",
+		" 9",
+		" 10",
+		"+11",
+	}, spec.LineDiff(a, b, 3))
+}
@@ -1,7 +1,9 @@
 package serviceversion
 
 import (
+	"fmt"
 	"io"
+	"time"
 
 	"github.com/fastly/cli/pkg/cmd"
 	"github.com/fastly/cli/pkg/compute/manifest"
@@ -18,6 +20,11 @@ type ActivateCommand struct {
 	Input          fastly.ActivateVersionInput
 	serviceVersion cmd.OptionalServiceVersion
 	autoClone      cmd.OptionalAutoClone
+
+	canary     bool
+	baseline   time.Duration
+	window     time.Duration
+	thresholds canaryThresholds
 }
 
 // NewActivateCommand returns a usable command registered under the parent.
@@ -35,6 +42,12 @@ func NewActivateCommand(parent cmd.Registerer, globals *config.Data) *ActivateCo
 		Action: c.autoClone.Set,
 		Dst:    &c.autoClone.Value,
 	})
+	c.CmdClause.Flag("canary", "Watch the realtime stats after activating and re-activate the previous version if they regress").BoolVar(&c.canary)
+	c.CmdClause.Flag("canary-baseline", "How long to measure the previous version's realtime stats for before activating").Default("1m").DurationVar(&c.baseline)
+	c.CmdClause.Flag("canary-window", "How long to watch the realtime stats for after activating").Default("5m").DurationVar(&c.window)
+	c.CmdClause.Flag("canary-error-threshold", "Maximum increase in the ratio of 5xx responses over the baseline (e.g. 0.01 is one percentage point)").Default("0.01").Float64Var(&c.thresholds.errorIncrease)
+	c.CmdClause.Flag("canary-latency-threshold", "Maximum multiple of the baseline's average miss latency").Default("1.5").Float64Var(&c.thresholds.latencyRatio)
+	c.CmdClause.Flag("canary-min-requests", "Minimum number of requests the new version must serve before its stats are judged").Default("100").Uint64Var(&c.thresholds.minRequests)
 	return &c
 }
 
@@ -59,6 +72,10 @@ func (c *ActivateCommand) Exec(in io.Reader, out io.Writer) error {
 	c.Input.ServiceID = serviceID
 	c.Input.ServiceVersion = serviceVersion.Number
 
+	if c.canary {
+		return c.activateCanary(serviceID, serviceVersion, out)
+	}
+
 	ver, err := c.Globals.Client.ActivateVersion(&c.Input)
 	if err != nil {
 		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
@@ -71,3 +88,91 @@ func (c *ActivateCommand) Exec(in io.Reader, out io.Writer) error {
 	text.Success(out, "Activated service %s version %d", ver.ServiceID, c.Input.ServiceVersion)
 	return nil
 }
+
+// activateCanary activates the service version while watching the realtime
+// stats of the service, re-activating the previously active version if the
+// stats regress from their baseline.
+func (c *ActivateCommand) activateCanary(serviceID string, serviceVersion *fastly.Version, out io.Writer) error {
+	vs, err := c.Globals.Client.ListVersions(&fastly.ListVersionsInput{
+		ServiceID: serviceID,
+	})
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID": serviceID,
+		})
+		return fmt.Errorf("error listing service versions: %w", err)
+	}
+	previous, err := cmd.GetActiveVersion(vs)
+	if err != nil {
+		return errors.RemediationError{
+			Inner:       fmt.Errorf("a canary activation requires an active version to roll back to"),
+			Remediation: "Activate the version without the --canary flag.",
+		}
+	}
+
+	w := canaryWatcher{
+		client:     c.Globals.RTSClient,
+		serviceID:  serviceID,
+		retryDelay: canaryRetryDelay,
+	}
+
+	text.Info(out, "Measuring the baseline of version %d for %s...", previous.Number, c.baseline)
+	baseline, err := w.watch(seconds(c.baseline), nil)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID": serviceID,
+		})
+		return err
+	}
+	canaryTimeline(out, 0, "Baseline (version %d): %s", previous.Number, baseline)
+
+	if _, err = c.Globals.Client.ActivateVersion(&c.Input); err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID":      serviceID,
+			"Service Version": serviceVersion.Number,
+		})
+		return err
+	}
+	canaryTimeline(out, 0, "Activated version %d, watching for %s", serviceVersion.Number, c.window)
+
+	var (
+		regression string
+		elapsed    int
+	)
+	canary, err := w.watch(seconds(c.window), func(n int, s canarySample) bool {
+		elapsed = n
+		canaryTimeline(out, n, "Canary (version %d): %s", serviceVersion.Number, s)
+		regression = c.thresholds.check(baseline, s)
+		return regression == ""
+	})
+	if err != nil {
+		regression = err.Error()
+	}
+	if regression == "" {
+		text.Success(out, "Activated service %s version %d (canary: %s)", serviceID, serviceVersion.Number, canary)
+		return nil
+	}
+
+	canaryTimeline(out, elapsed, "Rolling back to version %d: %s", previous.Number, regression)
+	_, err = c.Globals.Client.ActivateVersion(&fastly.ActivateVersionInput{
+		ServiceID:      serviceID,
+		ServiceVersion: previous.Number,
+	})
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID":      serviceID,
+			"Service Version": previous.Number,
+		})
+		return fmt.Errorf("error rolling back to version %d: %w", previous.Number, err)
+	}
+
+	return fmt.Errorf("canary activation of service %s version %d failed (%s), version %d was re-activated", serviceID, serviceVersion.Number, regression, previous.Number)
+}
+
+// seconds returns the number of whole seconds in d, which is at least one.
+func seconds(d time.Duration) int {
+	if n := int(d / time.Second); n > 0 {
+		return n
+	}
+	return 1
+}
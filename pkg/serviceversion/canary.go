@@ -0,0 +1,147 @@
+package serviceversion
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fastly/cli/pkg/api"
+	"github.com/fastly/cli/pkg/text"
+	"github.com/fastly/go-fastly/v3/fastly"
+)
+
+// canaryMaxFetchErrors is the number of consecutive failed realtime stats
+// requests tolerated before a canary is abandoned.
+const canaryMaxFetchErrors = 3
+
+// canaryRetryDelay is the delay before the first retry of a failed realtime
+// stats request, which doubles for each consecutive failure.
+const canaryRetryDelay = 500 * time.Millisecond
+
+// canaryResponse is the subset of a realtime stats response used to judge a
+// canary activation. Each item of Data covers one second.
+type canaryResponse struct {
+	Timestamp uint64 `json:"timestamp"`
+	Data      []struct {
+		Aggregated canarySample `json:"aggregated"`
+	} `json:"data"`
+}
+
+// canarySample accumulates the realtime stats of a service.
+type canarySample struct {
+	Requests  uint64  `json:"requests"`
+	Status5xx uint64  `json:"status_5xx"`
+	Miss      uint64  `json:"miss"`
+	MissTime  float64 `json:"miss_time"`
+}
+
+func (s *canarySample) add(o canarySample) {
+	s.Requests += o.Requests
+	s.Status5xx += o.Status5xx
+	s.Miss += o.Miss
+	s.MissTime += o.MissTime
+}
+
+// errorRatio is the proportion of requests which resulted in a 5xx response.
+func (s canarySample) errorRatio() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Status5xx) / float64(s.Requests)
+}
+
+// missLatency is the average time taken to fetch a cache miss from origin.
+func (s canarySample) missLatency() time.Duration {
+	if s.Miss == 0 {
+		return 0
+	}
+	return time.Duration(s.MissTime / float64(s.Miss) * float64(time.Second))
+}
+
+// String formats the sample for the canary timeline.
+func (s canarySample) String() string {
+	return fmt.Sprintf("%d requests, %.2f%% 5xx, %s miss latency", s.Requests, s.errorRatio()*100, s.missLatency().Round(time.Millisecond))
+}
+
+// canaryThresholds are the limits a canary's stats may reach, relative to the
+// baseline, before the activation is rolled back.
+type canaryThresholds struct {
+	// errorIncrease is the maximum increase in the 5xx ratio (e.g. 0.01 is one
+	// percentage point).
+	errorIncrease float64
+	// latencyRatio is the maximum multiple of the baseline miss latency.
+	latencyRatio float64
+	// minRequests is the number of requests the canary must serve before its
+	// stats are judged, so a few early errors don't roll back a low traffic
+	// service.
+	minRequests uint64
+}
+
+// check returns a description of the first threshold the canary exceeds, or
+// an empty string if it's within all of them or hasn't served enough requests
+// to tell.
+func (t canaryThresholds) check(baseline, canary canarySample) string {
+	if canary.Requests < t.minRequests {
+		return ""
+	}
+	if canary.errorRatio()-baseline.errorRatio() > t.errorIncrease {
+		return fmt.Sprintf("5xx ratio %.2f%% exceeds the baseline %.2f%% by more than %.2f percentage points", canary.errorRatio()*100, baseline.errorRatio()*100, t.errorIncrease*100)
+	}
+	if baseline.Miss > 0 && canary.Miss > 0 && float64(canary.missLatency()) > float64(baseline.missLatency())*t.latencyRatio {
+		return fmt.Sprintf("miss latency %s exceeds %.2fx the baseline %s", canary.missLatency().Round(time.Millisecond), t.latencyRatio, baseline.missLatency().Round(time.Millisecond))
+	}
+	return ""
+}
+
+// canaryWatcher polls the realtime stats of a service.
+type canaryWatcher struct {
+	client     api.RealtimeStatsInterface
+	serviceID  string
+	timestamp  uint64
+	retryDelay time.Duration
+}
+
+// watch polls the realtime stats until the given number of seconds of data
+// have been collected. The sample so far is passed to fn after each poll and
+// polling stops early if fn returns false.
+func (w *canaryWatcher) watch(seconds int, fn func(elapsed int, s canarySample) bool) (canarySample, error) {
+	var (
+		sample  canarySample
+		elapsed int
+		fails   int
+	)
+	for elapsed < seconds {
+		var resp canaryResponse
+		err := w.client.GetRealtimeStatsJSON(&fastly.GetRealtimeStatsInput{
+			ServiceID: w.serviceID,
+			Timestamp: w.timestamp,
+		}, &resp)
+		if err != nil {
+			fails++
+			if fails >= canaryMaxFetchErrors {
+				return sample, fmt.Errorf("error fetching realtime stats: %w", err)
+			}
+			time.Sleep(w.retryDelay << (fails - 1))
+			continue
+		}
+		fails = 0
+		w.timestamp = resp.Timestamp
+
+		for _, d := range resp.Data {
+			if elapsed == seconds {
+				break
+			}
+			sample.add(d.Aggregated)
+			elapsed++
+		}
+		if fn != nil && !fn(elapsed, sample) {
+			break
+		}
+	}
+	return sample, nil
+}
+
+// canaryTimeline writes an entry to the canary timeline.
+func canaryTimeline(out io.Writer, elapsed int, format string, args ...interface{}) {
+	text.Output(out, "[%4ds] %s", elapsed, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,58 @@
+package serviceversion
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fastly/cli/pkg/mock"
+	"github.com/fastly/go-fastly/v3/fastly"
+)
+
+func TestCanaryWatcherRetry(t *testing.T) {
+	var (
+		calls []time.Time
+		fails = 2
+	)
+	w := canaryWatcher{
+		client: mock.RealtimeStatsAPI{
+			GetRealtimeStatsJSONFn: func(i *fastly.GetRealtimeStatsInput, dst interface{}) error {
+				calls = append(calls, time.Now())
+				if len(calls) <= fails {
+					return errors.New("test error")
+				}
+				return json.Unmarshal([]byte(`{"timestamp": 2, "data": [{"aggregated": {"requests": 1}}]}`), dst)
+			},
+		},
+		retryDelay: 20 * time.Millisecond,
+	}
+
+	s, err := w.watch(1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Requests != 1 || len(calls) != 3 {
+		t.Fatalf("want 1 request from 3 calls, have %d requests from %d calls", s.Requests, len(calls))
+	}
+
+	// The delay doubles after each consecutive failure.
+	for i, want := range []time.Duration{20 * time.Millisecond, 40 * time.Millisecond} {
+		if d := calls[i+1].Sub(calls[i]); d < want {
+			t.Errorf("want retry %d after at least %s, have %s", i+1, want, d)
+		}
+	}
+}
+
+func TestCanaryThresholdsMinRequests(t *testing.T) {
+	thresholds := canaryThresholds{errorIncrease: 0.01, latencyRatio: 1.5, minRequests: 10}
+	baseline := canarySample{Requests: 1000}
+
+	if have := thresholds.check(baseline, canarySample{Requests: 9, Status5xx: 9}); have != "" {
+		t.Errorf("want too few requests to be judged, have %q", have)
+	}
+	if have := thresholds.check(baseline, canarySample{Requests: 10, Status5xx: 1}); !strings.Contains(have, "5xx ratio 10.00%") {
+		t.Errorf("want 5xx ratio regression, have %q", have)
+	}
+}
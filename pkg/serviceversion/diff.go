@@ -0,0 +1,128 @@
+package serviceversion
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/fastly/cli/pkg/cmd"
+	"github.com/fastly/cli/pkg/compute/manifest"
+	"github.com/fastly/cli/pkg/config"
+	"github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/service/spec"
+	"github.com/fastly/cli/pkg/text"
+)
+
+// DiffCommand calls the Fastly API to compare the resources of two service
+// versions.
+type DiffCommand struct {
+	cmd.Base
+	manifest manifest.Data
+	from     cmd.OptionalServiceVersion
+	to       cmd.OptionalServiceVersion
+}
+
+// diffOutput is the machine readable representation of a diff.
+type diffOutput struct {
+	ServiceID   string            `json:"service_id"`
+	FromVersion int               `json:"from_version"`
+	ToVersion   int               `json:"to_version"`
+	Changes     []spec.Difference `json:"changes"`
+}
+
+// NewDiffCommand returns a usable command registered under the parent.
+func NewDiffCommand(parent cmd.Registerer, globals *config.Data) *DiffCommand {
+	var c DiffCommand
+	c.Globals = globals
+	c.manifest.File.SetOutput(c.Globals.Output)
+	c.manifest.File.Read(manifest.Filename)
+	c.CmdClause = parent.Command("diff", "Show the resource changes between two Fastly service versions")
+	c.RegisterServiceIDFlag(&c.manifest.Flag.ServiceID)
+	c.CmdClause.Flag("from", "'latest', 'active', or the number of the version to compare from").Required().StringVar(&c.from.Value)
+	c.CmdClause.Flag("to", "'latest', 'active', or the number of the version to compare to").Required().StringVar(&c.to.Value)
+	return &c
+}
+
+// Exec invokes the application logic for the command.
+func (c *DiffCommand) Exec(in io.Reader, out io.Writer) error {
+	serviceID, source := c.manifest.ServiceID()
+	if source == manifest.SourceUndefined {
+		return errors.ErrNoServiceID
+	}
+
+	from, err := c.from.Parse(serviceID, c.Globals.Client)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID": serviceID,
+			"From":       c.from.Value,
+		})
+		return err
+	}
+	to, err := c.to.Parse(serviceID, c.Globals.Client)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID": serviceID,
+			"To":         c.to.Value,
+		})
+		return err
+	}
+
+	// Dictionary items aren't versioned, so they're never compared.
+	a, err := spec.ExportVersioned(c.Globals.Client, serviceID, from.Number)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID":      serviceID,
+			"Service Version": from.Number,
+		})
+		return err
+	}
+	b, err := spec.ExportVersioned(c.Globals.Client, serviceID, to.Number)
+	if err != nil {
+		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+			"Service ID":      serviceID,
+			"Service Version": to.Number,
+		})
+		return err
+	}
+
+	// Secrets are compared but never displayed.
+	changes := spec.Diff(a, b)
+	for _, d := range changes {
+		d.Redact()
+	}
+
+	if format := c.Globals.Flag.Format; text.IsMachineFormat(format) {
+		if changes == nil {
+			changes = []spec.Difference{}
+		}
+		output := diffOutput{
+			ServiceID:   serviceID,
+			FromVersion: from.Number,
+			ToVersion:   to.Number,
+			Changes:     changes,
+		}
+		// The JSON output keeps each change's kind and name first, where
+		// Render would sort them after its fields.
+		if format == text.FormatJSON {
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			return enc.Encode(output)
+		}
+		return text.Render(out, format, output)
+	}
+
+	if len(changes) == 0 {
+		text.Info(out, "No changes between service %s versions %d and %d", serviceID, from.Number, to.Number)
+		return nil
+	}
+
+	fmt.Fprintf(out, "--- service %s version %d\n", serviceID, from.Number)
+	fmt.Fprintf(out, "+++ service %s version %d\n", serviceID, to.Number)
+	for _, d := range changes {
+		fmt.Fprintln(out)
+		for _, l := range d.Format() {
+			fmt.Fprintln(out, l)
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package serviceversion_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -201,6 +202,97 @@ func TestVersionActivate(t *testing.T) {
 	}
 }
 
+func TestVersionActivateCanary(t *testing.T) {
+	args := testutil.Args
+	healthy := `{"timestamp": 1, "data": [{"aggregated": {"requests": 100, "status_5xx": 1, "miss": 10, "miss_time": 0.5}}]}`
+	failing := `{"timestamp": 1, "data": [{"aggregated": {"requests": 100, "status_5xx": 20, "miss": 10, "miss_time": 0.5}}]}`
+	slow := `{"timestamp": 1, "data": [{"aggregated": {"requests": 100, "status_5xx": 1, "miss": 10, "miss_time": 5}}]}`
+	quiet := `{"timestamp": 1, "data": [{"aggregated": {"requests": 5, "status_5xx": 1}}]}`
+	for _, testcase := range []struct {
+		name            string
+		args            []string
+		stats           []string
+		wantError       string
+		wantOutput      []string
+		wantActivations []int
+	}{
+		{
+			name:            "healthy",
+			args:            args("service-version activate --service-id 123 --version 3 --canary --canary-baseline 2s --canary-window 2s"),
+			stats:           []string{healthy, healthy, healthy, healthy},
+			wantOutput:      []string{"Baseline (version 1): 200 requests, 1.00% 5xx, 50ms miss latency", "[   2s] Canary (version 3)", "Activated service 123 version 3"},
+			wantActivations: []int{3},
+		},
+		{
+			name:            "error regression",
+			args:            args("service-version activate --service-id 123 --version 3 --canary --canary-baseline 2s --canary-window 5s"),
+			stats:           []string{healthy, healthy, failing},
+			wantError:       "canary activation of service 123 version 3 failed (5xx ratio 20.00% exceeds the baseline 1.00% by more than 1.00 percentage points), version 1 was re-activated",
+			wantOutput:      []string{"[   1s] Rolling back to version 1"},
+			wantActivations: []int{3, 1},
+		},
+		{
+			name:            "latency regression",
+			args:            args("service-version activate --service-id 123 --version 3 --canary --canary-baseline 1s --canary-window 5s"),
+			stats:           []string{healthy, slow},
+			wantError:       "miss latency 500ms exceeds 1.50x the baseline 50ms",
+			wantActivations: []int{3, 1},
+		},
+		{
+			name:            "low traffic isn't judged",
+			args:            args("service-version activate --service-id 123 --version 3 --canary --canary-baseline 1s --canary-window 2s"),
+			stats:           []string{healthy, quiet, quiet},
+			wantOutput:      []string{"[   2s] Canary (version 3): 10 requests, 20.00% 5xx", "Activated service 123 version 3"},
+			wantActivations: []int{3},
+		},
+		{
+			name:            "min requests",
+			args:            args("service-version activate --service-id 123 --version 3 --canary --canary-baseline 1s --canary-window 2s --canary-min-requests 10"),
+			stats:           []string{healthy, quiet, quiet},
+			wantError:       "5xx ratio 20.00% exceeds the baseline 1.00%",
+			wantOutput:      []string{"[   2s] Rolling back to version 1"},
+			wantActivations: []int{3, 1},
+		},
+		{
+			name:      "stats error",
+			args:      args("service-version activate --service-id 123 --version 3 --canary"),
+			wantError: "error fetching realtime stats: test error",
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			var activations []int
+			api := mock.API{
+				ListVersionsFn: testutil.ListVersions,
+				ActivateVersionFn: func(i *fastly.ActivateVersionInput) (*fastly.Version, error) {
+					activations = append(activations, i.ServiceVersion)
+					return activateVersionOK(i)
+				},
+			}
+			rts := mock.RealtimeStatsAPI{
+				GetRealtimeStatsJSONFn: func(i *fastly.GetRealtimeStatsInput, dst interface{}) error {
+					if len(testcase.stats) == 0 {
+						return testutil.Err
+					}
+					resp := testcase.stats[0]
+					testcase.stats = testcase.stats[1:]
+					return json.Unmarshal([]byte(resp), dst)
+				},
+			}
+
+			var stdout bytes.Buffer
+			opts := testutil.NewRunOpts(testcase.args, &stdout)
+			opts.APIClient = mock.APIClient(api)
+			opts.RTSClient = mock.RTSClient(rts)
+			err := app.Run(opts)
+			testutil.AssertErrorContains(t, err, testcase.wantError)
+			for _, s := range testcase.wantOutput {
+				testutil.AssertStringContains(t, stdout.String(), s)
+			}
+			testutil.AssertEqual(t, testcase.wantActivations, activations)
+		})
+	}
+}
+
 func TestVersionDeactivate(t *testing.T) {
 	args := testutil.Args
 	for _, testcase := range []struct {
@@ -329,6 +421,114 @@ Versions: 3
 		Last edited (UTC): 2000-01-03 01:00
 `) + "\n\n"
 
+func TestVersionDiff(t *testing.T) {
+	args := testutil.Args
+	api := testutil.ListEmpty(mock.API{
+		ListVersionsFn: testutil.ListVersions,
+		GetServiceFn: func(i *fastly.GetServiceInput) (*fastly.Service, error) {
+			return &fastly.Service{ID: i.ID}, nil
+		},
+		ListBackendsFn: func(i *fastly.ListBackendsInput) ([]*fastly.Backend, error) {
+			address := map[int]string{1: "127.0.0.1", 3: "127.0.0.2"}[i.ServiceVersion]
+			return []*fastly.Backend{{Name: "origin", Address: address, SSLClientKey: address}}, nil
+		},
+		ListVCLsFn: func(i *fastly.ListVCLsInput) ([]*fastly.VCL, error) {
+			if i.ServiceVersion == 1 {
+				return nil, nil
+			}
+			return []*fastly.VCL{{Name: "main", Content: "sub vcl_recv {\n}\n"}}, nil
+		},
+		ListDictionariesFn: func(i *fastly.ListDictionariesInput) ([]*fastly.Dictionary, error) {
+			return []*fastly.Dictionary{{ID: "d1", Name: "geo"}}, nil
+		},
+		// Dictionary items aren't versioned, so they're never fetched.
+		ListDictionaryItemsFn: func(i *fastly.ListDictionaryItemsInput) ([]*fastly.DictionaryItem, error) {
+			return nil, testutil.Err
+		},
+	})
+
+	for _, testcase := range []struct {
+		args       []string
+		api        mock.API
+		wantError  string
+		wantOutput string
+	}{
+		{
+			args:      args("service-version diff --from 1 --to 3"),
+			wantError: "error reading service: no service ID found",
+		},
+		{
+			args:      args("service-version diff --service-id 123 --from 1"),
+			wantError: "error parsing arguments: required flag --to not provided",
+		},
+		{
+			args:      args("service-version diff --service-id 123 --from 1 --to 5"),
+			api:       api,
+			wantError: "specified service version not found: 5",
+		},
+		{
+			args:       args("service-version diff --service-id 123 --from active --to active"),
+			api:        api,
+			wantOutput: "No changes between service 123 versions 1 and 1",
+		},
+		{
+			args: args("service-version diff --service-id 123 --from active --to latest"),
+			api:  api,
+			wantOutput: `--- service 123 version 1
++++ service 123 version 3
+
+~ backend "origin"
+    - address = "127.0.0.1"
+    + address = "127.0.0.2"
+    - ssl_client_key = "<redacted>"
+    + ssl_client_key = "<redacted>"
+
++ vcl "main"
+    content:
+      +sub vcl_recv {
+      +}
+    + main = false
+    + name = "main"
+`,
+		},
+		{
+			args: args("service-version diff --service-id 123 --from 3 --to 1 --output json"),
+			api:  api,
+			wantOutput: `{
+      "kind": "vcl",
+      "name": "main",
+      "action": "delete",
+      "fields": [
+        {
+          "field": "content",
+          "from": "sub vcl_recv {\n}\n",
+          "to": null
+        },
+        {
+          "field": "main",
+          "from": false,
+          "to": null
+        },
+        {
+          "field": "name",
+          "from": "main",
+          "to": null
+        }
+      ]
+    }`,
+		},
+	} {
+		t.Run(strings.Join(testcase.args, " "), func(t *testing.T) {
+			var stdout bytes.Buffer
+			opts := testutil.NewRunOpts(testcase.args, &stdout)
+			opts.APIClient = mock.APIClient(testcase.api)
+			err := app.Run(opts)
+			testutil.AssertErrorContains(t, err, testcase.wantError)
+			testutil.AssertStringContains(t, stdout.String(), testcase.wantOutput)
+		})
+	}
+}
+
 func updateVersionOK(i *fastly.UpdateVersionInput) (*fastly.Version, error) {
 	return &fastly.Version{
 		Number:    i.ServiceVersion,
@@ -0,0 +1,83 @@
+package stats
+
+import (
+	"sort"
+)
+
+// aggregateBlocks sums the stats of each period across services, returning
+// the periods in order.
+func aggregateBlocks(services [][]statsResponseData) []statsResponseData {
+	periods := make(map[float64]statsResponseData)
+	for _, blocks := range services {
+		for _, block := range blocks {
+			start, _ := block["start_time"].(float64)
+			agg, ok := periods[start]
+			if !ok {
+				agg = statsResponseData{"start_time": start}
+				periods[start] = agg
+			}
+			addStats(agg, block)
+		}
+	}
+
+	starts := make([]float64, 0, len(periods))
+	for start := range periods {
+		starts = append(starts, start)
+	}
+	sort.Float64s(starts)
+
+	blocks := make([]statsResponseData, 0, len(starts))
+	for _, start := range starts {
+		blocks = append(blocks, periods[start])
+	}
+	return blocks
+}
+
+// totalBlocks sums the stats of every period.
+func totalBlocks(blocks []statsResponseData) statsResponseData {
+	total := make(statsResponseData)
+	for _, block := range blocks {
+		addStats(total, block)
+	}
+	return total
+}
+
+// addStats adds the numeric stats of src to dst. Ratios can't be summed, so
+// the hit ratio is recalculated from the summed hits and misses.
+func addStats(dst, src statsResponseData) {
+	for field, value := range src {
+		v, ok := value.(float64)
+		if !ok || field == "start_time" || field == "hit_ratio" {
+			continue
+		}
+		sum, _ := dst[field].(float64)
+		dst[field] = sum + v
+	}
+
+	hits, _ := dst["hits"].(float64)
+	miss, _ := dst["miss"].(float64)
+	if _, ok := src["hit_ratio"]; ok {
+		ratio := 0.0
+		if hits+miss > 0 {
+			ratio = hits / (hits + miss)
+		}
+		dst["hit_ratio"] = ratio
+	}
+}
+
+// numericFields returns the names of the numeric stats in blocks, in order.
+func numericFields(blocks []statsResponseData) []string {
+	seen := make(map[string]bool)
+	var fields []string
+	for _, block := range blocks {
+		for field, value := range block {
+			if _, ok := value.(float64); !ok || field == "start_time" || seen[field] {
+				continue
+			}
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -0,0 +1,315 @@
+package stats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// alert is a condition over realtime stats, such as
+//
+//	errors/requests > 0.02 for 30s
+//
+// The left hand side is an arithmetic expression of stats fields and numbers,
+// which is compared with a number every second. The optional duration is how
+// long the comparison must hold before the alert trips.
+type alert struct {
+	source    string
+	expr      alertExpr
+	op        string
+	threshold float64
+	duration  time.Duration
+
+	held   time.Duration // how long the comparison has held
+	firing bool
+}
+
+// observe evaluates the alert against a second of stats, returning the value
+// of its expression and whether the alert tripped during this second. An alert
+// which has tripped doesn't trip again until its comparison stops holding.
+func (a *alert) observe(s statsResponseData) (value float64, tripped bool) {
+	value = a.expr.eval(s)
+	if !compare(value, a.op, a.threshold) {
+		a.held = 0
+		a.firing = false
+		return value, false
+	}
+
+	a.held += time.Second
+	if a.firing || a.held < a.duration {
+		return value, false
+	}
+	a.firing = true
+	return value, true
+}
+
+// fields returns the stats fields used by the alert.
+func (a *alert) fields() []string {
+	var fields []string
+	a.expr.walk(func(e alertExpr) {
+		if f, ok := e.(alertField); ok {
+			fields = append(fields, string(f))
+		}
+	})
+	return fields
+}
+
+func compare(v float64, op string, threshold float64) bool {
+	switch op {
+	case ">":
+		return v > threshold
+	case ">=":
+		return v >= threshold
+	case "<":
+		return v < threshold
+	case "<=":
+		return v <= threshold
+	case "==":
+		return v == threshold
+	case "!=":
+		return v != threshold
+	}
+	return false
+}
+
+// alertExpr is a node of an alert's arithmetic expression.
+type alertExpr interface {
+	eval(s statsResponseData) float64
+	walk(fn func(alertExpr))
+}
+
+// alertNumber is a numeric literal.
+type alertNumber float64
+
+func (n alertNumber) eval(statsResponseData) float64 { return float64(n) }
+func (n alertNumber) walk(fn func(alertExpr))        { fn(n) }
+
+// alertField is a realtime stats field, which is zero if it's absent.
+type alertField string
+
+func (f alertField) eval(s statsResponseData) float64 { return field(s, string(f)) }
+func (f alertField) walk(fn func(alertExpr))          { fn(f) }
+
+// alertBinary is an arithmetic operation. Division by zero is zero, so ratios
+// of fields are zero when there's no traffic.
+type alertBinary struct {
+	op          byte
+	left, right alertExpr
+}
+
+func (b alertBinary) eval(s statsResponseData) float64 {
+	l, r := b.left.eval(s), b.right.eval(s)
+	switch b.op {
+	case '+':
+		return l + r
+	case '-':
+		return l - r
+	case '*':
+		return l * r
+	case '/':
+		if r == 0 {
+			return 0
+		}
+		return l / r
+	}
+	return 0
+}
+
+func (b alertBinary) walk(fn func(alertExpr)) {
+	fn(b)
+	b.left.walk(fn)
+	b.right.walk(fn)
+}
+
+// parseAlert parses an alert condition.
+func parseAlert(source string) (*alert, error) {
+	tokens, err := tokenizeAlert(source)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing alert %q: %w", source, err)
+	}
+
+	p := alertParser{tokens: tokens}
+	a, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing alert %q: %w", source, err)
+	}
+	a.source = source
+	return a, nil
+}
+
+// alertParser is a recursive descent parser of alert conditions:
+//
+//	alert  = expr op [ "-" ] number [ "for" duration ]
+//	expr   = term { ("+" | "-") term }
+//	term   = factor { ("*" | "/") factor }
+//	factor = number | field | "(" expr ")" | "-" factor
+type alertParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *alertParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *alertParser) next() string {
+	t := p.peek()
+	if t != "" {
+		p.pos++
+	}
+	return t
+}
+
+func (p *alertParser) parse() (*alert, error) {
+	expr, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+
+	op := p.next()
+	switch op {
+	case ">", ">=", "<", "<=", "==", "!=":
+	default:
+		return nil, fmt.Errorf("expected a comparison (>, >=, <, <=, == or !=), got %s", describeToken(op))
+	}
+
+	sign := 1.0
+	t := p.next()
+	if t == "-" {
+		sign = -1
+		t = p.next()
+	}
+	threshold, err := strconv.ParseFloat(t, 64)
+	if err != nil {
+		return nil, fmt.Errorf("expected a number to compare with, got %s", describeToken(t))
+	}
+	threshold *= sign
+
+	a := &alert{expr: expr, op: op, threshold: threshold}
+	if p.peek() == "for" {
+		p.next()
+		t := p.next()
+		a.duration, err = time.ParseDuration(t)
+		if err != nil || a.duration <= 0 {
+			return nil, fmt.Errorf("expected a positive duration (e.g. 30s) after 'for', got %s", describeToken(t))
+		}
+	}
+
+	if t := p.peek(); t != "" {
+		return nil, fmt.Errorf("unexpected %s", describeToken(t))
+	}
+	return a, nil
+}
+
+func (p *alertParser) expr() (alertExpr, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "+" || p.peek() == "-" {
+		op := p.next()[0]
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = alertBinary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *alertParser) term() (alertExpr, error) {
+	left, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "*" || p.peek() == "/" {
+		op := p.next()[0]
+		right, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		left = alertBinary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *alertParser) factor() (alertExpr, error) {
+	t := p.next()
+	switch {
+	case t == "(":
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t != ")" {
+			return nil, fmt.Errorf("expected ')', got %s", describeToken(t))
+		}
+		return e, nil
+	case t == "-":
+		e, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		return alertBinary{op: '-', left: alertNumber(0), right: e}, nil
+	case t != "" && (unicode.IsDigit(rune(t[0])) || t[0] == '.'):
+		n, err := strconv.ParseFloat(t, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", t)
+		}
+		return alertNumber(n), nil
+	case t != "" && isFieldChar(rune(t[0])) && t != "for":
+		return alertField(t), nil
+	}
+	return nil, fmt.Errorf("expected a field, number or '(', got %s", describeToken(t))
+}
+
+// tokenizeAlert splits an alert condition into fields, numbers, durations and
+// operators.
+func tokenizeAlert(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case strings.ContainsRune("+-*/()", c):
+			tokens = append(tokens, string(c))
+			i++
+		case strings.ContainsRune("<>=!", c):
+			j := i + 1
+			if j < len(s) && s[j] == '=' {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		case isFieldChar(c) || c == '.':
+			// Numbers, fields and durations (e.g. 1m30s) are runs of
+			// the same characters.
+			j := i
+			for j < len(s) && (isFieldChar(rune(s[j])) || s[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q", c)
+		}
+	}
+	return tokens, nil
+}
+
+func isFieldChar(c rune) bool {
+	return c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+func describeToken(t string) string {
+	if t == "" {
+		return "end of alert"
+	}
+	return fmt.Sprintf("%q", t)
+}
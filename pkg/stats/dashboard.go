@@ -0,0 +1,519 @@
+package stats
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fastly/cli/pkg/api"
+	"github.com/fastly/cli/pkg/text"
+	"github.com/fastly/go-fastly/v3/fastly"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Terminal control sequences used to draw the dashboard.
+const (
+	escEnterScreen = "\x1b[?1049h\x1b[?25l" // alternate screen, hide cursor
+	escExitScreen  = "\x1b[?25h\x1b[?1049l" // show cursor, main screen
+	escHome        = "\x1b[H"
+	escClearLine   = "\x1b[K"
+	escClearBelow  = "\x1b[J"
+)
+
+// resizeInterval is how often the dashboard checks whether the terminal has
+// been resized. Polling works the same on every platform, unlike SIGWINCH.
+const resizeInterval = 250 * time.Millisecond
+
+// retryDelay is the delay before the first retry of a failed realtime stats
+// request, which doubles for each consecutive failure up to maxRetryDelay.
+const (
+	retryDelay    = 500 * time.Millisecond
+	maxRetryDelay = 30 * time.Second
+)
+
+// sparkChars are the bars of a sparkline, from lowest to highest.
+var sparkChars = []rune("▁▂▃▄▅▆▇█")
+
+// dashboardMetric is a stat charted by the dashboard.
+type dashboardMetric struct {
+	name   string
+	value  func(s statsResponseData) float64
+	format func(v float64) string
+}
+
+// dashboardMetrics are the metrics charted by the dashboard, in order.
+var dashboardMetrics = []dashboardMetric{
+	{
+		name:   "Requests",
+		value:  func(s statsResponseData) float64 { return field(s, "requests") },
+		format: func(v float64) string { return fmt.Sprintf("%.0f/s", v) },
+	},
+	{
+		name:   "Hit ratio",
+		value:  hitRatio,
+		format: func(v float64) string { return fmt.Sprintf("%.1f%%", v*100) },
+	},
+	{
+		name: "Bandwidth",
+		value: func(s statsResponseData) float64 {
+			return field(s, "resp_header_bytes") + field(s, "resp_body_bytes")
+		},
+		format: func(v float64) string { return formatBytes(v) + "/s" },
+	},
+	{
+		name:   "Errors",
+		value:  func(s statsResponseData) float64 { return field(s, "errors") },
+		format: func(v float64) string { return fmt.Sprintf("%.0f/s", v) },
+	},
+	{
+		name:   "Miss latency",
+		value:  missLatency,
+		format: func(v float64) string { return fmt.Sprintf("%.1fms", v*1000) },
+	},
+}
+
+// dashboardKey is an action requested with a key press.
+type dashboardKey int
+
+const (
+	keyNone dashboardKey = iota
+	keyNext
+	keyPrev
+	keyPause
+	keyQuit
+	keyMetric // keyMetric+n selects metric n
+)
+
+// dashboardSample is a second of realtime stats.
+type dashboardSample struct {
+	aggregated statsResponseData
+	datacenter map[string]statsResponseData
+}
+
+// dashboard is the state of the realtime stats dashboard.
+type dashboard struct {
+	serviceID string
+	window    time.Duration
+	samples   []dashboardSample // one per second, oldest first
+	selected  int
+	paused    bool
+}
+
+// add records the realtime stats of each second in the response, discarding
+// those older than the window.
+func (d *dashboard) add(resp realtimeResponse) {
+	for _, block := range resp.Data {
+		d.samples = append(d.samples, dashboardSample{
+			aggregated: block.Aggregated,
+			datacenter: block.Datacenter,
+		})
+	}
+	if max := int(d.window / time.Second); len(d.samples) > max {
+		d.samples = d.samples[len(d.samples)-max:]
+	}
+}
+
+// handle applies a key press, returning false if the dashboard should exit.
+func (d *dashboard) handle(key dashboardKey) bool {
+	switch {
+	case key == keyQuit:
+		return false
+	case key == keyPause:
+		d.paused = !d.paused
+	case key == keyNext:
+		d.selected = (d.selected + 1) % len(dashboardMetrics)
+	case key == keyPrev:
+		d.selected = (d.selected + len(dashboardMetrics) - 1) % len(dashboardMetrics)
+	case key >= keyMetric && int(key-keyMetric) < len(dashboardMetrics):
+		d.selected = int(key - keyMetric)
+	}
+	return true
+}
+
+// render draws the dashboard to fit a terminal of the given size.
+func (d *dashboard) render(out io.Writer, width, height int) {
+	var lines []string
+	add := func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+
+	status := ""
+	if d.paused {
+		status = "  " + text.BoldYellow("[paused]")
+	}
+	add("%s service %s, last %s%s", text.Bold("Fastly realtime stats:"), d.serviceID, d.window, status)
+	add("")
+
+	// A sparkline of every metric, with its latest value.
+	const labelWidth, valueWidth = 15, 12
+	sparkWidth := width - labelWidth - valueWidth - 2
+	for i, m := range dashboardMetrics {
+		series := d.series(m)
+		cursor, label := " ", fmt.Sprintf("%-*s", labelWidth-2, m.name)
+		if i == d.selected {
+			cursor, label = ">", text.Bold(label)
+		}
+		latest := "-"
+		if len(series) > 0 {
+			latest = m.format(series[len(series)-1])
+		}
+		add("%s %s %s %*s", cursor, label, sparkline(resample(series, sparkWidth)), valueWidth, latest)
+	}
+	add("")
+
+	// A taller chart of the selected metric.
+	m := dashboardMetrics[d.selected]
+	series := d.series(m)
+	lo, hi := bounds(series)
+	add("%s (min %s, max %s)", text.Bold(m.name), m.format(lo), m.format(hi))
+	for _, row := range chart(resample(series, width-2), 6) {
+		add("  %s", row)
+	}
+	add("")
+
+	// The per-POP breakdown, busiest first, filling the remaining rows.
+	add("%s", text.Bold(fmt.Sprintf("%-8s %12s %10s %10s %13s", "POP", "Requests", "Hit ratio", "Errors", "Miss latency")))
+	pops := d.pops()
+	rows := height - len(lines) - 2
+	for i, pop := range pops {
+		if i >= rows {
+			break
+		}
+		s := pop.stats
+		add("%-8s %12.0f %10s %10.0f %13s", pop.name, field(s, "requests"), dashboardMetrics[1].format(hitRatio(s)), field(s, "errors"), dashboardMetrics[4].format(missLatency(s)))
+	}
+	if len(pops) == 0 {
+		add("(waiting for data)")
+	}
+
+	for len(lines) < height-1 {
+		add("")
+	}
+	add("←/→ or 1-%d: switch metric   p: pause   q: quit", len(dashboardMetrics))
+
+	fmt.Fprint(out, escHome)
+	for i, line := range lines {
+		if i > 0 {
+			// The terminal is in raw mode, so newlines don't return
+			// the cursor to the start of the line.
+			fmt.Fprint(out, "\r\n")
+		}
+		fmt.Fprint(out, line, escClearLine)
+	}
+	fmt.Fprint(out, escClearBelow)
+}
+
+// series returns the metric's value for each second in the window.
+func (d *dashboard) series(m dashboardMetric) []float64 {
+	series := make([]float64, len(d.samples))
+	for i, s := range d.samples {
+		series[i] = m.value(s.aggregated)
+	}
+	return series
+}
+
+// dashboardPOP is the stats of a POP over the window.
+type dashboardPOP struct {
+	name  string
+	stats statsResponseData
+}
+
+// pops returns the stats of each POP summed over the window, busiest first.
+func (d *dashboard) pops() []dashboardPOP {
+	totals := make(map[string]statsResponseData)
+	for _, s := range d.samples {
+		for name, stats := range s.datacenter {
+			if totals[name] == nil {
+				totals[name] = make(statsResponseData)
+			}
+			addStats(totals[name], stats)
+		}
+	}
+
+	pops := make([]dashboardPOP, 0, len(totals))
+	for name, stats := range totals {
+		pops = append(pops, dashboardPOP{name: name, stats: stats})
+	}
+	sort.Slice(pops, func(i, j int) bool {
+		a, b := field(pops[i].stats, "requests"), field(pops[j].stats, "requests")
+		if a != b {
+			return a > b
+		}
+		return pops[i].name < pops[j].name
+	})
+	return pops
+}
+
+// runDashboard polls the realtime stats of a service and draws them as a full
+// screen dashboard until the user quits.
+func runDashboard(client api.RealtimeStatsInterface, service string, window time.Duration, in io.Reader, out io.Writer) error {
+	if f, ok := in.(*os.File); ok && terminal.IsTerminal(int(f.Fd())) {
+		state, err := terminal.MakeRaw(int(f.Fd()))
+		if err != nil {
+			return fmt.Errorf("error configuring terminal: %w", err)
+		}
+		defer terminal.Restore(int(f.Fd()), state)
+	}
+	fmt.Fprint(out, escEnterScreen)
+	defer fmt.Fprint(out, escExitScreen)
+
+	done := make(chan struct{})
+	defer close(done)
+
+	keys := make(chan dashboardKey)
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := in.Read(buf)
+			for _, key := range parseKeys(buf[:n]) {
+				select {
+				case keys <- key:
+				case <-done:
+					return
+				}
+			}
+			if err != nil {
+				select {
+				case keys <- keyQuit:
+				case <-done:
+				}
+				return
+			}
+		}
+	}()
+
+	type update struct {
+		resp realtimeResponse
+		err  error
+	}
+	updates := make(chan update)
+	go func() {
+		var (
+			timestamp uint64
+			fails     int
+		)
+		for {
+			var resp realtimeResponse
+			err := client.GetRealtimeStatsJSON(&fastly.GetRealtimeStatsInput{
+				ServiceID: service,
+				Timestamp: timestamp,
+			}, &resp)
+			if err == nil {
+				timestamp = resp.Timestamp
+				fails = 0
+			}
+			select {
+			case updates <- update{resp, err}:
+			case <-done:
+				return
+			}
+			if err != nil {
+				fails++
+				select {
+				case <-time.After(retryBackoff(fails)):
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+
+	resize := time.NewTicker(resizeInterval)
+	defer resize.Stop()
+
+	d := dashboard{serviceID: service, window: window}
+	width, height := terminalSize()
+	var lastErr error
+	for {
+		select {
+		case <-resize.C:
+			w, h := terminalSize()
+			if w == width && h == height {
+				continue
+			}
+			width, height = w, h
+		case u := <-updates:
+			lastErr = u.err
+			if u.err == nil {
+				d.add(u.resp)
+			}
+			if d.paused {
+				continue
+			}
+		case key := <-keys:
+			if !d.handle(key) {
+				return nil
+			}
+		}
+
+		d.render(out, width, height)
+		if lastErr != nil {
+			fmt.Fprintf(out, "\r\n%s fetching stats: %v", text.BoldRed("ERROR:"), lastErr)
+		}
+	}
+}
+
+// retryBackoff returns the delay before retrying a realtime stats request
+// after the given number of consecutive failures.
+func retryBackoff(fails int) time.Duration {
+	d := retryDelay
+	for i := 1; i < fails && d < maxRetryDelay; i++ {
+		d *= 2
+	}
+	if d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	return d
+}
+
+// parseKeys returns the dashboard actions of the keys pressed.
+func parseKeys(b []byte) []dashboardKey {
+	var keys []dashboardKey
+	for i := 0; i < len(b); i++ {
+		switch c := b[i]; {
+		case c == 'q' || c == 'Q' || c == 3: // Ctrl-C doesn't signal in raw mode
+			keys = append(keys, keyQuit)
+		case c == 'p' || c == 'P' || c == ' ':
+			keys = append(keys, keyPause)
+		case c == '\t' || c == 'l':
+			keys = append(keys, keyNext)
+		case c == 'h':
+			keys = append(keys, keyPrev)
+		case c >= '1' && c <= '9':
+			keys = append(keys, keyMetric+dashboardKey(c-'1'))
+		case c == 0x1b && i+2 < len(b) && b[i+1] == '[':
+			switch b[i+2] {
+			case 'C':
+				keys = append(keys, keyNext)
+			case 'D':
+				keys = append(keys, keyPrev)
+			}
+			i += 2
+		}
+	}
+	return keys
+}
+
+// terminalSize returns the size of the terminal, or a conventional size if
+// there isn't one. The dashboard's output is wrapped by the CLI, so the size is
+// read from stdout, or stdin if stdout is redirected, rather than from it.
+func terminalSize() (width, height int) {
+	for _, f := range []*os.File{os.Stdout, os.Stdin} {
+		if w, h, err := terminal.GetSize(int(f.Fd())); err == nil && w > 0 && h > 0 {
+			return w, h
+		}
+	}
+	return 80, 24
+}
+
+// resample averages series into n values, so the whole window fits the space
+// available. Shorter series are returned unchanged.
+func resample(series []float64, n int) []float64 {
+	if n <= 0 || len(series) <= n {
+		return series
+	}
+	out := make([]float64, n)
+	for i := range out {
+		start, end := i*len(series)/n, (i+1)*len(series)/n
+		var sum float64
+		for _, v := range series[start:end] {
+			sum += v
+		}
+		out[i] = sum / float64(end-start)
+	}
+	return out
+}
+
+// sparkline draws series as a line of bars scaled between its bounds.
+func sparkline(series []float64) string {
+	lo, hi := bounds(series)
+	var b strings.Builder
+	for _, v := range series {
+		i := 0
+		if hi > lo {
+			i = int((v - lo) / (hi - lo) * float64(len(sparkChars)-1))
+		}
+		b.WriteRune(sparkChars[i])
+	}
+	return b.String()
+}
+
+// chart draws series as rows of bars, top row first, scaled from zero to its
+// maximum.
+func chart(series []float64, rows int) []string {
+	_, hi := bounds(series)
+	levels := rows * len(sparkChars)
+	out := make([]string, rows)
+	for r := range out {
+		var b strings.Builder
+		for _, v := range series {
+			level := 0
+			if hi > 0 {
+				level = int(math.Round(v / hi * float64(levels)))
+			}
+			// The number of eighths of this row filled by the bar.
+			fill := level - (rows-1-r)*len(sparkChars)
+			switch {
+			case fill <= 0:
+				b.WriteRune(' ')
+			case fill >= len(sparkChars):
+				b.WriteRune(sparkChars[len(sparkChars)-1])
+			default:
+				b.WriteRune(sparkChars[fill-1])
+			}
+		}
+		out[r] = b.String()
+	}
+	return out
+}
+
+// bounds returns the minimum and maximum of series.
+func bounds(series []float64) (lo, hi float64) {
+	for i, v := range series {
+		if i == 0 || v < lo {
+			lo = v
+		}
+		if i == 0 || v > hi {
+			hi = v
+		}
+	}
+	return lo, hi
+}
+
+// field returns a numeric stat, or zero if it isn't present.
+func field(s statsResponseData, name string) float64 {
+	v, _ := s[name].(float64)
+	return v
+}
+
+// hitRatio is the proportion of cacheable requests which were cache hits.
+func hitRatio(s statsResponseData) float64 {
+	hits, miss := field(s, "hits"), field(s, "miss")
+	if hits+miss == 0 {
+		return 0
+	}
+	return hits / (hits + miss)
+}
+
+// missLatency is the average time, in seconds, taken to fetch a cache miss.
+func missLatency(s statsResponseData) float64 {
+	if field(s, "miss") == 0 {
+		return 0
+	}
+	return field(s, "miss_time") / field(s, "miss")
+}
+
+// formatBytes formats a number of bytes with a binary unit prefix.
+func formatBytes(v float64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	i := 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.1f%s", v, units[i])
+}
@@ -1,26 +1,43 @@
 package stats
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/fastly/cli/pkg/cmd"
 	"github.com/fastly/cli/pkg/compute/manifest"
 	"github.com/fastly/cli/pkg/config"
 	"github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/text"
 	"github.com/fastly/go-fastly/v3/fastly"
 )
 
 const statusSuccess = "success"
 
+// Historical stats output formats.
+const (
+	formatJSON       = "json"
+	formatCSV        = "csv"
+	formatPrometheus = "prometheus"
+)
+
 // HistoricalCommand exposes the Historical Stats API.
 type HistoricalCommand struct {
 	cmd.Base
 	manifest manifest.Data
 
-	Input      fastly.GetStatsInput
-	formatFlag string
+	Input       fastly.GetStatsInput
+	formatFlag  string
+	fieldsFlag  string
+	serviceIDs  []string
+	allServices bool
 }
 
 // NewHistoricalCommand is the "stats historical" subcommand.
@@ -29,41 +46,91 @@ func NewHistoricalCommand(parent cmd.Registerer, globals *config.Data) *Historic
 	c.Globals = globals
 
 	c.CmdClause = parent.Command("historical", "View historical stats for a Fastly service")
-	c.RegisterServiceIDFlag(&c.manifest.Flag.ServiceID)
+	c.CmdClause.Flag("service-id", "Service ID (falls back to FASTLY_SERVICE_ID, then fastly.toml), repeat to aggregate the stats of several services").Short('s').StringsVar(&c.serviceIDs)
+	c.CmdClause.Flag("all", "Aggregate the stats of every service").BoolVar(&c.allServices)
 
 	c.CmdClause.Flag("from", "From time, accepted formats at https://fastly.dev/reference/api/metrics-stats/historical-stats").StringVar(&c.Input.From)
 	c.CmdClause.Flag("to", "To time").StringVar(&c.Input.To)
 	c.CmdClause.Flag("by", "Aggregation period (minute/hour/day)").EnumVar(&c.Input.By, "minute", "hour", "day")
 	c.CmdClause.Flag("region", "Filter by region ('stats regions' to list)").StringVar(&c.Input.Region)
 
-	c.CmdClause.Flag("format", "Output format (json)").EnumVar(&c.formatFlag, "json")
+	c.CmdClause.Flag("format", "Output format (json, csv, prometheus)").EnumVar(&c.formatFlag, formatJSON, formatCSV, formatPrometheus)
+	c.CmdClause.Flag("fields", "Comma separated stats fields to output in the csv and prometheus formats, defaults to all fields (e.g. hit_ratio,requests,errors)").StringVar(&c.fieldsFlag)
 
 	return &c
 }
 
 // Exec implements the command interface.
 func (c *HistoricalCommand) Exec(in io.Reader, out io.Writer) error {
-	serviceID, source := c.manifest.ServiceID()
-	if source == manifest.SourceUndefined {
-		return errors.ErrNoServiceID
-	}
-	c.Input.Service = serviceID
-
-	var envelope statsResponse
-	err := c.Globals.Client.GetStatsJSON(&c.Input, &envelope)
+	serviceIDs, err := c.services()
 	if err != nil {
-		c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
-			"Service ID": serviceID,
-		})
 		return err
 	}
+	serviceID := strings.Join(serviceIDs, ",")
+
+	var (
+		envelope statsResponse
+		blocks   [][]statsResponseData
+	)
+	for _, id := range serviceIDs {
+		input := c.Input
+		input.Service = id
+
+		var resp statsResponse
+		err := c.Globals.Client.GetStatsJSON(&input, &resp)
+		if err != nil {
+			c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+				"Service ID": id,
+			})
+			return err
+		}
+
+		if resp.Status != statusSuccess {
+			return fmt.Errorf("non-success response for service %s: %s", id, resp.Msg)
+		}
+		envelope = resp
+		blocks = append(blocks, resp.Data)
+	}
+	if len(blocks) > 1 {
+		envelope.Data = aggregateBlocks(blocks)
+	}
 
-	if envelope.Status != statusSuccess {
-		return fmt.Errorf("non-success response: %s", envelope.Msg)
+	fields := numericFields(envelope.Data)
+	if c.fieldsFlag != "" {
+		fields, err = selectFields(envelope.Data, c.fieldsFlag)
+		if err != nil {
+			return err
+		}
 	}
 
-	switch c.formatFlag {
-	case "json":
+	switch {
+	case text.IsMachineFormat(c.Globals.Flag.Format):
+		return text.Render(out, c.Globals.Flag.Format, envelope.Data)
+
+	case c.formatFlag == formatCSV:
+		if err := writeBlocksCSV(out, fields, envelope.Data); err != nil {
+			c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+				"Service ID": serviceID,
+			})
+			return err
+		}
+
+	case c.formatFlag == formatPrometheus:
+		labels := map[string]string{
+			"by":     envelope.Meta.By,
+			"region": envelope.Meta.Region,
+		}
+		if len(serviceIDs) == 1 {
+			labels["service_id"] = serviceID
+		}
+		if err := writePrometheus(out, fields, labels, envelope.Meta, totalBlocks(envelope.Data)); err != nil {
+			c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+				"Service ID": serviceID,
+			})
+			return err
+		}
+
+	case c.formatFlag == formatJSON:
 		err := writeBlocksJSON(out, serviceID, envelope.Data)
 		if err != nil {
 			c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
@@ -84,6 +151,80 @@ func (c *HistoricalCommand) Exec(in io.Reader, out io.Writer) error {
 	return nil
 }
 
+// services returns the IDs of the services whose stats are aggregated.
+func (c *HistoricalCommand) services() ([]string, error) {
+	if c.allServices {
+		if len(c.serviceIDs) > 0 {
+			return nil, errors.RemediationError{
+				Inner:       fmt.Errorf("--service-id and --all can't be used together"),
+				Remediation: "Either list the services with --service-id, or aggregate every service with --all.",
+			}
+		}
+
+		services, err := c.Globals.Client.ListServices(&fastly.ListServicesInput{})
+		if err != nil {
+			c.Globals.ErrLog.Add(err)
+			return nil, err
+		}
+		var ids []string
+		for _, s := range services {
+			ids = append(ids, s.ID)
+		}
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("no services found")
+		}
+		sort.Strings(ids)
+		return ids, nil
+	}
+
+	if len(c.serviceIDs) > 1 {
+		seen := make(map[string]bool)
+		var ids []string
+		for _, id := range c.serviceIDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		return ids, nil
+	}
+
+	// A single service falls back to the environment and manifest.
+	if len(c.serviceIDs) == 1 {
+		c.manifest.Flag.ServiceID = c.serviceIDs[0]
+	}
+	serviceID, source := c.manifest.ServiceID()
+	if source == manifest.SourceUndefined {
+		return nil, errors.ErrNoServiceID
+	}
+	return []string{serviceID}, nil
+}
+
+// selectFields parses a comma separated list of stats fields, checking each
+// is in the stats.
+func selectFields(blocks []statsResponseData, list string) ([]string, error) {
+	known := make(map[string]bool)
+	for _, field := range numericFields(blocks) {
+		known[field] = true
+	}
+
+	var fields []string
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if len(blocks) > 0 && !known[field] {
+			return nil, errors.RemediationError{
+				Inner:       fmt.Errorf("unknown stats field %q", field),
+				Remediation: "See https://developer.fastly.com/reference/api/metrics-stats/historical-stats/ for the available fields.",
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
 func writeHeader(out io.Writer, meta statsResponseMeta) {
 	fmt.Fprintf(out, "From: %s\n", meta.From)
 	fmt.Fprintf(out, "To: %s\n", meta.To)
@@ -111,3 +252,68 @@ func writeBlocksJSON(out io.Writer, service string, blocks []statsResponseData)
 
 	return nil
 }
+
+// writeBlocksCSV writes a row of the given fields for each period.
+func writeBlocksCSV(out io.Writer, fields []string, blocks []statsResponseData) error {
+	w := csv.NewWriter(out)
+	if err := w.Write(append([]string{"start_time"}, fields...)); err != nil {
+		return err
+	}
+
+	for _, block := range blocks {
+		start, _ := block["start_time"].(float64)
+		record := []string{time.Unix(int64(start), 0).UTC().Format(time.RFC3339)}
+		for _, field := range fields {
+			v, _ := block[field].(float64)
+			record = append(record, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// promInvalidChars matches characters which aren't valid in Prometheus metric
+// names.
+var promInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+
+// promLabelEscaper escapes Prometheus label values.
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// writePrometheus writes the total of each field over the requested period in
+// the Prometheus text exposition format, for the node exporter's textfile
+// collector.
+func writePrometheus(out io.Writer, fields []string, labels map[string]string, meta statsResponseMeta, total statsResponseData) error {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var pairs []string
+	for _, name := range names {
+		if labels[name] != "" {
+			pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, promLabelEscaper.Replace(labels[name])))
+		}
+	}
+	var labelSet string
+	if len(pairs) > 0 {
+		labelSet = "{" + strings.Join(pairs, ",") + "}"
+	}
+
+	for _, field := range fields {
+		metric := "fastly_stats_" + promInvalidChars.ReplaceAllString(field, "_")
+		v, _ := total[field].(float64)
+		_, err := fmt.Fprintf(out, "# HELP %s Fastly historical stats %s from %s to %s.\n# TYPE %s gauge\n%s%s %s\n",
+			metric, field, meta.From, meta.To,
+			metric,
+			metric, labelSet, strconv.FormatFloat(v, 'g', -1, 64))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
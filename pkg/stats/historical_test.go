@@ -3,6 +3,7 @@ package stats_test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -35,6 +36,35 @@ func TestHistorical(t *testing.T) {
 			api:        mock.API{GetStatsJSONFn: getStatsJSONOK},
 			wantOutput: historicalJSONOK,
 		},
+		{
+			args:       args("stats historical --service-id=123 --format=csv --fields=hit_ratio,requests,errors"),
+			api:        mock.API{GetStatsJSONFn: getStatsJSONPeriods},
+			wantOutput: historicalCSVOK,
+		},
+		{
+			args:       args("stats historical --service-id=123 --service-id=456 --format=csv --fields=hit_ratio,requests,errors"),
+			api:        mock.API{GetStatsJSONFn: getStatsJSONPeriods},
+			wantOutput: historicalCSVAggregatedOK,
+		},
+		{
+			args:       args("stats historical --all --format=csv --fields=requests"),
+			api:        mock.API{ListServicesFn: listServicesOK, GetStatsJSONFn: getStatsJSONPeriods},
+			wantOutput: "start_time,requests\n2013-05-15T00:00:00Z,30\n2013-05-16T00:00:00Z,60\n",
+		},
+		{
+			args:       args("stats historical --service-id=123 --format=prometheus --fields=requests,hit_ratio"),
+			api:        mock.API{GetStatsJSONFn: getStatsJSONPeriods},
+			wantOutput: historicalPrometheusOK,
+		},
+		{
+			args:      args("stats historical --service-id=123 --format=csv --fields=nope"),
+			api:       mock.API{GetStatsJSONFn: getStatsJSONPeriods},
+			wantError: `unknown stats field "nope"`,
+		},
+		{
+			args:      args("stats historical --service-id=123 --all"),
+			wantError: "--service-id and --all can't be used together",
+		},
 	} {
 		t.Run(strings.Join(testcase.args, " "), func(t *testing.T) {
 			var stdout bytes.Buffer
@@ -79,6 +109,24 @@ Requests:                                        0
 var historicalJSONOK = `{"start_time":0}
 `
 
+var historicalCSVOK = `start_time,hit_ratio,requests,errors
+2013-05-15T00:00:00Z,0.75,10,1
+2013-05-16T00:00:00Z,0.25,20,0
+`
+
+var historicalCSVAggregatedOK = `start_time,hit_ratio,requests,errors
+2013-05-15T00:00:00Z,0.75,30,3
+2013-05-16T00:00:00Z,0.25,60,0
+`
+
+var historicalPrometheusOK = `# HELP fastly_stats_requests Fastly historical stats requests from Wed May 15 00:00:00 UTC 2013 to Fri May 17 00:00:00 UTC 2013.
+# TYPE fastly_stats_requests gauge
+fastly_stats_requests{by="day",region="all",service_id="123"} 30
+# HELP fastly_stats_hit_ratio Fastly historical stats hit_ratio from Wed May 15 00:00:00 UTC 2013 to Fri May 17 00:00:00 UTC 2013.
+# TYPE fastly_stats_hit_ratio gauge
+fastly_stats_hit_ratio{by="day",region="all",service_id="123"} 0.5
+`
+
 func getStatsJSONOK(i *fastly.GetStatsInput, o interface{}) error {
 	msg := []byte(`
 {
@@ -96,6 +144,36 @@ func getStatsJSONOK(i *fastly.GetStatsInput, o interface{}) error {
 	return json.Unmarshal(msg, o)
 }
 
+// getStatsJSONPeriods returns two days of stats, with service 456 having
+// twice the traffic of any other service.
+func getStatsJSONPeriods(i *fastly.GetStatsInput, o interface{}) error {
+	scale := 1
+	if i.Service == "456" {
+		scale = 2
+	}
+	msg := fmt.Sprintf(`
+{
+  "status": "success",
+  "meta": {
+    "to": "Fri May 17 00:00:00 UTC 2013",
+    "from": "Wed May 15 00:00:00 UTC 2013",
+    "by": "day",
+    "region": "all"
+  },
+  "msg": null,
+  "data": [
+    {"start_time": 1368576000, "service_id": "%[1]s", "requests": %[2]d, "hits": %[3]d, "miss": %[4]d, "errors": %[4]d, "hit_ratio": 0.75},
+    {"start_time": 1368662400, "service_id": "%[1]s", "requests": %[5]d, "hits": %[4]d, "miss": %[3]d, "errors": 0, "hit_ratio": 0.25}
+  ]
+}`, i.Service, 10*scale, 3*scale, 1*scale, 20*scale)
+
+	return json.Unmarshal([]byte(msg), o)
+}
+
+func listServicesOK(i *fastly.ListServicesInput) ([]*fastly.Service, error) {
+	return []*fastly.Service{{ID: "123"}, {ID: "456"}}, nil
+}
+
 func getStatsJSONError(i *fastly.GetStatsInput, o interface{}) error {
 	return errTest
 }
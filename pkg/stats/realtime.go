@@ -3,6 +3,7 @@ package stats
 import (
 	"encoding/json"
 	"io"
+	"time"
 
 	"github.com/fastly/cli/pkg/api"
 	"github.com/fastly/cli/pkg/cmd"
@@ -18,7 +19,9 @@ type RealtimeCommand struct {
 	cmd.Base
 	manifest manifest.Data
 
-	formatFlag string
+	formatFlag      string
+	dashboard       bool
+	dashboardWindow time.Duration
 }
 
 // NewRealtimeCommand is the "stats realtime" subcommand.
@@ -30,6 +33,8 @@ func NewRealtimeCommand(parent cmd.Registerer, globals *config.Data) *RealtimeCo
 	c.RegisterServiceIDFlag(&c.manifest.Flag.ServiceID)
 
 	c.CmdClause.Flag("format", "Output format (json)").EnumVar(&c.formatFlag, "json")
+	c.CmdClause.Flag("dashboard", "Show the stats in a full screen dashboard").BoolVar(&c.dashboard)
+	c.CmdClause.Flag("dashboard-window", "How much history the dashboard charts").Default("5m").DurationVar(&c.dashboardWindow)
 
 	return &c
 }
@@ -41,8 +46,16 @@ func (c *RealtimeCommand) Exec(in io.Reader, out io.Writer) error {
 		return errors.ErrNoServiceID
 	}
 
-	switch c.formatFlag {
-	case "json":
+	switch {
+	case c.dashboard:
+		if err := runDashboard(c.Globals.RTSClient, serviceID, c.dashboardWindow, in, out); err != nil {
+			c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+				"Service ID": serviceID,
+			})
+			return err
+		}
+
+	case c.formatFlag == "json":
 		if err := loopJSON(c.Globals.RTSClient, serviceID, out); err != nil {
 			c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
 				"Service ID": serviceID,
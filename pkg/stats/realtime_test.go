@@ -0,0 +1,65 @@
+package stats_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/fastly/cli/pkg/app"
+	"github.com/fastly/cli/pkg/mock"
+	"github.com/fastly/cli/pkg/testutil"
+	"github.com/fastly/go-fastly/v3/fastly"
+)
+
+func TestRealtimeDashboard(t *testing.T) {
+	// Keys are pressed once the first second of stats has been drawn.
+	keys, press := io.Pipe()
+	var calls int
+	rts := mock.RealtimeStatsAPI{
+		GetRealtimeStatsJSONFn: func(i *fastly.GetRealtimeStatsInput, dst interface{}) error {
+			calls++
+			if calls == 2 {
+				go press.Write([]byte("2q"))
+			}
+			return json.Unmarshal([]byte(realtimeDashboardResponse), dst)
+		},
+	}
+
+	var stdout bytes.Buffer
+	opts := testutil.NewRunOpts(testutil.Args("stats realtime --service-id=123 --dashboard"), &stdout)
+	opts.Stdin = keys
+	opts.RTSClient = mock.RTSClient(rts)
+	err := app.Run(opts)
+	testutil.AssertNoError(t, err)
+
+	for _, want := range []string{
+		"Fastly realtime stats: service 123, last 5m0s",
+		"> Requests",
+		"Hit ratio (min 75.0%, max 75.0%)",
+		"Miss latency  ▁",
+		"50.0ms",
+	} {
+		testutil.AssertStringContains(t, stdout.String(), want)
+	}
+
+	// POPs are listed busiest first.
+	if iad, lhr := strings.Index(stdout.String(), "IAD "), strings.Index(stdout.String(), "LHR "); iad < 0 || lhr < iad {
+		t.Errorf("want IAD listed before LHR, have:\n%s", stdout.String())
+	}
+}
+
+var realtimeDashboardResponse = `{
+  "Timestamp": 1,
+  "Data": [
+    {
+      "recorded": 1,
+      "aggregated": {"requests": 40, "hits": 30, "miss": 10, "miss_time": 0.5, "errors": 2},
+      "datacenter": {
+        "IAD": {"requests": 30, "hits": 24, "miss": 8, "miss_time": 0.4, "errors": 2},
+        "LHR": {"requests": 10, "hits": 6, "miss": 2, "miss_time": 0.1, "errors": 0}
+      }
+    }
+  ]
+}`
@@ -30,6 +30,10 @@ func (c *RegionsCommand) Exec(in io.Reader, out io.Writer) error {
 		return fmt.Errorf("fetching regions: %w", err)
 	}
 
+	if text.IsMachineFormat(c.Globals.Flag.Format) {
+		return text.Render(out, c.Globals.Flag.Format, resp.Data)
+	}
+
 	for _, region := range resp.Data {
 		text.Output(out, "%s", region)
 	}
@@ -0,0 +1,168 @@
+package stats
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/fastly/cli/pkg/cmd"
+	"github.com/fastly/cli/pkg/compute/manifest"
+	"github.com/fastly/cli/pkg/config"
+	"github.com/fastly/cli/pkg/errors"
+	fstexec "github.com/fastly/cli/pkg/exec"
+	"github.com/fastly/cli/pkg/text"
+	"github.com/fastly/go-fastly/v3/fastly"
+)
+
+// watchMaxFetchErrors is the number of consecutive failed realtime stats
+// requests tolerated before watching is abandoned.
+const watchMaxFetchErrors = 3
+
+// WatchCommand watches realtime stats for alert conditions.
+type WatchCommand struct {
+	cmd.Base
+	manifest manifest.Data
+
+	alerts   []string
+	hook     string
+	duration time.Duration
+}
+
+// NewWatchCommand is the "stats watch" subcommand.
+func NewWatchCommand(parent cmd.Registerer, globals *config.Data) *WatchCommand {
+	var c WatchCommand
+	c.Globals = globals
+
+	c.CmdClause = parent.Command("watch", "Watch realtime stats for a Fastly service, failing when an alert condition trips")
+	c.RegisterServiceIDFlag(&c.manifest.Flag.ServiceID)
+
+	c.CmdClause.Flag("alert", "Alert condition over realtime stats fields, e.g. 'errors/requests > 0.02 for 30s' (repeatable)").Required().StringsVar(&c.alerts)
+	c.CmdClause.Flag("exec", "Command to run when an alert trips, instead of exiting with an error").StringVar(&c.hook)
+	c.CmdClause.Flag("duration", "How long to watch for, e.g. 5m (defaults to until an alert trips)").DurationVar(&c.duration)
+
+	return &c
+}
+
+// Exec implements the command interface.
+func (c *WatchCommand) Exec(in io.Reader, out io.Writer) error {
+	serviceID, source := c.manifest.ServiceID()
+	if source == manifest.SourceUndefined {
+		return errors.ErrNoServiceID
+	}
+
+	var alerts []*alert
+	for _, s := range c.alerts {
+		a, err := parseAlert(s)
+		if err != nil {
+			return errors.RemediationError{
+				Inner:       err,
+				Remediation: "Alerts compare an expression of realtime stats fields with a number, optionally for a duration, e.g. 'errors/requests > 0.02 for 30s'.",
+			}
+		}
+		alerts = append(alerts, a)
+	}
+
+	if c.duration > 0 {
+		text.Info(out, "Watching service %s for %s", serviceID, c.duration)
+	} else {
+		text.Info(out, "Watching service %s", serviceID)
+	}
+
+	var (
+		timestamp uint64
+		elapsed   int
+		fails     int
+		tripped   int
+		checked   bool
+	)
+	seconds := int(c.duration / time.Second)
+	for seconds == 0 || elapsed < seconds {
+		var resp realtimeResponse
+		err := c.Globals.RTSClient.GetRealtimeStatsJSON(&fastly.GetRealtimeStatsInput{
+			ServiceID: serviceID,
+			Timestamp: timestamp,
+		}, &resp)
+		if err != nil {
+			fails++
+			if fails >= watchMaxFetchErrors {
+				c.Globals.ErrLog.AddWithContext(err, map[string]interface{}{
+					"Service ID": serviceID,
+				})
+				return fmt.Errorf("error fetching realtime stats: %w", err)
+			}
+			time.Sleep(retryBackoff(fails))
+			continue
+		}
+		fails = 0
+		timestamp = resp.Timestamp
+
+		for _, block := range resp.Data {
+			if seconds > 0 && elapsed == seconds {
+				break
+			}
+			elapsed++
+
+			// Fields which are missing are probably typos, as
+			// they'd never change the alert.
+			if !checked {
+				checked = true
+				for _, a := range alerts {
+					for _, f := range a.fields() {
+						if _, ok := block.Aggregated[f]; !ok {
+							text.Warning(out, "The realtime stats have no %q field, so it's always 0 in the alert %q.", f, a.source)
+						}
+					}
+				}
+			}
+
+			for _, a := range alerts {
+				value, ok := a.observe(block.Aggregated)
+				if c.Globals.Verbose() {
+					text.Output(out, "[%4ds] %s: %g", elapsed, a.source, value)
+				}
+				if !ok {
+					continue
+				}
+
+				tripped++
+				if c.hook == "" {
+					return fmt.Errorf("alert tripped after %ds: %s (value %g)", elapsed, a.source, value)
+				}
+				text.Warning(out, "Alert tripped after %ds: %s (value %g)", elapsed, a.source, value)
+				if err := c.runHook(serviceID, a, value, out); err != nil {
+					c.Globals.ErrLog.Add(err)
+					text.Error(out, "alert command failed: %v", err)
+				}
+			}
+		}
+	}
+
+	if tripped > 0 {
+		text.Info(out, "%d alert(s) tripped in %s", tripped, c.duration)
+		return nil
+	}
+	text.Success(out, "No alerts tripped in %s", c.duration)
+	return nil
+}
+
+// runHook runs the --exec command through the shell, describing the alert in
+// its environment.
+func (c *WatchCommand) runHook(serviceID string, a *alert, value float64, out io.Writer) error {
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	s := fstexec.Streaming{
+		Command: shell,
+		Args:    []string{flag, c.hook},
+		Env: []string{
+			"FASTLY_SERVICE_ID=" + serviceID,
+			"FASTLY_ALERT=" + strings.TrimSpace(a.source),
+			fmt.Sprintf("FASTLY_ALERT_VALUE=%g", value),
+		},
+		Output: out,
+	}
+	return s.Exec()
+}
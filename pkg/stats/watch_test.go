@@ -0,0 +1,99 @@
+package stats_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/fastly/cli/pkg/app"
+	"github.com/fastly/cli/pkg/mock"
+	"github.com/fastly/cli/pkg/testutil"
+	"github.com/fastly/go-fastly/v3/fastly"
+)
+
+func TestWatch(t *testing.T) {
+	args := testutil.Args
+	for _, testcase := range []struct {
+		args       []string
+		rts        mock.RealtimeStatsAPI
+		wantError  string
+		wantOutput string
+	}{
+		{
+			args:      args("stats watch --service-id=123 --alert=errors/requests>0.02"),
+			rts:       mock.RealtimeStatsAPI{GetRealtimeStatsJSONFn: getRealtimeStatsWatch},
+			wantError: "alert tripped after 1s: errors/requests>0.02 (value 0.05)",
+		},
+		{
+			args:      append(args("stats watch --service-id=123 --alert=requests>1000"), "--alert=(errors+synth)/requests >= 0.05 for 3s"),
+			rts:       mock.RealtimeStatsAPI{GetRealtimeStatsJSONFn: getRealtimeStatsWatch},
+			wantError: "alert tripped after 3s: (errors+synth)/requests >= 0.05 for 3s (value 0.05)",
+		},
+		{
+			args:      args("stats watch --service-id=123 --alert=errors-requests<-50"),
+			rts:       mock.RealtimeStatsAPI{GetRealtimeStatsJSONFn: getRealtimeStatsWatch},
+			wantError: "alert tripped after 1s: errors-requests<-50 (value -95)",
+		},
+		{
+			args:       append(args("stats watch --service-id=123 --duration=2s"), "--alert=errors - requests < - 100"),
+			rts:        mock.RealtimeStatsAPI{GetRealtimeStatsJSONFn: getRealtimeStatsWatch},
+			wantOutput: "No alerts tripped in 2s",
+		},
+		{
+			args:       args("stats watch --service-id=123 --alert=errors/requests>0.1 --duration=4s"),
+			rts:        mock.RealtimeStatsAPI{GetRealtimeStatsJSONFn: getRealtimeStatsWatch},
+			wantOutput: "No alerts tripped in 4s",
+		},
+		{
+			args:       args("stats watch --service-id=123 --alert=erors>1 --duration=1s"),
+			rts:        mock.RealtimeStatsAPI{GetRealtimeStatsJSONFn: getRealtimeStatsWatch},
+			wantOutput: `The realtime stats have no "erors" field`,
+		},
+		{
+			args:       append(args("stats watch --service-id=123 --alert=errors>1 --duration=3s"), "--exec=echo tripped $FASTLY_ALERT_VALUE"),
+			rts:        mock.RealtimeStatsAPI{GetRealtimeStatsJSONFn: getRealtimeStatsWatch},
+			wantOutput: "tripped 5",
+		},
+		{
+			args:      args("stats watch --service-id=123 --alert=errors/requests>"),
+			wantError: "expected a number to compare with, got end of alert",
+		},
+		{
+			args:      append(args("stats watch --service-id=123"), "--alert=errors > 1 for soon"),
+			wantError: `expected a positive duration (e.g. 30s) after 'for', got "soon"`,
+		},
+		{
+			args:      args("stats watch --service-id=123 --alert=errors>1"),
+			rts:       mock.RealtimeStatsAPI{GetRealtimeStatsJSONFn: getRealtimeStatsError},
+			wantError: "error fetching realtime stats: " + errTest.Error(),
+		},
+	} {
+		t.Run(strings.Join(testcase.args, " "), func(t *testing.T) {
+			var stdout bytes.Buffer
+			opts := testutil.NewRunOpts(testcase.args, &stdout)
+			opts.RTSClient = mock.RTSClient(testcase.rts)
+			err := app.Run(opts)
+			testutil.AssertErrorContains(t, err, testcase.wantError)
+			testutil.AssertStringContains(t, stdout.String(), testcase.wantOutput)
+		})
+	}
+}
+
+// getRealtimeStatsWatch returns two seconds of stats with a 5% error rate.
+func getRealtimeStatsWatch(i *fastly.GetRealtimeStatsInput, o interface{}) error {
+	msg := []byte(`
+{
+  "Timestamp": 2,
+  "Data": [
+    {"recorded": 1, "aggregated": {"requests": 100, "errors": 5, "synth": 0}},
+    {"recorded": 2, "aggregated": {"requests": 100, "errors": 5, "synth": 0}}
+  ]
+}`)
+
+	return json.Unmarshal(msg, o)
+}
+
+func getRealtimeStatsError(i *fastly.GetRealtimeStatsInput, o interface{}) error {
+	return errTest
+}
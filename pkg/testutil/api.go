@@ -2,7 +2,10 @@ package testutil
 
 import (
 	"errors"
+	"reflect"
+	"strings"
 
+	"github.com/fastly/cli/pkg/mock"
 	"github.com/fastly/go-fastly/v3/fastly"
 )
 
@@ -61,3 +64,26 @@ func CloneVersionResult(version int) func(i *fastly.CloneVersionInput) (*fastly.
 func CloneVersionError(i *fastly.CloneVersionInput) (*fastly.Version, error) {
 	return nil, Err
 }
+
+// ListEmpty returns a copy of the mock API where every unset List*Fn function
+// is replaced with one that returns no resources. It's useful for testing
+// commands which walk every resource type on a service version.
+func ListEmpty(m mock.API) mock.API {
+	v := reflect.ValueOf(&m).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := v.Field(i)
+		if !strings.HasPrefix(t.Field(i).Name, "List") || f.Kind() != reflect.Func || !f.IsNil() {
+			continue
+		}
+		ft := f.Type()
+		f.Set(reflect.MakeFunc(ft, func([]reflect.Value) []reflect.Value {
+			out := make([]reflect.Value, ft.NumOut())
+			for j := range out {
+				out[j] = reflect.Zero(ft.Out(j))
+			}
+			return out
+		}))
+	}
+	return m
+}
@@ -16,3 +16,20 @@ var BoldGreen = color.New(color.Bold, color.FgGreen).SprintFunc()
 
 // Reset is a Sprint-class function that resets the color for the arguments.
 var Reset = color.New(color.Reset).SprintFunc()
+
+// Palette is a set of Sprint-class functions which colour the arguments
+// distinctly, for telling apart interleaved output from different sources.
+var Palette = []func(a ...interface{}) string{
+	color.New(color.FgCyan).SprintFunc(),
+	color.New(color.FgMagenta).SprintFunc(),
+	color.New(color.FgGreen).SprintFunc(),
+	color.New(color.FgYellow).SprintFunc(),
+	color.New(color.FgBlue).SprintFunc(),
+	color.New(color.FgRed).SprintFunc(),
+	color.New(color.FgHiCyan).SprintFunc(),
+	color.New(color.FgHiMagenta).SprintFunc(),
+	color.New(color.FgHiGreen).SprintFunc(),
+	color.New(color.FgHiYellow).SprintFunc(),
+	color.New(color.FgHiBlue).SprintFunc(),
+	color.New(color.FgHiRed).SprintFunc(),
+}
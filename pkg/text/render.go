@@ -0,0 +1,294 @@
+package text
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Output formats supported by the global --output flag. FormatTable is the
+// default human readable output, which each command prints itself.
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatCSV   = "csv"
+)
+
+// Formats is every supported output format.
+var Formats = []string{FormatTable, FormatJSON, FormatYAML, FormatCSV}
+
+// IsMachineFormat reports whether the output format is a machine readable
+// format, which should be written using Render.
+func IsMachineFormat(format string) bool {
+	return format != "" && format != FormatTable
+}
+
+// Render writes v to w in a machine readable format.
+//
+// Structs are rendered using the field names from their mapstructure (or json)
+// tags, falling back to a snake_case version of the Go field name, so the
+// output uses the same names as the Fastly API. The CSV format is only
+// supported for slices.
+func Render(w io.Writer, format string, v interface{}) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(machineData(reflect.ValueOf(v)))
+	case FormatYAML:
+		bs, err := yaml.Marshal(machineData(reflect.ValueOf(v)))
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(bs)
+		return err
+	case FormatCSV:
+		return renderCSV(w, v)
+	}
+	return fmt.Errorf("unsupported output format: %s", format)
+}
+
+// renderCSV writes a slice of structs as CSV, with a header row of field
+// names. Nested struct fields are flattened into columns named with dotted
+// paths (e.g. coordinates.latitude), and lists and maps are written as JSON.
+func renderCSV(w io.Writer, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return fmt.Errorf("the %s output format is only supported when listing resources", FormatCSV)
+	}
+
+	cw := csv.NewWriter(w)
+
+	// A slice of scalar values is written as a single column.
+	t := rv.Type().Elem()
+	if isScalar(t) {
+		for i := 0; i < rv.Len(); i++ {
+			value, err := csvValue(rv.Index(i))
+			if err != nil {
+				return err
+			}
+			if err := cw.Write([]string{value}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("the %s output format is only supported when listing resources", FormatCSV)
+	}
+
+	columns := csvColumns(t, "", nil, map[reflect.Type]bool{t: true})
+	header := make([]string, len(columns))
+	for n, c := range columns {
+		header[n] = c.name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for i := 0; i < rv.Len(); i++ {
+		record := make([]string, len(columns))
+		for n, c := range columns {
+			f, ok := fieldByIndex(rv.Index(i), c.index)
+			if !ok {
+				continue
+			}
+			value, err := csvValue(f)
+			if err != nil {
+				return err
+			}
+			record[n] = value
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvColumn is a CSV column and the index of the struct field it's read from.
+type csvColumn struct {
+	name  string
+	index []int
+}
+
+// csvColumns returns the columns of a struct, in field order. The fields of
+// nested structs are flattened into columns prefixed with the field's name,
+// unless the struct is nested in itself.
+func csvColumns(t reflect.Type, prefix string, index []int, parents map[reflect.Type]bool) []csvColumn {
+	var columns []csvColumn
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := fieldName(f)
+		if !ok {
+			continue
+		}
+		name = prefix + name
+		fieldIndex := append(index[:len(index):len(index)], i)
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && !isScalar(ft) && !parents[ft] {
+			parents[ft] = true
+			columns = append(columns, csvColumns(ft, name+".", fieldIndex, parents)...)
+			delete(parents, ft)
+			continue
+		}
+		columns = append(columns, csvColumn{name: name, index: fieldIndex})
+	}
+	return columns
+}
+
+// fieldByIndex returns the nested field of a struct, or false if a struct
+// along the way is nil.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for _, i := range index {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v, true
+}
+
+// csvValue returns the CSV representation of a value. Scalars are written as
+// they are and anything else as JSON.
+func csvValue(v reflect.Value) (string, error) {
+	d := machineData(v)
+	switch d.(type) {
+	case nil:
+		return "", nil
+	case []interface{}, map[string]interface{}:
+		bs, err := json.Marshal(d)
+		if err != nil {
+			return "", err
+		}
+		return string(bs), nil
+	}
+	return fmt.Sprint(d), nil
+}
+
+// machineData converts v into a tree of plain values (maps, slices, strings,
+// numbers and bools) suitable for encoding.
+func machineData(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.UTC().Format(time.RFC3339)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return machineData(v.Elem())
+	case reflect.Struct:
+		m := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			if name, ok := fieldName(v.Type().Field(i)); ok {
+				m[name] = machineData(v.Field(i))
+			}
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return []interface{}{}
+		}
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			s[i] = machineData(v.Index(i))
+		}
+		return s
+	case reflect.Map:
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = machineData(iter.Value())
+		}
+		return m
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// fieldName returns the name a struct field is rendered with, and false if
+// the field should be omitted.
+func fieldName(f reflect.StructField) (string, bool) {
+	if f.PkgPath != "" {
+		return "", false
+	}
+	for _, key := range []string{"mapstructure", "json"} {
+		if tag, ok := f.Tag.Lookup(key); ok {
+			name := strings.Split(tag, ",")[0]
+			if name == "-" {
+				return "", false
+			}
+			if name != "" {
+				return name, true
+			}
+		}
+	}
+	return snakeCase(f.Name), true
+}
+
+// isScalar reports whether values of type t render as a single CSV value.
+func isScalar(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map, reflect.Interface, reflect.Func, reflect.Chan:
+		return false
+	}
+	return true
+}
+
+// snakeCase converts a Go identifier (e.g. ServiceID) to snake_case
+// (e.g. service_id).
+func snakeCase(s string) string {
+	rs := []rune(s)
+	var b strings.Builder
+	for i, r := range rs {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(rs[i-1]) || (i+1 < len(rs) && unicode.IsLower(rs[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,120 @@
+package text_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/fastly/cli/pkg/testutil"
+	"github.com/fastly/cli/pkg/text"
+)
+
+type renderChild struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+type renderResource struct {
+	ServiceID string       `mapstructure:"service_id"`
+	Port      uint         `mapstructure:"port"`
+	Child     *renderChild `mapstructure:"child"`
+	CreatedAt *time.Time   `mapstructure:"created_at"`
+	Secret    string       `json:"-"`
+	HTTPCode  int
+}
+
+func TestRender(t *testing.T) {
+	created := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	resources := []*renderResource{
+		{ServiceID: "123", Port: 443, Child: &renderChild{Enabled: true}, CreatedAt: &created, Secret: "s3cr3t", HTTPCode: 200},
+		{ServiceID: "456", Port: 80},
+	}
+
+	for _, testcase := range []struct {
+		name       string
+		format     string
+		in         interface{}
+		wantOutput string
+		wantError  string
+	}{
+		{
+			name:   "json",
+			format: text.FormatJSON,
+			in:     resources[0],
+			wantOutput: `{
+  "child": {
+    "enabled": true
+  },
+  "created_at": "2021-01-02T03:04:05Z",
+  "http_code": 200,
+  "port": 443,
+  "service_id": "123"
+}
+`,
+		},
+		{
+			name:   "yaml",
+			format: text.FormatYAML,
+			in:     resources,
+			wantOutput: `- child:
+    enabled: true
+  created_at: "2021-01-02T03:04:05Z"
+  http_code: 200
+  port: 443
+  service_id: "123"
+- child: null
+  created_at: null
+  http_code: 0
+  port: 80
+  service_id: "456"
+`,
+		},
+		{
+			name:   "csv",
+			format: text.FormatCSV,
+			in:     resources,
+			wantOutput: `service_id,port,child.enabled,created_at,http_code
+123,443,true,2021-01-02T03:04:05Z,200
+456,80,,,0
+`,
+		},
+		{
+			name:   "csv lists and maps",
+			format: text.FormatCSV,
+			in: []struct {
+				Name string
+				Tags []string
+				Meta map[string]string
+			}{
+				{Name: "a", Tags: []string{"x", "y"}, Meta: map[string]string{"k": "v"}},
+			},
+			wantOutput: `name,tags,meta
+a,"[""x"",""y""]","{""k"":""v""}"
+`,
+		},
+		{
+			name:       "csv scalars",
+			format:     text.FormatCSV,
+			in:         []string{"a", "b"},
+			wantOutput: "a\nb\n",
+		},
+		{
+			name:      "csv requires a list",
+			format:    text.FormatCSV,
+			in:        resources[0],
+			wantError: "the csv output format is only supported when listing resources",
+		},
+		{
+			name:      "unsupported",
+			format:    text.FormatTable,
+			in:        resources,
+			wantError: "unsupported output format: table",
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := text.Render(&buf, testcase.format, testcase.in)
+			testutil.AssertErrorContains(t, err, testcase.wantError)
+			testutil.AssertString(t, testcase.wantOutput, buf.String())
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package undo
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fastly/cli/pkg/errors"
+)
+
+// JournalDir is the directory of the undo journals, which sits alongside the
+// fastly CLI error log.
+var JournalDir = filepath.Join(filepath.Dir(errors.LogPath), "undo")
+
+// JournalPath returns the location of the undo journal for the key, such as a
+// project directory, so that an unfinished command only blocks commands with
+// the same key.
+func JournalPath(key string) string {
+	return filepath.Join(JournalDir, fmt.Sprintf("%x.json", sha256.Sum256([]byte(key))))
+}
+
+// Action is a serializable undo step. The Name identifies the Handler which
+// performs the step, and the Params are passed to it.
+type Action struct {
+	Name   string            `json:"name"`
+	Params map[string]string `json:"params"`
+}
+
+// Handler performs an undo Action using its parameters.
+type Handler func(params map[string]string) error
+
+// Handlers maps an Action name to the Handler which performs it.
+type Handlers map[string]Handler
+
+// Journal is an on-disk record of the undo actions of a command which hasn't
+// finished, so that they can be replayed if the command is interrupted.
+type Journal struct {
+	Command   string    `json:"command"`
+	CreatedAt time.Time `json:"created_at"`
+	Actions   []Action  `json:"actions"`
+
+	path string
+}
+
+// NewJournal constructs a new Journal for the given command. Nothing is
+// written to disk until an Action is recorded.
+func NewJournal(path, command string) *Journal {
+	return &Journal{
+		Command:   command,
+		CreatedAt: time.Now().UTC(),
+		path:      path,
+	}
+}
+
+// ReadJournal reads the Journal at the given path. If there is no journal the
+// returned error satisfies os.IsNotExist.
+func ReadJournal(path string) (*Journal, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var j Journal
+	if err := json.Unmarshal(bs, &j); err != nil {
+		return nil, fmt.Errorf("error parsing undo journal %s: %w", path, err)
+	}
+	j.path = path
+	return &j, nil
+}
+
+// Path returns the location of the journal on disk.
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Record appends an Action to the journal and persists it.
+func (j *Journal) Record(a Action) error {
+	j.Actions = append(j.Actions, a)
+	return j.Write()
+}
+
+// Write persists the journal to disk.
+func (j *Journal) Write() error {
+	bs, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(j.path, bs, 0600)
+}
+
+// Remove deletes the journal from disk.
+func (j *Journal) Remove() error {
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Replay performs the journaled actions in reverse order. Actions which fail
+// are kept in the journal so that they can be retried, otherwise the journal
+// is removed.
+func (j *Journal) Replay(w io.Writer, handlers Handlers) error {
+	var failed []Action
+	for i := len(j.Actions) - 1; i >= 0; i-- {
+		a := j.Actions[i]
+		h, ok := handlers[a.Name]
+		if !ok {
+			fmt.Fprintf(w, "unrecognised undo action %q\n", a.Name)
+			failed = append([]Action{a}, failed...)
+			continue
+		}
+		if err := h(a.Params); err != nil {
+			fmt.Fprintln(w, err)
+			failed = append([]Action{a}, failed...)
+		}
+	}
+
+	if len(failed) == 0 {
+		return j.Remove()
+	}
+	j.Actions = failed
+	if err := j.Write(); err != nil {
+		return err
+	}
+	return fmt.Errorf("%d undo action(s) failed and remain in %s", len(failed), j.path)
+}
@@ -12,14 +12,23 @@ type Fn func() error
 // stateful functions, such as a function to teardown API state if something
 // goes wrong during procedural commands, for example deleting a Fastly service
 // after it's been created.
+//
+// A Stack constructed with NewJournaledStack also records each Action pushed
+// with PushAction in a Journal, so the undo steps survive the process being
+// interrupted.
 type Stack struct {
-	states []Fn
+	states  []Fn
+	actions []*Action
+
+	journal  *Journal
+	handlers Handlers
 }
 
 // Stacker represents the API of a Stack.
 type Stacker interface {
 	Pop() Fn
 	Push(elem Fn)
+	PushAction(a Action) error
 	Len() int
 	RunIfError(w io.Writer, err error)
 }
@@ -33,6 +42,15 @@ func NewStack() *Stack {
 	return stack
 }
 
+// NewJournaledStack constructs a new Stack which records actions in the given
+// Journal. The handlers perform each Action when the stack is unwound.
+func NewJournaledStack(j *Journal, handlers Handlers) *Stack {
+	stack := NewStack()
+	stack.journal = j
+	stack.handlers = handlers
+	return stack
+}
+
 // Pop method pops last added Fn element off the stack and returns it.
 // If stack is empty Pop() returns nil.
 func (s *Stack) Pop() Fn {
@@ -42,12 +60,33 @@ func (s *Stack) Pop() Fn {
 	}
 	v := s.states[n-1]
 	s.states = s.states[:n-1]
+	s.actions = s.actions[:n-1]
 	return v
 }
 
 // Push method pushes an element onto the Stack.
 func (s *Stack) Push(elem Fn) {
 	s.states = append(s.states, elem)
+	s.actions = append(s.actions, nil)
+}
+
+// PushAction method pushes a serializable Action onto the Stack, recording it
+// in the journal (if there is one) before returning.
+func (s *Stack) PushAction(a Action) error {
+	h, ok := s.handlers[a.Name]
+	if !ok {
+		return fmt.Errorf("unrecognised undo action %q", a.Name)
+	}
+	if s.journal != nil {
+		if err := s.journal.Record(a); err != nil {
+			return fmt.Errorf("error writing undo journal: %w", err)
+		}
+	}
+	s.states = append(s.states, func() error {
+		return h(a.Params)
+	})
+	s.actions = append(s.actions, &a)
+	return nil
 }
 
 // Len method returns the number of elements in the Stack.
@@ -59,16 +98,37 @@ func (s *Stack) Len() int {
 // calling each Fn function state in FIFO order. If any Fn returns an
 // error, it gets logged to the provided writer. Should be deferrerd, such as:
 //
-//     undoStack := undo.NewStack()
-//     defer func() { undoStack.RunIfError(w, err) }()
+//	undoStack := undo.NewStack()
+//	defer func() { undoStack.RunIfError(w, err) }()
 //
+// For a journaled stack the journal is removed once there is nothing left to
+// undo, otherwise the actions which failed are kept so they can be replayed.
 func (s *Stack) RunIfError(w io.Writer, err error) {
-	if err == nil {
+	var failed []Action
+	if err != nil {
+		for i := len(s.states) - 1; i >= 0; i-- {
+			if err := s.states[i](); err != nil {
+				fmt.Fprintln(w, err)
+				if s.actions[i] != nil {
+					failed = append([]Action{*s.actions[i]}, failed...)
+				}
+			}
+		}
+	}
+
+	if s.journal == nil {
 		return
 	}
-	for i := len(s.states) - 1; i >= 0; i-- {
-		if err := s.states[i](); err != nil {
+	if len(failed) == 0 {
+		if err := s.journal.Remove(); err != nil {
 			fmt.Fprintln(w, err)
 		}
+		return
+	}
+	s.journal.Actions = failed
+	if err := s.journal.Write(); err != nil {
+		fmt.Fprintln(w, err)
+		return
 	}
+	fmt.Fprintf(w, "Some changes couldn't be undone. Run `fastly %s --rollback-incomplete` to retry.\n", s.journal.Command)
 }
@@ -0,0 +1,148 @@
+package undo_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fastly/cli/pkg/testutil"
+	"github.com/fastly/cli/pkg/undo"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJournaledStack(t *testing.T) {
+	for _, testcase := range []struct {
+		name        string
+		err         error
+		fail        map[string]bool
+		wantCalls   []string
+		wantJournal []string
+	}{
+		{
+			name: "success",
+		},
+		{
+			name:      "unwound",
+			err:       testutil.Err,
+			wantCalls: []string{"c", "b", "a"},
+		},
+		{
+			name:        "unwound with failures",
+			err:         testutil.Err,
+			fail:        map[string]bool{"a": true, "c": true},
+			wantCalls:   []string{"c", "b", "a"},
+			wantJournal: []string{"a", "c"},
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "undo.json")
+
+			var calls []string
+			handler := func(params map[string]string) error {
+				calls = append(calls, params["id"])
+				if testcase.fail[params["id"]] {
+					return fmt.Errorf("failed to undo %s", params["id"])
+				}
+				return nil
+			}
+
+			s := undo.NewJournaledStack(undo.NewJournal(path, "compute deploy"), undo.Handlers{"test": handler})
+			for _, id := range []string{"a", "b", "c"} {
+				if err := s.PushAction(undo.Action{Name: "test", Params: map[string]string{"id": id}}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Every action should be journaled before the stack is unwound.
+			j, err := undo.ReadJournal(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(j.Actions) != 3 {
+				t.Fatalf("want 3 journaled actions, have %d", len(j.Actions))
+			}
+
+			var out bytes.Buffer
+			s.RunIfError(&out, testcase.err)
+
+			if diff := cmp.Diff(testcase.wantCalls, calls); diff != "" {
+				t.Fatalf("unexpected calls (-want +have):\n%s", diff)
+			}
+
+			j, err = undo.ReadJournal(path)
+			if testcase.wantJournal == nil {
+				if !os.IsNotExist(err) {
+					t.Fatalf("want journal to be removed, have %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var have []string
+			for _, a := range j.Actions {
+				have = append(have, a.Params["id"])
+			}
+			if diff := cmp.Diff(testcase.wantJournal, have); diff != "" {
+				t.Fatalf("unexpected journal (-want +have):\n%s", diff)
+			}
+			testutil.AssertStringContains(t, out.String(), "fastly compute deploy --rollback-incomplete")
+		})
+	}
+}
+
+func TestJournalReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "undo.json")
+	j := undo.NewJournal(path, "compute deploy")
+	for _, a := range []undo.Action{
+		{Name: "ok", Params: map[string]string{"id": "a"}},
+		{Name: "fail", Params: map[string]string{"id": "b"}},
+		{Name: "unknown", Params: map[string]string{"id": "c"}},
+	} {
+		if err := j.Record(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	handlers := undo.Handlers{
+		"ok":   func(map[string]string) error { return nil },
+		"fail": func(map[string]string) error { return testutil.Err },
+	}
+
+	var out bytes.Buffer
+	err := j.Replay(&out, handlers)
+	testutil.AssertErrorContains(t, err, "2 undo action(s) failed")
+	testutil.AssertStringContains(t, out.String(), `unrecognised undo action "unknown"`)
+
+	j, err = undo.ReadJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(j.Actions) != 2 || j.Actions[0].Name != "fail" || j.Actions[1].Name != "unknown" {
+		t.Fatalf("unexpected journal actions: %v", j.Actions)
+	}
+
+	// Once every action succeeds the journal is removed.
+	handlers["unknown"] = handlers["ok"]
+	handlers["fail"] = handlers["ok"]
+	if err := j.Replay(&out, handlers); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("want journal to be removed, have %v", err)
+	}
+}
+
+func TestJournalPath(t *testing.T) {
+	defer func(dir string) { undo.JournalDir = dir }(undo.JournalDir)
+	undo.JournalDir = t.TempDir()
+
+	a := undo.JournalPath("/projects/a")
+	testutil.AssertString(t, a, undo.JournalPath("/projects/a"))
+	if b := undo.JournalPath("/projects/b"); a == b {
+		t.Fatalf("want different journals for different projects, both are %s", a)
+	}
+	testutil.AssertString(t, undo.JournalDir, filepath.Dir(a))
+}
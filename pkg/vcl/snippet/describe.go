@@ -8,6 +8,7 @@ import (
 	"github.com/fastly/cli/pkg/compute/manifest"
 	"github.com/fastly/cli/pkg/config"
 	"github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/text"
 	"github.com/fastly/go-fastly/v3/fastly"
 )
 
@@ -79,6 +80,11 @@ func (c *DescribeCommand) Exec(in io.Reader, out io.Writer) error {
 			})
 			return err
 		}
+
+		if text.IsMachineFormat(c.Globals.Flag.Format) {
+			return text.Render(out, c.Globals.Flag.Format, v)
+		}
+
 		c.printDynamic(out, v)
 		return nil
 	}
@@ -99,6 +105,11 @@ func (c *DescribeCommand) Exec(in io.Reader, out io.Writer) error {
 		})
 		return err
 	}
+
+	if text.IsMachineFormat(c.Globals.Flag.Format) {
+		return text.Render(out, c.Globals.Flag.Format, v)
+	}
+
 	c.print(out, v)
 	return nil
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/fastly/cli/pkg/cmd"
 	"github.com/fastly/cli/pkg/config"
 	"github.com/fastly/cli/pkg/errors"
+	"github.com/fastly/cli/pkg/text"
 	"github.com/fastly/cli/pkg/useragent"
 )
 
@@ -68,6 +69,10 @@ func (c *RootCommand) Exec(in io.Reader, out io.Writer) error {
 		return fmt.Errorf("error decoding API response: %w", err)
 	}
 
+	if text.IsMachineFormat(c.Globals.Flag.Format) {
+		return text.Render(out, c.Globals.Flag.Format, response)
+	}
+
 	if !c.Globals.Verbose() {
 		fmt.Fprintf(out, "%s <%s>\n", response.User.Name, response.User.Login)
 		return nil